@@ -3,11 +3,13 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/conduktor/ctl/internal/cli"
 	"github.com/conduktor/ctl/internal/state"
 	"github.com/conduktor/ctl/internal/state/model"
 	"github.com/conduktor/ctl/internal/state/storage"
+	"github.com/conduktor/ctl/pkg/resource"
 	"github.com/spf13/cobra"
 )
 
@@ -15,12 +17,18 @@ func initApply(rootContext cli.RootContext) {
 	// applyCmd represents the apply command
 	var recursiveFolder *bool
 	var filePath *[]string
+	var overlays *[]string
 	var dryRun *bool
 	var printDiff *bool
 	var maxParallel *int
 	var stateEnabled *bool
 	var stateFile *string
 	var stateRemoteURI *string
+	var wait *bool
+	var waitTimeout *time.Duration
+	var templating templateFlags
+	var filtering fileFilterFlags
+	var strictYaml *bool
 
 	var applyCmd = &cobra.Command{
 		Use:          "apply",
@@ -28,20 +36,35 @@ func initApply(rootContext cli.RootContext) {
 		Long:         ``,
 		SilenceUsage: true, // do not print usage on run error
 		RunE: func(cmd *cobra.Command, args []string) error {
+			templateValues, err := templating.values()
+			if err != nil {
+				return err
+			}
+			fileFilter, err := filtering.filter()
+			if err != nil {
+				return err
+			}
 			stateCfg := storage.NewStorageConfig(stateEnabled, stateFile, stateRemoteURI)
 			return state.RunWithState(stateCfg, *dryRun, *rootContext.Debug, func(stateRef *model.State) error {
 
 				cmdCtx := cli.ApplyHandlerContext{
 					FilePaths:       *filePath,
+					Overlays:        *overlays,
 					RecursiveFolder: *recursiveFolder,
 					DryRun:          *dryRun,
 					PrintDiff:       *printDiff,
 					MaxParallel:     *maxParallel,
 					StateEnabled:    stateCfg.Enabled,
 					StateRef:        stateRef,
+					TemplateValues:  templateValues,
+					FileFilter:      fileFilter,
+					StrictYaml:      *strictYaml,
 				}
 
-				return runApply(rootContext, cmdCtx)
+				if !*wait || *dryRun {
+					return runApply(rootContext, cmdCtx, 0)
+				}
+				return runApply(rootContext, cmdCtx, *waitTimeout)
 			})
 		},
 	}
@@ -51,6 +74,9 @@ func initApply(rootContext cli.RootContext) {
 	filePath = applyCmd.
 		PersistentFlags().StringArrayP("file", "f", make([]string, 0), FILE_ARGS_DOC)
 
+	overlays = applyCmd.
+		PersistentFlags().StringArrayP("overlay", "k", make([]string, 0), "Specify the overlays to build and apply, directories holding a "+resource.OverlayFileName+" file. See the build command")
+
 	dryRun = applyCmd.
 		PersistentFlags().Bool("dry-run", false, "Test potential changes without the effects being applied")
 
@@ -58,7 +84,7 @@ func initApply(rootContext cli.RootContext) {
 		PersistentFlags().Bool("print-diff", false, "Print the diff between the current resource and the one to be applied")
 
 	recursiveFolder = applyCmd.
-		PersistentFlags().BoolP("recursive", "r", false, "Apply all .yaml or .yml files in the specified folder and its subfolders. If not set, only files in the specified folder will be applied.")
+		PersistentFlags().BoolP("recursive", "r", false, "Apply all .yaml, .yml, .json or .ndjson files in the specified folder and its subfolders. If not set, only files in the specified folder will be applied.")
 
 	maxParallel = applyCmd.
 		PersistentFlags().Int("parallelism", 1, "Run each apply in parallel, useful when applying a large number of resources. Must be less than 100.")
@@ -72,7 +98,20 @@ func initApply(rootContext cli.RootContext) {
 	stateRemoteURI = applyCmd.
 		PersistentFlags().String("state-remote-uri", "", "Remote storage URI for state management (e.g., s3://bucket/path/, gs://bucket/path/, azblob://container/path/). If provided, remote backend will be used instead of local file.")
 
-	_ = applyCmd.MarkPersistentFlagRequired("file")
+	wait = applyCmd.
+		PersistentFlags().Bool("wait", false, "Wait for each applied resource to match the ready condition of its kind. Ignored with --dry-run")
+
+	waitTimeout = applyCmd.
+		PersistentFlags().Duration("wait-timeout", cli.DefaultWaitTimeout, "Maximum duration to wait for each resource with --wait")
+
+	templating = addTemplateFlags(applyCmd)
+
+	filtering = addFileFilterFlags(applyCmd)
+
+	strictYaml = applyCmd.
+		PersistentFlags().Bool("strict-yaml", false, STRICT_YAML_DOC)
+
+	applyCmd.MarkFlagsOneRequired("file", "overlay")
 
 	applyCmd.PreRunE = func(cmd *cobra.Command, args []string) error {
 		if *maxParallel > 100 || *maxParallel < 1 {
@@ -82,7 +121,8 @@ func initApply(rootContext cli.RootContext) {
 	}
 }
 
-func runApply(rootContext cli.RootContext, cmdCtx cli.ApplyHandlerContext) error {
+// runApply applies the resources and, if waitTimeout is not zero, waits for each successfully applied resource to be ready.
+func runApply(rootContext cli.RootContext, cmdCtx cli.ApplyHandlerContext, waitTimeout time.Duration) error {
 	applyHandler := cli.NewApplyHandler(rootContext)
 
 	results, err := applyHandler.Handle(cmdCtx)
@@ -96,11 +136,26 @@ func runApply(rootContext cli.RootContext, cmdCtx cli.ApplyHandlerContext) error
 			fmt.Fprintf(os.Stderr, "Could not apply resource %s/%s: %s\n", result.Resource.Kind, result.Resource.Name, result.Err)
 			allSuccess = false
 		} else if result.UpsertResult.UpsertResult != "" {
-			fmt.Printf("%s", result.UpsertResult.Diff)
+			fmt.Print(colorDiff(result.UpsertResult.Diff))
 			fmt.Printf("%s/%s: %s\n", result.Resource.Kind, result.Resource.Name, result.UpsertResult.UpsertResult)
 		}
 	}
 
+	if waitTimeout > 0 {
+		for _, result := range results {
+			if result.Err != nil {
+				continue
+			}
+			lastObserved, err := cli.WaitForResource(rootContext, result.Resource, waitTimeout)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Resource %s/%s is not ready: %s\n", result.Resource.Kind, result.Resource.Name, waitError(lastObserved, err))
+				allSuccess = false
+			} else {
+				fmt.Printf("%s/%s: ready\n", result.Resource.Kind, result.Resource.Name)
+			}
+		}
+	}
+
 	if !allSuccess {
 		return fmt.Errorf("one or more resources could not be applied")
 	}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"github.com/conduktor/ctl/internal/cli"
+	"github.com/conduktor/ctl/pkg/resource"
+	"github.com/spf13/cobra"
+	"github.com/thediveo/enumflag/v2"
+)
+
+func initBuild(rootContext cli.RootContext) {
+	var format OutputFormat = YAML
+	var recursiveFolder *bool
+	var templating templateFlags
+	var filtering fileFilterFlags
+
+	var buildCmd = &cobra.Command{
+		Use:   "build <overlay>",
+		Short: "Print the resources of an overlay with its patches applied",
+		Long: `Load the resources of an overlay, a directory holding a ` + resource.OverlayFileName + ` file, from its bases,
+apply its patches, name prefix and suffix, common labels and cluster, and print the resulting resources.
+apply -k applies the same resources.`,
+		Example: `  conduktor build overlays/prod
+  conduktor apply -k overlays/prod`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true, // do not print usage on run error
+		RunE: func(cmd *cobra.Command, args []string) error {
+			templateValues, err := templating.values()
+			if err != nil {
+				return err
+			}
+			fileFilter, err := filtering.filter()
+			if err != nil {
+				return err
+			}
+			resources, err := cli.BuildOverlays(args, rootContext.Loader(*recursiveFolder, templateValues, fileFilter))
+			if err != nil {
+				return err
+			}
+			if resources == nil {
+				resources = []resource.Resource{}
+			}
+			return printResource(resources, format)
+		},
+	}
+
+	recursiveFolder = buildCmd.Flags().BoolP("recursive", "r", false, "Load all .yaml, .yml, .json or .ndjson files in the folders of the overlay and their subfolders")
+	buildCmd.Flags().VarP(enumflag.New(&format, "output", OutputFormatIds, enumflag.EnumCaseInsensitive), "output", "o", "Output format. One of: json|yaml|name")
+	templating = addTemplateFlags(buildCmd)
+	filtering = addFileFilterFlags(buildCmd)
+
+	rootCmd.AddCommand(buildCmd)
+}
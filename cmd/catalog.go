@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/conduktor/ctl/internal/cli"
+	"github.com/conduktor/ctl/pkg/client"
+	"github.com/conduktor/ctl/pkg/schema"
+	"github.com/spf13/cobra"
+)
+
+func initCatalog(rootContext cli.RootContext) {
+	var catalogCmd = &cobra.Command{
+		Use:   "catalog",
+		Short: "Inspect and refresh the catalog of kinds",
+		Long: `The catalog describes the kinds of resources of the Console and the Gateway. It is downloaded from their API,
+cached for CDK_CATALOG_TTL (default 24h, 0 disables the cache), and the catalog embedded in the CLI is used when offline.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			_ = cmd.Help()
+			os.Exit(1)
+		},
+	}
+
+	var infoCmd = &cobra.Command{
+		Use:   "info",
+		Short: "Show whether the live, cached or embedded catalog is in use and its age",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			for _, status := range cli.CatalogStatuses(rootContext) {
+				printCatalogStatus(status)
+			}
+			printCatalogCollisions(rootContext.Catalog)
+		},
+	}
+
+	var refreshCmd = &cobra.Command{
+		Use:   "refresh",
+		Short: "Download the catalogs and replace the cached ones",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			statuses, errors := cli.RefreshCatalogs(rootContext)
+			for _, status := range statuses {
+				printCatalogStatus(status)
+			}
+			for _, err := range errors {
+				fmt.Fprintf(os.Stderr, "%s\n", err)
+			}
+			if len(errors) > 0 {
+				os.Exit(1)
+			}
+		},
+	}
+
+	catalogCmd.AddCommand(infoCmd)
+	catalogCmd.AddCommand(refreshCmd)
+	rootCmd.AddCommand(catalogCmd)
+}
+
+// printCatalogCollisions reports the kinds and runs defined by both the Console and the Gateway.
+func printCatalogCollisions(catalog schema.Catalog) {
+	kinds, runs := catalog.Collisions()
+	if len(kinds) > 0 {
+		fmt.Printf("Kinds defined by both the Console and the Gateway, use console:<kind> or gateway:<kind>: %s\n", strings.Join(kinds, ", "))
+	}
+	if len(runs) > 0 {
+		fmt.Printf("Runs defined by both the Console and the Gateway, use console:<run> or gateway:<run>: %s\n", strings.Join(runs, ", "))
+	}
+}
+
+func printCatalogStatus(status cli.CatalogStatus) {
+	info := status.Info
+	if status.ClientError != nil {
+		fmt.Printf("%s: %s (not configured: %s)\n", status.Backend, info.Source, status.ClientError)
+		return
+	}
+	fmt.Printf("%s: %s\n", status.Backend, info.Source)
+	fmt.Printf("  base URL: %s\n", info.BaseURL)
+	if info.Source != client.CatalogEmbedded {
+		fmt.Printf("  server version: %s\n", info.ServerVersion)
+		fmt.Printf("  age: %s (fetched %s)\n", info.Age().Truncate(time.Second), info.FetchedAt.Format(time.RFC3339))
+	}
+	if info.CacheFile != "" {
+		fmt.Printf("  cache: %s (ttl %s)\n", info.CacheFile, info.TTL)
+	}
+	if info.Offline {
+		fmt.Println("  offline: true")
+	}
+	if info.Error != nil {
+		fmt.Printf("  live catalog unavailable: %s\n", info.Error)
+	}
+}
@@ -1,11 +1,19 @@
 package cmd
 
 import (
+	"os"
 	"strings"
+
+	"github.com/conduktor/ctl/internal/utils"
+	"github.com/conduktor/ctl/pkg/resource"
+	"github.com/spf13/cobra"
 )
 
 //nolint:staticcheck
-const FILE_ARGS_DOC = "Specify the files or folders to apply. For folders, all .yaml or .yml files within the folder will be applied, while files in subfolders will be ignored."
+const FILE_ARGS_DOC = "Specify the files or folders to apply. For folders, all .yaml, .yml, .json or .ndjson files within the folder will be applied, while files in subfolders will be ignored. Use - to read stdin, an http(s) URL or git::<repository>//<path>?ref=<ref>"
+
+//nolint:staticcheck
+const STRICT_YAML_DOC = "Reject duplicate keys, unknown top-level keys, missing or non-string apiVersion, kind and metadata.name and tabs in the indentation, reporting the file, line and column of the error"
 
 func removeTrailingSIfAny(name string) string {
 	return strings.TrimSuffix(name, "s")
@@ -20,3 +28,50 @@ func buildAlias(name string) []string {
 	}
 	return aliases
 }
+
+// colorDiff colors a unified diff printed on stdout according to the --color flag.
+func colorDiff(diff string) string {
+	if utils.ShouldColor(colorMode, os.Stdout) {
+		return utils.ColorizeUnifiedDiff(diff)
+	}
+	return diff
+}
+
+// templateFlags are the --values and --set flags of the commands loading files, rendered as templates when one is set.
+type templateFlags struct {
+	valueFiles *[]string
+	sets       *[]string
+}
+
+func addTemplateFlags(cmd *cobra.Command) templateFlags {
+	return templateFlags{
+		valueFiles: cmd.Flags().StringArray("values", []string{}, "Render the files as Go templates with the values of this YAML file, can be repeated, later files override earlier ones"),
+		sets:       cmd.Flags().StringArray("set", []string{}, "Render the files as Go templates with this value, as key=value where key is a dotted path (e.g. topic.partitions=6), can be repeated and overrides --values"),
+	}
+}
+
+// fileFilterFlags are the --include and --exclude flags of the commands loading folders.
+type fileFilterFlags struct {
+	include *[]string
+	exclude *[]string
+}
+
+func addFileFilterFlags(cmd *cobra.Command) fileFilterFlags {
+	return fileFilterFlags{
+		include: cmd.Flags().StringArray("include", []string{}, "Only load the files of folders matching this glob pattern (e.g. topics/**/*.yaml), relative to the folder or matching the file name for patterns without /, can be repeated"),
+		exclude: cmd.Flags().StringArray("exclude", []string{}, "Skip the files and subfolders of folders matching this glob pattern (e.g. **/values*.yaml), relative to the folder or matching the file name for patterns without /, can be repeated"),
+	}
+}
+
+func (f fileFilterFlags) filter() (resource.FileFilter, error) {
+	filter := resource.FileFilter{Include: *f.include, Exclude: *f.exclude}
+	return filter, filter.Validate()
+}
+
+// values returns the template values, nil if no flag is set so that the files are not rendered.
+func (f templateFlags) values() (map[string]interface{}, error) {
+	if len(*f.valueFiles) == 0 && len(*f.sets) == 0 {
+		return nil, nil
+	}
+	return resource.LoadTemplateValues(*f.valueFiles, *f.sets)
+}
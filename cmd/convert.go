@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"github.com/conduktor/ctl/internal/cli"
+	"github.com/conduktor/ctl/pkg/resource"
+	"github.com/spf13/cobra"
+	"github.com/thediveo/enumflag/v2"
+)
+
+func initConvert(rootContext cli.RootContext) {
+	var format OutputFormat = YAML
+	var filePath *[]string
+	var recursiveFolder *bool
+	var toVersion *string
+	var filtering fileFilterFlags
+
+	var convertCmd = &cobra.Command{
+		Use:   "convert",
+		Short: "Convert the resources of files to another version of their kind",
+		Long: `Convert the resources of files to another apiVersion of their kind with the conversions declared in the catalog,
+e.g. renamed or removed fields and new defaults, chaining them through the versions in between, and print the converted resources.
+The resources whose kind has no such version are left as is.`,
+		Example:      `  conduktor convert -f alerts.yaml --to-version v3 > alerts-v3.yaml`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true, // do not print usage on run error
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fileFilter, err := filtering.filter()
+			if err != nil {
+				return err
+			}
+			resources, err := cli.LoadResourcesFromFiles(*filePath, rootContext.Loader(*recursiveFolder, nil, fileFilter))
+			if err != nil {
+				return err
+			}
+			converted, err := cli.ConvertResources(rootContext.Catalog, resources, *toVersion)
+			if err != nil {
+				return err
+			}
+			if converted == nil {
+				converted = []resource.Resource{}
+			}
+			return printResource(converted, format)
+		},
+	}
+
+	filePath = convertCmd.Flags().StringArrayP("file", "f", make([]string, 0), "Specify the files or folders to convert. For folders, all .yaml, .yml, .json or .ndjson files within the folder will be converted, while files in subfolders will be ignored. Use - to read stdin, an http(s) URL or git::<repository>//<path>?ref=<ref>")
+	recursiveFolder = convertCmd.Flags().BoolP("recursive", "r", false, "Convert all .yaml, .yml, .json or .ndjson files in the specified folder and its subfolders")
+	toVersion = convertCmd.Flags().String("to-version", "", "Version to convert to, e.g. v3")
+	convertCmd.Flags().VarP(enumflag.New(&format, "output", OutputFormatIds, enumflag.EnumCaseInsensitive), "output", "o", "Output format. One of: json|yaml|name")
+	filtering = addFileFilterFlags(convertCmd)
+	_ = convertCmd.MarkFlagRequired("file")
+	_ = convertCmd.MarkFlagRequired("to-version")
+
+	rootCmd.AddCommand(convertCmd)
+}
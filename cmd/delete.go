@@ -8,6 +8,7 @@ import (
 	"github.com/conduktor/ctl/internal/state"
 	"github.com/conduktor/ctl/internal/state/model"
 	"github.com/conduktor/ctl/internal/state/storage"
+	"github.com/conduktor/ctl/pkg/resource"
 	"github.com/conduktor/ctl/pkg/schema"
 	"github.com/spf13/cobra"
 )
@@ -19,6 +20,8 @@ func initDelete(rootContext cli.RootContext) {
 	var stateEnabled *bool
 	var stateFile *string
 	var stateRemoteURI *string
+	var templating templateFlags
+	var filtering fileFilterFlags
 
 	var deleteCmd = &cobra.Command{
 		Use:          "delete",
@@ -27,7 +30,15 @@ func initDelete(rootContext cli.RootContext) {
 		Args:         cobra.NoArgs,
 		SilenceUsage: true, // do not print usage on run error
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runDeleteFromFiles(rootContext, *filePath, *recursiveFolder, dryRun, stateEnabled, stateFile, stateRemoteURI)
+			templateValues, err := templating.values()
+			if err != nil {
+				return err
+			}
+			fileFilter, err := filtering.filter()
+			if err != nil {
+				return err
+			}
+			return runDeleteFromFiles(rootContext, *filePath, *recursiveFolder, templateValues, fileFilter, dryRun, stateEnabled, stateFile, stateRemoteURI)
 		},
 	}
 
@@ -36,7 +47,7 @@ func initDelete(rootContext cli.RootContext) {
 	filePath = deleteCmd.Flags().StringArrayP("file", "f", make([]string, 0), FILE_ARGS_DOC)
 
 	recursiveFolder = deleteCmd.
-		Flags().BoolP("recursive", "r", false, "Delete all .yaml or .yml files in the specified folder and its subfolders. If not set, only files in the specified folder will be applied.")
+		Flags().BoolP("recursive", "r", false, "Delete all .yaml, .yml, .json or .ndjson files in the specified folder and its subfolders. If not set, only files in the specified folder will be applied.")
 
 	dryRun = deleteCmd.
 		PersistentFlags().Bool("dry-run", false, "Test potential changes without the effects being deleted")
@@ -50,9 +61,13 @@ func initDelete(rootContext cli.RootContext) {
 	stateRemoteURI = deleteCmd.
 		PersistentFlags().String("state-remote-uri", "", "Remote storage URI for state management (e.g., s3://bucket/path/, gs://bucket/path/, azblob://container/path/). If provided, remote backend will be used instead of local file.")
 
+	templating = addTemplateFlags(deleteCmd)
+
+	filtering = addFileFilterFlags(deleteCmd)
+
 	_ = deleteCmd.MarkFlagRequired("file")
 
-	for name, kind := range rootContext.Catalog.Kind {
+	addKindCommands(deleteCmd, rootContext.Catalog, func(name string, kind schema.Kind) {
 		if cli.IsKindIdentifiedByNameAndVCluster(kind) {
 			byVClusterAndNameDeleteCmd := buildDeleteByVClusterAndNameCmd(rootContext, kind, dryRun, stateEnabled, stateFile, stateRemoteURI)
 			deleteCmd.AddCommand(byVClusterAndNameDeleteCmd)
@@ -64,6 +79,7 @@ func initDelete(rootContext cli.RootContext) {
 			parentQueryFlags := kind.GetParentQueryFlag()
 			parentFlagValue := make([]*string, len(flags))
 			parentQueryFlagValue := make([]*string, len(parentQueryFlags))
+			gatewayInstance := new(string)
 			kindCmd := &cobra.Command{
 				Use:          fmt.Sprintf("%s [name]", name),
 				Short:        "Delete resource of kind " + name,
@@ -71,7 +87,7 @@ func initDelete(rootContext cli.RootContext) {
 				Aliases:      buildAlias(name),
 				SilenceUsage: true, // do not print usage on run error
 				RunE: func(cmd *cobra.Command, args []string) error {
-					return runDeleteKind(rootContext, kind, args, parentFlagValue, parentQueryFlagValue, dryRun, stateEnabled, stateFile, stateRemoteURI)
+					return runDeleteKind(rootContext, kind, args, parentFlagValue, parentQueryFlagValue, *gatewayInstance, dryRun, stateEnabled, stateFile, stateRemoteURI)
 				},
 			}
 			for i, flag := range kind.GetParentFlag() {
@@ -81,12 +97,15 @@ func initDelete(rootContext cli.RootContext) {
 			for i, flag := range parentQueryFlags {
 				parentQueryFlagValue[i] = kindCmd.Flags().String(flag, "", "Parent "+flag)
 			}
+			if kind.IsGatewayKind() {
+				gatewayInstance = addGatewayInstanceFlag(kindCmd, rootContext)
+			}
 			deleteCmd.AddCommand(kindCmd)
 		}
-	}
+	})
 }
 
-func runDeleteFromFiles(rootContext cli.RootContext, filePaths []string, recursiveFolder bool, dryRun *bool, stateEnabled *bool, stateFile *string, stateRemoteURI *string) error {
+func runDeleteFromFiles(rootContext cli.RootContext, filePaths []string, recursiveFolder bool, templateValues map[string]interface{}, fileFilter resource.FileFilter, dryRun *bool, stateEnabled *bool, stateFile *string, stateRemoteURI *string) error {
 
 	stateCfg := storage.NewStorageConfig(stateEnabled, stateFile, stateRemoteURI)
 	return state.RunWithState(stateCfg, *dryRun, *rootContext.Debug, func(stateRef *model.State) error {
@@ -99,6 +118,8 @@ func runDeleteFromFiles(rootContext cli.RootContext, filePaths []string, recursi
 			DryRun:          *dryRun,
 			StateEnabled:    *stateEnabled,
 			StateRef:        stateRef,
+			TemplateValues:  templateValues,
+			FileFilter:      fileFilter,
 		}
 
 		results, err := deleteHandler.HandleFromFiles(cmdCtx)
@@ -125,6 +146,7 @@ func buildDeleteByVClusterAndNameCmd(rootContext cli.RootContext, kind schema.Ki
 	const vClusterFlag = "vcluster"
 	name := kind.GetName()
 	var vClusterValue string
+	var gatewayInstance *string
 	var deleteCmd = &cobra.Command{
 		Use:          fmt.Sprintf("%s [name]", name),
 		Short:        "Delete resource of kind " + name,
@@ -132,11 +154,12 @@ func buildDeleteByVClusterAndNameCmd(rootContext cli.RootContext, kind schema.Ki
 		Aliases:      buildAlias(name),
 		SilenceUsage: true, // do not print usage on run error
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runDeleteByVClusterAndName(rootContext, kind, args[0], vClusterValue, dryRun, stateEnabled, stateFile, stateRemoteURI)
+			return runDeleteByVClusterAndName(rootContext, kind, args[0], vClusterValue, *gatewayInstance, dryRun, stateEnabled, stateFile, stateRemoteURI)
 		},
 	}
 
 	deleteCmd.Flags().StringVar(&vClusterValue, vClusterFlag, "passthrough", "vCluster of the "+name)
+	gatewayInstance = addGatewayInstanceFlag(deleteCmd, rootContext)
 
 	return deleteCmd
 }
@@ -148,6 +171,7 @@ func buildDeleteInterceptorsCmd(rootContext cli.RootContext, kind schema.Kind, d
 	var vClusterValue string
 	var groupValue string
 	var usernameValue string
+	var gatewayInstance *string
 	name := kind.GetName()
 	var interceptorDeleteCmd = &cobra.Command{
 		Use:          fmt.Sprintf("%s [name]", name),
@@ -156,30 +180,32 @@ func buildDeleteInterceptorsCmd(rootContext cli.RootContext, kind schema.Kind, d
 		Aliases:      buildAlias(name),
 		SilenceUsage: true, // do not print usage on run error
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runDeleteInterceptor(rootContext, kind, args[0], vClusterValue, groupValue, usernameValue, dryRun, stateEnabled, stateFile, stateRemoteURI)
+			return runDeleteInterceptor(rootContext, kind, args[0], vClusterValue, groupValue, usernameValue, *gatewayInstance, dryRun, stateEnabled, stateFile, stateRemoteURI)
 		},
 	}
 
 	interceptorDeleteCmd.Flags().StringVar(&vClusterValue, vClusterFlag, "", "vCluster of the "+name)
 	interceptorDeleteCmd.Flags().StringVar(&groupValue, groupFlag, "", "Group of the "+name)
 	interceptorDeleteCmd.Flags().StringVar(&usernameValue, usernameFlag, "", "Username of the "+name)
+	gatewayInstance = addGatewayInstanceFlag(interceptorDeleteCmd, rootContext)
 
 	return interceptorDeleteCmd
 }
 
-func runDeleteByVClusterAndName(rootContext cli.RootContext, kind schema.Kind, name string, vCluster string, dryRun *bool, stateEnabled *bool, stateFile *string, stateRemoteURI *string) error {
+func runDeleteByVClusterAndName(rootContext cli.RootContext, kind schema.Kind, name string, vCluster string, gatewayInstance string, dryRun *bool, stateEnabled *bool, stateFile *string, stateRemoteURI *string) error {
 
 	stateCfg := storage.NewStorageConfig(stateEnabled, stateFile, stateRemoteURI)
 	return state.RunWithState(stateCfg, *dryRun, *rootContext.Debug, func(stateRef *model.State) error {
 		deleteHandler := cli.NewDeleteHandler(rootContext)
 
 		cmdCtx := cli.DeleteByVClusterAndNameHandlerContext{
-			Name:          name,
-			VCluster:      vCluster,
-			IgnoreMissing: false, // fail even if resource is missing (keep current behavior)
-			DryRun:        *dryRun,
-			StateEnabled:  *stateEnabled,
-			StateRef:      stateRef,
+			Name:            name,
+			VCluster:        vCluster,
+			IgnoreMissing:   false, // fail even if resource is missing (keep current behavior)
+			DryRun:          *dryRun,
+			StateEnabled:    *stateEnabled,
+			StateRef:        stateRef,
+			GatewayInstance: gatewayInstance,
 		}
 
 		err := deleteHandler.HandleByVClusterAndName(kind, cmdCtx)
@@ -190,21 +216,22 @@ func runDeleteByVClusterAndName(rootContext cli.RootContext, kind schema.Kind, n
 	})
 }
 
-func runDeleteInterceptor(rootContext cli.RootContext, kind schema.Kind, name string, vCluster string, group string, username string, dryRun *bool, stateEnabled *bool, stateFile *string, stateRemoteURI *string) error {
+func runDeleteInterceptor(rootContext cli.RootContext, kind schema.Kind, name string, vCluster string, group string, username string, gatewayInstance string, dryRun *bool, stateEnabled *bool, stateFile *string, stateRemoteURI *string) error {
 
 	stateCfg := storage.NewStorageConfig(stateEnabled, stateFile, stateRemoteURI)
 	return state.RunWithState(stateCfg, *dryRun, *rootContext.Debug, func(stateRef *model.State) error {
 		deleteHandler := cli.NewDeleteHandler(rootContext)
 
 		cmdCtx := cli.DeleteInterceptorHandlerContext{
-			Name:          name,
-			VCluster:      vCluster,
-			Group:         group,
-			Username:      username,
-			IgnoreMissing: false, // fail even if resource is missing (keep current behavior)
-			DryRun:        *dryRun,
-			StateEnabled:  *stateEnabled,
-			StateRef:      stateRef,
+			Name:            name,
+			VCluster:        vCluster,
+			Group:           group,
+			Username:        username,
+			IgnoreMissing:   false, // fail even if resource is missing (keep current behavior)
+			DryRun:          *dryRun,
+			StateEnabled:    *stateEnabled,
+			StateRef:        stateRef,
+			GatewayInstance: gatewayInstance,
 		}
 
 		err := deleteHandler.HandleInterceptor(kind, cmdCtx)
@@ -220,7 +247,8 @@ func runDeleteKind(
 	kind schema.Kind,
 	args []string,
 	parentFlagValue []*string,
-	parentQueryFlagValue []*string, dryRun *bool, stateEnabled *bool, stateFile *string, stateRemoteURI *string) error {
+	parentQueryFlagValue []*string,
+	gatewayInstance string, dryRun *bool, stateEnabled *bool, stateFile *string, stateRemoteURI *string) error {
 
 	stateCfg := storage.NewStorageConfig(stateEnabled, stateFile, stateRemoteURI)
 	return state.RunWithState(stateCfg, *dryRun, *rootContext.Debug, func(stateRef *model.State) error {
@@ -234,6 +262,7 @@ func runDeleteKind(
 			DryRun:               *dryRun,
 			StateEnabled:         *stateEnabled,
 			StateRef:             stateRef,
+			GatewayInstance:      gatewayInstance,
 		}
 
 		err := deleteHandler.HandleKind(kind, cmdCtx)
@@ -243,3 +272,12 @@ func runDeleteKind(
 		return nil
 	})
 }
+
+// addGatewayInstanceFlag adds the --gateway-instance flag to the delete command of a Gateway kind.
+func addGatewayInstanceFlag(cmd *cobra.Command, rootContext cli.RootContext) *string {
+	gatewayInstance := cmd.Flags().String("gateway-instance", "", "Delete the resource from a named Gateway instance of CDK_GATEWAY_INSTANCES instead of the default Gateway")
+	_ = cmd.RegisterFlagCompletionFunc("gateway-instance", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return rootContext.GatewayInstances(), cobra.ShellCompDirectiveNoFileComp
+	})
+	return gatewayInstance
+}
@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/conduktor/ctl/internal/cli"
+	"github.com/conduktor/ctl/internal/utils"
+	"github.com/spf13/cobra"
+	"github.com/thediveo/enumflag/v2"
+)
+
+type DiffOutputFormat enumflag.Flag
+
+const (
+	UNIFIED DiffOutputFormat = iota
+	SIDE_BY_SIDE
+	DIFF_JSON
+	FIELDS
+)
+
+var DiffOutputFormatIds = map[DiffOutputFormat][]string{
+	UNIFIED:      {"unified"},
+	SIDE_BY_SIDE: {"side-by-side"},
+	DIFF_JSON:    {"json"},
+	FIELDS:       {"fields"},
+}
+
+func initDiff(rootContext cli.RootContext) {
+	var format = UNIFIED
+	var kindName *string
+	var recursiveFolder *bool
+	var width *int
+	var context *int
+	var templating templateFlags
+	var filtering fileFilterFlags
+	parentValues := make(map[string]*string)
+
+	var diffCmd = &cobra.Command{
+		Use:   "diff <source> <target>",
+		Short: "Compare resources between two sources",
+		Long: `Compare the resources of two sources. A source is one of:
+  live                  the Console and Gateway configured in the current environment
+  context:<name>        the environment described by the CDK_* variables of <config dir>/contexts/<name>.env
+  env:<path>            the environment described by the CDK_* variables of an env file
+  state[:<path|uri>]    the resources recorded in a state file, only apiVersion, kind and metadata are compared
+  [file:]<path>         the resources of a local file or folder, - for stdin, an http(s) URL or a git:: source as with apply -f
+
+Exit code is 0 if there is no difference, 1 if there are differences and 2 on error.`,
+		Example: `  # compare local files with the live server
+  conduktor diff ./resources live -r
+
+  # compare the Topics of a cluster between staging and prod
+  conduktor diff context:staging context:prod --kind Topic --cluster my-cluster
+
+  # compare the state with the local files
+  conduktor diff state ./resources -o json`,
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdCtx := cli.DiffHandlerContext{
+				KindName:        *kindName,
+				ParentValues:    make(map[string]string),
+				RecursiveFolder: *recursiveFolder,
+			}
+			var err error
+			cmdCtx.TemplateValues, err = templating.values()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s\n", err)
+				os.Exit(2)
+			}
+			cmdCtx.FileFilter, err = filtering.filter()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s\n", err)
+				os.Exit(2)
+			}
+			cmdCtx.Source, err = cli.ParseDiffSource(args[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s\n", err)
+				os.Exit(2)
+			}
+			cmdCtx.Target, err = cli.ParseDiffSource(args[1])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s\n", err)
+				os.Exit(2)
+			}
+			for flag, value := range parentValues {
+				if cmd.Flags().Changed(flag) {
+					cmdCtx.ParentValues[flag] = *value
+				}
+			}
+
+			diffs, err := cli.DiffHandler(rootContext, cmdCtx)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s\n", err)
+				os.Exit(2)
+			}
+			err = printDiffs(rootContext, diffs, cmdCtx, format, *context, *width)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s\n", err)
+				os.Exit(2)
+			}
+			if len(diffs) > 0 {
+				os.Exit(1)
+			}
+		},
+	}
+
+	diffCmd.Flags().VarP(enumflag.New(&format, "output", DiffOutputFormatIds, enumflag.EnumCaseInsensitive), "output", "o", "Output format. One of: unified|side-by-side|fields|json")
+	kindName = diffCmd.Flags().String("kind", "", "Only compare resources of this kind, remote sources are then listed")
+	recursiveFolder = diffCmd.Flags().BoolP("recursive", "r", false, "Load all .yaml, .yml, .json or .ndjson files in folder sources and their subfolders")
+	width = diffCmd.Flags().Int("width", 80, "Column width of the side-by-side output")
+	context = diffCmd.Flags().IntP("context", "U", utils.DefaultDiffContext, "Number of unchanged lines shown around each change in the unified output")
+	templating = addTemplateFlags(diffCmd)
+	filtering = addFileFilterFlags(diffCmd)
+	for _, flag := range allParentFlags(rootContext.Catalog) {
+		parentValues[flag] = diffCmd.Flags().String(flag, "", "Only compare resources with this "+flag+", required to list kinds scoped by it")
+	}
+
+	rootCmd.AddCommand(diffCmd)
+}
+
+func printDiffs(rootContext cli.RootContext, diffs []cli.ResourceDiff, cmdCtx cli.DiffHandlerContext, format DiffOutputFormat, context, width int) error {
+	if format == DIFF_JSON {
+		if diffs == nil {
+			diffs = []cli.ResourceDiff{}
+		}
+		data, err := json.MarshalIndent(diffs, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	for _, diff := range diffs {
+		name := diff.Kind + "/" + diff.Name
+		if format == FIELDS {
+			fmt.Printf("%s %s\n", diff.Change, name)
+			for _, field := range diff.Fields {
+				printFieldChange(field)
+			}
+			continue
+		}
+		ordering := cli.ArrayOrdering(rootContext.Catalog, diff.Kind)
+		sourceYaml, err := utils.NormalizedYaml(diff.Source, ordering)
+		if err != nil {
+			return err
+		}
+		targetYaml, err := utils.NormalizedYaml(diff.Target, ordering)
+		if err != nil {
+			return err
+		}
+		lines := utils.DiffLines(sourceYaml, targetYaml)
+		if format == SIDE_BY_SIDE {
+			fmt.Printf("%s %s (%s | %s)\n", diff.Change, name, cmdCtx.Source.Label, cmdCtx.Target.Label)
+			fmt.Print(utils.SideBySide(lines, width))
+			continue
+		}
+		sourceHeader, targetHeader := name+"\t"+cmdCtx.Source.Label, name+"\t"+cmdCtx.Target.Label
+		if diff.Source == nil {
+			sourceHeader = "/dev/null"
+		}
+		if diff.Target == nil {
+			targetHeader = "/dev/null"
+		}
+		fmt.Print(colorDiff(utils.UnifiedDiff(sourceHeader, targetHeader, lines, context)))
+	}
+	return nil
+}
+
+func printFieldChange(field utils.FieldChange) {
+	oldValue, _ := json.Marshal(field.Old)
+	newValue, _ := json.Marshal(field.New)
+	switch field.Op {
+	case utils.FieldAdded:
+		fmt.Printf("  + %s: %s\n", field.Path, newValue)
+	case utils.FieldRemoved:
+		fmt.Printf("  - %s: %s\n", field.Path, oldValue)
+	default:
+		fmt.Printf("  ~ %s: %s -> %s\n", field.Path, oldValue, newValue)
+	}
+}
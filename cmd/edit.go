@@ -29,14 +29,14 @@ func initEdit(rootContext cli.RootContext) {
 	rootCmd.AddCommand(editCmd)
 
 	// Add all kinds to the 'edit' command
-	for name, kind := range rootContext.Catalog.Kind {
+	addKindCommands(editCmd, rootContext.Catalog, func(name string, kind schema.Kind) {
 		gatewayKind, isGatewayKind := kind.GetLatestKindVersion().(*schema.GatewayKindVersion)
 		args := cobra.ExactArgs(1) // edit command requires a resource name
 		use := fmt.Sprintf("%s <name>", name)
 
 		// Skip kinds that don't support getting individual resources
 		if isGatewayKind && !gatewayKind.GetAvailable {
-			continue
+			return
 		}
 
 		parentFlags := kind.GetParentFlag()
@@ -167,5 +167,5 @@ func initEdit(rootContext cli.RootContext) {
 		}
 
 		editCmd.AddCommand(kindCmd)
-	}
+	})
 }
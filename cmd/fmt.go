@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/conduktor/ctl/internal/cli"
+	"github.com/spf13/cobra"
+)
+
+func initFmt(rootContext cli.RootContext) {
+	var filePath *[]string
+	var recursiveFolder *bool
+	var check *bool
+	var write *bool
+	var filtering fileFilterFlags
+
+	var fmtCmd = &cobra.Command{
+		Use:   "fmt",
+		Short: "Format resource files in the canonical layout",
+		Long: `Rewrite every resource document of YAML files in the canonical layout: apiVersion, kind, metadata and spec first,
+the keys of metadata and spec in the order of the schema of their kind in the catalog, or of its apply example as printed by
+conduktor template when the catalog has not the schema order, like the offline one, then the other keys in their current order,
+block style, quotes only where needed and an indentation of 2 spaces. Comments are kept. JSON files and Go templates, whose {{ }} actions are not within a YAML value,
+are left as is and reported on stderr.
+By default the formatted files are printed, --write rewrites them and --check lists the files that are not formatted
+and fails if there are some.`,
+		Example: `  conduktor fmt -f ./resources -r --write
+  conduktor fmt -f ./resources -r --check`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true, // do not print usage on run error
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fileFilter, err := filtering.filter()
+			if err != nil {
+				return err
+			}
+			results, err := cli.NewFormatHandler(rootContext).Handle(cli.FormatHandlerContext{
+				FilePaths:  *filePath,
+				Recursive:  *recursiveFolder,
+				FileFilter: fileFilter,
+				Write:      *write,
+			})
+			if err != nil {
+				return err
+			}
+			unformatted := 0
+			for i, result := range results {
+				switch {
+				case *check:
+					if result.Changed {
+						unformatted++
+						fmt.Println(result.Path)
+					}
+				case !*write:
+					if i > 0 {
+						fmt.Println("---")
+					}
+					os.Stdout.Write(result.Formatted)
+				}
+			}
+			if unformatted > 0 {
+				return fmt.Errorf("%d file(s) not formatted, run conduktor fmt --write", unformatted)
+			}
+			return nil
+		},
+	}
+
+	filePath = fmtCmd.Flags().StringArrayP("file", "f", make([]string, 0), "Specify the files or folders to format. For folders, all .yaml or .yml files within the folder will be formatted, while files in subfolders will be ignored. Use - to read stdin")
+	recursiveFolder = fmtCmd.Flags().BoolP("recursive", "r", false, "Format all .yaml or .yml files in the specified folder and its subfolders")
+	check = fmtCmd.Flags().Bool("check", false, "List the files that are not formatted, and exit with an error if there are some")
+	write = fmtCmd.Flags().Bool("write", false, "Rewrite the files that are not formatted")
+	filtering = addFileFilterFlags(fmtCmd)
+	_ = fmtCmd.MarkFlagRequired("file")
+	fmtCmd.MarkFlagsMutuallyExclusive("check", "write")
+
+	rootCmd.AddCommand(fmtCmd)
+}
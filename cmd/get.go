@@ -4,6 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/conduktor/ctl/internal/cli"
 	"github.com/conduktor/ctl/pkg/resource"
@@ -44,6 +47,13 @@ var getCmd = &cobra.Command{
 func initGet(rootContext cli.RootContext) {
 	var format OutputFormat = YAML
 	getCmd.PersistentFlags().VarP(enumflag.New(&format, "output", OutputFormatIds, enumflag.EnumCaseInsensitive), "output", "o", "Output format. One of: json|yaml|name")
+	labelSelector := getCmd.PersistentFlags().StringP("selector", "l", "", "Label selector to filter on, supports '=', '==', '!=', 'key' and '!key' (e.g. -l owner=x,env!=prod)")
+	fieldSelector := getCmd.PersistentFlags().String("field-selector", "", "Field selector to filter on, supports '=', '==', '!=', '>', '>=', '<' and '<=' (e.g. --field-selector spec.partitions>12,metadata.cluster=prod). Equality on metadata.<name> is also sent to the server when the kind supports it as query parameter")
+	showSecrets := getCmd.PersistentFlags().Bool("show-secrets", false, "Show the sensitive fields of the resources, e.g. cluster passwords, instead of masking them")
+	gatewayInstance := getCmd.PersistentFlags().String("gateway-instance", "", "Read the Gateway resources of a named Gateway instance of CDK_GATEWAY_INSTANCES instead of the default Gateway")
+	_ = getCmd.RegisterFlagCompletionFunc("gateway-instance", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return rootContext.GatewayInstances(), cobra.ShellCompDirectiveNoFileComp
+	})
 	rootCmd.AddCommand(getCmd)
 
 	var onlyGateway *bool
@@ -53,7 +63,7 @@ func initGet(rootContext cli.RootContext) {
 		Short: "Get all global resources",
 		Args:  cobra.NoArgs,
 		Run: func(cmd *cobra.Command, args []string) {
-			getAllCommandRun(rootContext, onlyGateway, onlyConsole, format)
+			getAllCommandRun(rootContext, onlyGateway, onlyConsole, parseSelectors(*labelSelector, *fieldSelector), *gatewayInstance, *showSecrets, format)
 		},
 	}
 	onlyGateway = allCmd.Flags().BoolP("gateway", "g", false, "Only show gateway resources")
@@ -62,7 +72,7 @@ func initGet(rootContext cli.RootContext) {
 	getCmd.AddCommand(allCmd)
 
 	// Add all kinds to the 'get' command
-	for name, kind := range rootContext.Catalog.Kind {
+	addKindCommands(getCmd, rootContext.Catalog, func(name string, kind schema.Kind) {
 		gatewayKind, isGatewayKind := kind.GetLatestKindVersion().(*schema.GatewayKindVersion)
 		args := cobra.MaximumNArgs(1)
 		use := fmt.Sprintf("%s [name]", name)
@@ -70,11 +80,12 @@ func initGet(rootContext cli.RootContext) {
 			args = cobra.NoArgs
 			use = name
 		}
-		parentFlags := kind.GetParentFlag()
-		parentQueryFlags := kind.GetParentQueryFlag()
-		parentFlagValue := make([]*string, len(parentFlags))
-		parentQueryFlagValue := make([]*string, len(parentQueryFlags))
 		var multipleFlags *MultipleFlags
+		var watch *bool
+		var interval *time.Duration
+		var until *string
+		var timeout *time.Duration
+		var apiVersion *string
 		kindCmd := &cobra.Command{
 			Use:     use,
 			Short:   "Get resource of kind " + name,
@@ -82,25 +93,130 @@ func initGet(rootContext cli.RootContext) {
 			Long:    `If name not provided it will list all resource`,
 			Aliases: buildAlias(name),
 			Run: func(cmd *cobra.Command, args []string) {
-				getKindCommandRun(rootContext, kind, args, parentFlagValue, parentQueryFlagValue, multipleFlags, format)
+				if *gatewayInstance != "" && !kind.IsGatewayKind() {
+					fmt.Fprintf(os.Stderr, "--gateway-instance only applies to Gateway kinds, %s is a Console kind\n", name)
+					os.Exit(1)
+				}
+				versionKind := kind
+				if *apiVersion != "" {
+					var err error
+					versionKind, err = kind.AtVersion(*apiVersion)
+					if err != nil {
+						fmt.Fprintf(os.Stderr, "%s\n", err)
+						os.Exit(1)
+					}
+				}
+				parentFlagValue, parentQueryFlagValue, err := parentValuesOfKind(cmd, versionKind)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "%s\n", err)
+					os.Exit(1)
+				}
+				selector := parseSelectors(*labelSelector, *fieldSelector)
+				if *watch || *until != "" {
+					untilSelector, err := resource.ParseFieldSelector(*until)
+					if err != nil {
+						fmt.Fprintf(os.Stderr, "Invalid --until condition: %s\n", err)
+						os.Exit(1)
+					}
+					watchCtx := cli.WatchHandlerContext{
+						Interval: *interval,
+						Until:    untilSelector,
+						Timeout:  *timeout,
+					}
+					watchKindCommandRun(rootContext, versionKind, args, parentFlagValue, parentQueryFlagValue, multipleFlags, selector, *gatewayInstance, *showSecrets, watchCtx, format)
+				} else {
+					getKindCommandRun(rootContext, versionKind, args, parentFlagValue, parentQueryFlagValue, multipleFlags, selector, *gatewayInstance, *showSecrets, format)
+				}
 			},
 		}
-		for i, flag := range parentFlags {
-			parentFlagValue[i] = kindCmd.Flags().String(flag, "", "Parent "+flag)
+		multipleFlags = NewMultipleFlags(kindCmd, kind.GetListFlag())
+		addParentFlags(kindCmd, kind)
+		watch = kindCmd.Flags().BoolP("watch", "w", false, "After listing, watch for changes and print ADDED, MODIFIED and DELETED events")
+		interval = kindCmd.Flags().Duration("interval", 5*time.Second, "Polling interval used with --watch")
+		until = kindCmd.Flags().String("until", "", "Watch until every listed resource matches the condition, using the --field-selector syntax (e.g. 'spec.state==RUNNING'), an empty list never matching. Implies --watch")
+		timeout = kindCmd.Flags().Duration("timeout", 0, "Stop watching after this duration, failing if the --until condition does not hold by then. No timeout if zero")
+		apiVersion = kindCmd.Flags().String("api-version", "", "List with the path and parameters of this version of the kind, e.g. v1, instead of the latest one")
+		getCmd.AddCommand(kindCmd)
+	})
+}
+
+// addParentFlags adds the parent flags of every version of a kind, the path ones being required when every version requires them.
+// A parent flag of an older version can share its name with a list flag of the latest version, e.g. cluster.
+func addParentFlags(kindCmd *cobra.Command, kind schema.Kind) {
+	versions := make([]int, 0, len(kind.Versions))
+	for version := range kind.Versions {
+		versions = append(versions, version)
+	}
+	// the flags of the latest version come first
+	sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+	requiredBy := map[string]int{}
+	for _, version := range versions {
+		kindVersion := kind.Versions[version]
+		for _, flag := range kindVersion.GetParentPathParam() {
+			requiredBy[flag]++
+		}
+		for _, flag := range append(append([]string{}, kindVersion.GetParentPathParam()...), kindVersion.GetParentQueryParam()...) {
+			if kindCmd.Flags().Lookup(flag) == nil {
+				kindCmd.Flags().String(flag, "", "Parent "+flag)
+			}
+		}
+	}
+	for flag, count := range requiredBy {
+		if count == len(versions) {
 			_ = kindCmd.MarkFlagRequired(flag)
 		}
-		for i, flag := range parentQueryFlags {
-			parentQueryFlagValue[i] = kindCmd.Flags().String(flag, "", "Parent "+flag)
+	}
+}
+
+// parentValuesOfKind returns the values of the parent path and query flags of the latest version of kind,
+// checking that the path ones are set.
+func parentValuesOfKind(cmd *cobra.Command, kind schema.Kind) ([]*string, []*string, error) {
+	value := func(flag string) *string {
+		value := cmd.Flags().Lookup(flag).Value.String()
+		return &value
+	}
+	parentFlags := kind.GetParentFlag()
+	parentQueryFlags := kind.GetParentQueryFlag()
+	parentFlagValue := make([]*string, len(parentFlags))
+	parentQueryFlagValue := make([]*string, len(parentQueryFlags))
+	var missing []string
+	for i, flag := range parentFlags {
+		parentFlagValue[i] = value(flag)
+		if *parentFlagValue[i] == "" {
+			missing = append(missing, `"`+flag+`"`)
 		}
-		multipleFlags = NewMultipleFlags(kindCmd, kind.GetListFlag())
-		getCmd.AddCommand(kindCmd)
 	}
+	for i, flag := range parentQueryFlags {
+		parentQueryFlagValue[i] = value(flag)
+	}
+	if len(missing) > 0 {
+		return nil, nil, fmt.Errorf("required flag(s) %s not set", strings.Join(missing, ", "))
+	}
+	return parentFlagValue, parentQueryFlagValue, nil
+}
+
+// parseSelectors merges the label and field selectors into a single selector, exiting on invalid input.
+func parseSelectors(labelSelector, fieldSelector string) resource.Selector {
+	labels, err := resource.ParseLabelSelector(labelSelector)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(1)
+	}
+	fields, err := resource.ParseFieldSelector(fieldSelector)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(1)
+	}
+	return append(labels, fields...)
 }
 
-func getAllCommandRun(rootContext cli.RootContext, onlyGateway *bool, onlyConsole *bool, format OutputFormat) {
+func getAllCommandRun(rootContext cli.RootContext, onlyGateway *bool, onlyConsole *bool, selector resource.Selector, gatewayInstance string, showSecrets bool, format OutputFormat) {
 	cmdCtx := cli.GetAllHandlerContext{
-		OnlyGateway: onlyGateway,
-		OnlyConsole: onlyConsole,
+		OnlyGateway:         onlyGateway,
+		OnlyConsole:         onlyConsole,
+		Selector:            selector,
+		GatewayInstance:     gatewayInstance,
+		MaskSensitiveFields: !showSecrets,
 	}
 
 	allResources, errors := cli.GetAllsHandler(rootContext, cmdCtx)
@@ -122,6 +238,9 @@ func getKindCommandRun(
 	parentFlagValue []*string,
 	parentQueryFlagValue []*string,
 	multipleFlags *MultipleFlags,
+	selector resource.Selector,
+	gatewayInstance string,
+	showSecrets bool,
 	format OutputFormat) {
 
 	cmdCtx := cli.GetKindHandlerContext{
@@ -129,6 +248,9 @@ func getKindCommandRun(
 		ParentFlagValue:      parentFlagValue,
 		ParentQueryFlagValue: parentQueryFlagValue,
 		QueryParams:          multipleFlags.ExtractFlagValueForQueryParam(),
+		Selector:             selector,
+		GatewayInstance:      gatewayInstance,
+		MaskSensitiveFields:  !showSecrets,
 	}
 
 	result, errors := cli.GetKindHandler(kind, rootContext, cmdCtx)
@@ -146,10 +268,74 @@ func getKindCommandRun(
 	}
 }
 
+func watchKindCommandRun(
+	rootContext cli.RootContext,
+	kind schema.Kind,
+	args []string,
+	parentFlagValue []*string,
+	parentQueryFlagValue []*string,
+	multipleFlags *MultipleFlags,
+	selector resource.Selector,
+	gatewayInstance string,
+	showSecrets bool,
+	watchCtx cli.WatchHandlerContext,
+	format OutputFormat) {
+
+	if watchCtx.Interval <= 0 {
+		fmt.Fprintf(os.Stderr, "argument --interval must be positive (got %s)\n", watchCtx.Interval)
+		os.Exit(1)
+	}
+	if watchCtx.Timeout < 0 {
+		fmt.Fprintf(os.Stderr, "argument --timeout must not be negative (got %s)\n", watchCtx.Timeout)
+		os.Exit(1)
+	}
+
+	cmdCtx := cli.GetKindHandlerContext{
+		Args:                 args,
+		ParentFlagValue:      parentFlagValue,
+		ParentQueryFlagValue: parentQueryFlagValue,
+		QueryParams:          multipleFlags.ExtractFlagValueForQueryParam(),
+		Selector:             selector,
+		GatewayInstance:      gatewayInstance,
+		MaskSensitiveFields:  !showSecrets,
+	}
+
+	fetch := func() ([]resource.Resource, []error) {
+		return cli.GetKindHandler(kind, rootContext, cmdCtx)
+	}
+	err := cli.Watch(kind, fetch, watchCtx, func(event cli.WatchEvent) error {
+		return printWatchEvent(event, format)
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(1)
+	}
+}
+
+func printWatchEvent(event cli.WatchEvent, format OutputFormat) error {
+	switch format {
+	case JSON:
+		jsonOutput, err := json.MarshalIndent(event, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error marshalling JSON: %s\n%s", err, event.Resource)
+		}
+		fmt.Println(string(jsonOutput))
+	case NAME:
+		fmt.Printf("%s %s/%s\n", event.Type, event.Resource.Kind, event.Resource.Name)
+	case YAML:
+		fmt.Println("---")
+		fmt.Printf("# %s\n", event.Type)
+		_ = event.Resource.PrintPreservingOriginalFieldOrder()
+	default:
+		return fmt.Errorf("invalid output format %s", format.String())
+	}
+	return nil
+}
+
 func printResource(result interface{}, format OutputFormat) error {
 	switch format {
 	case JSON:
-		jsonOutput, err := json.MarshalIndent(result, "", "  ")
+		jsonOutput, err := json.MarshalIndent(withSecretFieldsMasked(result), "", "  ")
 		if err != nil {
 			return fmt.Errorf("error marshalling JSON: %s\n%s", err, result)
 		}
@@ -183,3 +369,19 @@ func printResource(result interface{}, format OutputFormat) error {
 	}
 	return nil
 }
+
+// withSecretFieldsMasked masks the values resolved from secret references of loaded resources, see resource.Resource.SecretFields.
+func withSecretFieldsMasked(result interface{}) interface{} {
+	switch res := result.(type) {
+	case []resource.Resource:
+		masked := make([]resource.Resource, len(res))
+		for i, r := range res {
+			masked[i] = r.WithSecretFieldsMasked()
+		}
+		return masked
+	case resource.Resource:
+		return res.WithSecretFieldsMasked()
+	default:
+		return result
+	}
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/conduktor/ctl/pkg/resource"
+	"github.com/conduktor/ctl/pkg/schema"
+	"github.com/spf13/cobra"
+)
+
+// kindCommandAdders add the sub-command of a kind to the commands having one per kind, e.g. get or delete.
+var kindCommandAdders = map[*cobra.Command]func(name string, kind schema.Kind){}
+
+// addKindCommands adds the sub-commands of the kinds of a catalog to a command, remembering how to add
+// the kinds only found in the live catalog, see addLiveKindCommands.
+func addKindCommands(parent *cobra.Command, catalog schema.Catalog, add func(name string, kind schema.Kind)) {
+	for name, kind := range catalog.Kind {
+		add(name, kind)
+	}
+	kindCommandAdders[parent] = add
+}
+
+// addLiveKindCommands adds the kinds of the live catalog when args call an unknown sub-command of a command
+// having one per kind, e.g. get NewKind, as the commands are registered with the cached or embedded catalog.
+func addLiveKindCommands(args []string) {
+	parent, rest, err := rootCmd.Find(args)
+	if err != nil {
+		return
+	}
+	add, ok := kindCommandAdders[parent]
+	positional := positionalArgs(parent, rest)
+	if !ok || len(positional) == 0 {
+		return
+	}
+	// a cached catalog lacking the kind is downloaded again
+	rootContext.LoadCatalogs(resource.Resource{Kind: positional[0]})
+	for name, kind := range rootContext.Catalog.Kind {
+		if !hasSubCommand(parent, name) {
+			add(name, kind)
+		}
+	}
+}
+
+func hasSubCommand(parent *cobra.Command, name string) bool {
+	for _, cmd := range parent.Commands() {
+		if cmd.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// positionalArgs returns the arguments of a command that are neither flags nor flag values.
+// Unknown flags are considered to take no value.
+func positionalArgs(cmd *cobra.Command, args []string) []string {
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return append(positional, args[i+1:]...)
+		case strings.HasPrefix(arg, "--"):
+			flag := cmd.Flag(strings.TrimPrefix(arg, "--"))
+			if flag != nil && flag.NoOptDefVal == "" {
+				i++
+			}
+		case strings.HasPrefix(arg, "-") && len(arg) == 2:
+			flag := cmd.Flags().ShorthandLookup(arg[1:])
+			if flag == nil {
+				// the persistent flags of the command are only merged into Flags when it runs
+				flag = cmd.PersistentFlags().ShorthandLookup(arg[1:])
+			}
+			if flag == nil {
+				flag = cmd.InheritedFlags().ShorthandLookup(arg[1:])
+			}
+			if flag != nil && flag.NoOptDefVal == "" {
+				i++
+			}
+		case strings.HasPrefix(arg, "-"):
+			// -vv or -o=yaml
+		default:
+			positional = append(positional, arg)
+		}
+	}
+	return positional
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestPositionalArgs(t *testing.T) {
+	root := &cobra.Command{Use: "root"}
+	root.PersistentFlags().CountP("verbose", "v", "")
+	parent := &cobra.Command{Use: "get"}
+	parent.PersistentFlags().StringP("output", "o", "yaml", "")
+	parent.PersistentFlags().Bool("show-secrets", false, "")
+	root.AddCommand(parent)
+
+	testCases := []struct {
+		args     []string
+		expected []string
+	}{
+		{args: []string{}, expected: nil},
+		{args: []string{"-o", "json", "-vv"}, expected: nil},
+		{args: []string{"--output", "json", "--show-secrets", "NewKind"}, expected: []string{"NewKind"}},
+		{args: []string{"-v", "NewKind", "name", "--cluster=prod"}, expected: []string{"NewKind", "name"}},
+		{args: []string{"--output=json", "--", "-name"}, expected: []string{"-name"}},
+	}
+	for _, testCase := range testCases {
+		result := positionalArgs(parent, testCase.args)
+		if !reflect.DeepEqual(result, testCase.expected) {
+			t.Errorf("positionalArgs(%v): expected %v got %v", testCase.args, testCase.expected, result)
+		}
+	}
+}
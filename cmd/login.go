@@ -1,29 +1,61 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/conduktor/ctl/internal/utils"
 	"github.com/conduktor/ctl/pkg/client"
+	"github.com/pkg/browser"
 	"github.com/spf13/cobra"
+	"golang.org/x/oauth2"
 )
 
+const ssoLoginTimeout = 5 * time.Minute
+
+var printToken *bool
+var sso *bool
+var deviceCode *bool
+
 // loginCmd represents the apply command.
 var loginCmd = &cobra.Command{
 	Use:   "login",
-	Short: "Login user using username password to get a JWT token",
-	Long:  `Use must use CDK_USER CDK_PASSWORD environment variables to login`,
-	Args:  cobra.RangeArgs(0, 0),
+	Short: "Login user using username password and cache the session for the following commands",
+	Long: `Use must use CDK_USER CDK_PASSWORD environment variables to login, or --sso to login with the identity provider of the Console.
+The session is cached in the configuration directory for CDK_BASE_URL, following commands then only need CDK_BASE_URL
+until it expires. The password is not cached: a session of CDK_USER/CDK_PASSWORD is only renewed by the commands run with
+the same CDK_USER and CDK_PASSWORD, conduktor login must be run again once it has expired otherwise.
+SSO sessions are refreshed against the identity provider before they expire.`,
+	Args: cobra.RangeArgs(0, 0),
 	Run: func(cmd *cobra.Command, args []string) {
-		specificAPIClient, err := client.Make(client.APIParameter{BaseURL: os.Getenv("CDK_BASE_URL"), Debug: utils.CdkDebug()})
-		if trace {
-			specificAPIClient.ActivateDebug()
-		}
+		baseURL := os.Getenv("CDK_BASE_URL")
+		specificAPIClient, err := client.Make(client.APIParameter{
+			BaseURL:  baseURL,
+			Debug:    utils.CdkDebug(),
+			Key:      os.Getenv("CDK_KEY"),
+			Cert:     os.Getenv("CDK_CERT"),
+			Cacert:   os.Getenv("CDK_CACERT"),
+			Insecure: strings.ToLower(os.Getenv("CDK_INSECURE")) == "true",
+		})
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Could not login: %s\n", err)
 			os.Exit(1)
 		}
+		if trace {
+			specificAPIClient.ActivateDebug()
+		}
+		if *sso {
+			session, err := ssoLogin(specificAPIClient, baseURL, *deviceCode)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Could not login: %s\n", err)
+				os.Exit(4)
+			}
+			saveLoginSession(session)
+			return
+		}
 		username := os.Getenv("CDK_USER")
 		if username == "" {
 			fmt.Fprintln(os.Stderr, "Please set CDK_USER")
@@ -39,10 +71,129 @@ var loginCmd = &cobra.Command{
 			fmt.Fprintf(os.Stderr, "Could not login: %s\n", err)
 			os.Exit(4)
 		}
-		fmt.Println(token.AccessToken)
+		saveLoginSession(client.NewSession(baseURL, username, token))
+	},
+}
+
+func saveLoginSession(session client.Session) {
+	err := client.SaveSession(session)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not save the login session: %s\n", err)
+		os.Exit(5)
+	}
+	if *printToken {
+		fmt.Println(session.AccessToken)
+	} else {
+		fmt.Printf("Logged in to %s as %s\n", session.BaseURL, session.Username)
+	}
+}
+
+// ssoLogin logs in with the identity provider advertised by the Console, in the browser or with a device code.
+func ssoLogin(consoleClient *client.Client, baseURL string, useDeviceCode bool) (client.Session, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ssoLoginTimeout)
+	defer cancel()
+	ssoConfig, err := consoleClient.GetSSOConfig()
+	if err != nil {
+		return client.Session{}, err
+	}
+	provider, err := client.DiscoverOIDC(ctx, ssoConfig.Issuer)
+	if err != nil {
+		return client.Session{}, err
+	}
+	config := client.OAuth2Config(ssoConfig, provider)
+
+	var token *oauth2.Token
+	if useDeviceCode {
+		token, err = client.DeviceLogin(ctx, config, func(deviceAuth *oauth2.DeviceAuthResponse) {
+			if deviceAuth.VerificationURIComplete != "" {
+				fmt.Fprintf(os.Stderr, "To login, visit %s\n", deviceAuth.VerificationURIComplete)
+			} else {
+				fmt.Fprintf(os.Stderr, "To login, visit %s and enter the code %s\n", deviceAuth.VerificationURI, deviceAuth.UserCode)
+			}
+		})
+	} else {
+		token, err = client.BrowserLogin(ctx, config, func(url string) error {
+			fmt.Fprintf(os.Stderr, "Opening the browser to login, if it does not open visit:\n%s\n", url)
+			browser.Stdout = os.Stderr
+			_ = browser.OpenURL(url)
+			return nil
+		})
+	}
+	if err != nil {
+		return client.Session{}, err
+	}
+	return client.NewSSOSession(baseURL, config, token), nil
+}
+
+var logoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Remove the login session cached for CDK_BASE_URL",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		baseURL := os.Getenv("CDK_BASE_URL")
+		if baseURL == "" {
+			fmt.Fprintln(os.Stderr, "Please set CDK_BASE_URL")
+			os.Exit(1)
+		}
+		deleted, err := client.DeleteSession(baseURL)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Could not logout: %s\n", err)
+			os.Exit(1)
+		}
+		if deleted {
+			fmt.Printf("Logged out from %s\n", baseURL)
+		} else {
+			fmt.Printf("Not logged in to %s\n", baseURL)
+		}
 	},
 }
 
+var whoamiCmd = &cobra.Command{
+	Use:   "whoami",
+	Short: "Show the login session cached for CDK_BASE_URL",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		baseURL := os.Getenv("CDK_BASE_URL")
+		if baseURL == "" {
+			fmt.Fprintln(os.Stderr, "Please set CDK_BASE_URL")
+			os.Exit(1)
+		}
+		session, ok, err := client.LoadSession(baseURL)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			os.Exit(1)
+		}
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Not logged in to %s, run conduktor login\n", baseURL)
+			os.Exit(1)
+		}
+		fmt.Printf("Console:  %s\n", session.BaseURL)
+		fmt.Printf("User:     %s\n", session.Username)
+		if session.TokenURL != "" {
+			fmt.Printf("SSO:      %s\n", session.TokenURL)
+		}
+		fmt.Printf("Expires:  %s\n", describeExpiry(session))
+	},
+}
+
+func describeExpiry(session client.Session) string {
+	switch {
+	case session.ExpiresAt.IsZero():
+		return "unknown"
+	case session.Expired() && session.TokenURL != "" && session.RefreshToken != "":
+		return fmt.Sprintf("%s (expired, refreshed on next use)", session.ExpiresAt.Local().Format(time.RFC3339))
+	case session.Expired():
+		return fmt.Sprintf("%s (expired)", session.ExpiresAt.Local().Format(time.RFC3339))
+	default:
+		return fmt.Sprintf("%s (in %s)", session.ExpiresAt.Local().Format(time.RFC3339), time.Until(session.ExpiresAt).Round(time.Second))
+	}
+}
+
 func init() {
+	sso = loginCmd.Flags().Bool("sso", false, "Login with the SSO identity provider of the Console, in the browser")
+	deviceCode = loginCmd.Flags().Bool("device-code", false, "With --sso, login with a code entered on another device instead of opening a browser")
+	printToken = loginCmd.Flags().Bool("print-token", true, "Print the JWT token on stdout, use --print-token=false to print a confirmation message instead")
 	rootCmd.AddCommand(loginCmd)
+	rootCmd.AddCommand(logoutCmd)
+	rootCmd.AddCommand(whoamiCmd)
 }
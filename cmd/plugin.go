@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"text/tabwriter"
+
+	"github.com/conduktor/ctl/internal/cli"
+	"github.com/spf13/cobra"
+)
+
+func initPlugin(rootContext cli.RootContext) {
+	var pluginCmd = &cobra.Command{
+		Use:   "plugin",
+		Short: "Manage the plugins, executables named conduktor-<name> run by conduktor <name>",
+		Long: `Any conduktor-<name> executable of the PATH is run by conduktor <name>, with the remaining arguments.
+The plugin gets the connection settings of the Console and the Gateways in CDK_PLUGIN_INFO,
+and the catalog of kinds in CDK_PLUGIN_CATALOG, both as JSON.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			_ = cmd.Help()
+			os.Exit(1)
+		},
+	}
+
+	var listCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List the plugins of the PATH",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			plugins := cli.FindPlugins(os.Getenv("PATH"), isBuiltinCommand)
+			if len(plugins) == 0 {
+				fmt.Fprintln(os.Stderr, "No plugin found in the PATH")
+				return
+			}
+			writer := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+			fmt.Fprintln(writer, "NAME\tPATH")
+			for _, plugin := range plugins {
+				if plugin.ShadowedBy == "" {
+					fmt.Fprintf(writer, "%s\t%s\n", plugin.Name, plugin.Path)
+				}
+			}
+			_ = writer.Flush()
+			for _, plugin := range plugins {
+				if plugin.ShadowedBy != "" {
+					fmt.Fprintf(os.Stderr, "Warning: %s is shadowed by %s\n", plugin.Path, plugin.ShadowedBy)
+				}
+			}
+		},
+	}
+
+	pluginCmd.AddCommand(listCmd)
+	rootCmd.AddCommand(pluginCmd)
+}
+
+// isBuiltinCommand tells whether a name, or alias, is taken by a command of the CLI.
+func isBuiltinCommand(name string) bool {
+	// added by Execute otherwise
+	rootCmd.InitDefaultHelpCmd()
+	rootCmd.InitDefaultCompletionCmd()
+	cmd, _, err := rootCmd.Find([]string{name})
+	return err == nil && cmd != rootCmd
+}
+
+// runPlugin runs the plugin named by the first argument with the other ones, e.g. conduktor-topic-report
+// for conduktor topic-report, and exits with its exit code. It returns if there is no such plugin.
+// The PATH is only searched when the first argument is not a command of the CLI, so that the commands are never slowed down.
+func runPlugin(args []string) {
+	if len(args) == 0 || !cli.IsPluginName(args[0]) || isBuiltinCommand(args[0]) {
+		return
+	}
+	path, err := exec.LookPath(cli.PluginPrefix + args[0])
+	if err != nil {
+		return
+	}
+	env, err := cli.PluginEnv(rootContext, args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot run plugin %s: %s\n", args[0], err)
+		os.Exit(1)
+	}
+	plugin := exec.Command(path, args[1:]...)
+	plugin.Stdin = os.Stdin
+	plugin.Stdout = os.Stdout
+	plugin.Stderr = os.Stderr
+	plugin.Env = append(os.Environ(), env...)
+	// the plugin handles Ctrl+C, receiving it along with the CLI
+	signal.Ignore(os.Interrupt)
+	err = plugin.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		os.Exit(exitErr.ExitCode())
+	} else if err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot run plugin %s: %s\n", args[0], err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
@@ -4,12 +4,12 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/conduktor/ctl/internal/cli"
 	"github.com/conduktor/ctl/internal/utils"
-	"github.com/conduktor/ctl/pkg/schema"
 	"github.com/spf13/cobra"
 )
 
-func initPrintCatalog(kinds schema.Catalog) {
+func initPrintCatalog(rootContext cli.RootContext) {
 
 	var prettyPrint *bool
 
@@ -23,6 +23,8 @@ func initPrintCatalog(kinds schema.Catalog) {
 		Run: func(cmd *cobra.Command, args []string) {
 			var payload []byte
 			var err error
+			rootContext.LoadCatalogs()
+			kinds := rootContext.Catalog
 			if *prettyPrint {
 				payload, err = json.MarshalIndent(kinds, "", "  ")
 			} else {
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"github.com/conduktor/ctl/internal/cli"
+	"github.com/conduktor/ctl/pkg/resource"
+	"github.com/spf13/cobra"
+	"github.com/thediveo/enumflag/v2"
+)
+
+func initRender(rootContext cli.RootContext) {
+	var format OutputFormat = YAML
+	var filePath *[]string
+	var recursiveFolder *bool
+	var templating templateFlags
+	var filtering fileFilterFlags
+	var strictYaml *bool
+
+	var renderCmd = &cobra.Command{
+		Use:   "render",
+		Short: "Print the resources of files as apply would load them",
+		Long: `Render the files as Go templates if --values or --set is given, then resolve their variables, secret references and includes,
+and print the resulting resources. apply, delete and diff load files the same way with the same flags.`,
+		Example:      `  conduktor render -f ./resources -r --values values-prod.yaml --set topic.partitions=6`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true, // do not print usage on run error
+		RunE: func(cmd *cobra.Command, args []string) error {
+			templateValues, err := templating.values()
+			if err != nil {
+				return err
+			}
+			fileFilter, err := filtering.filter()
+			if err != nil {
+				return err
+			}
+			loader := rootContext.Loader(*recursiveFolder, templateValues, fileFilter)
+			loader.StrictYaml = *strictYaml
+			resources, err := cli.LoadResourcesFromFiles(*filePath, loader)
+			if err != nil {
+				return err
+			}
+			if resources == nil {
+				resources = []resource.Resource{}
+			}
+			return printResource(resources, format)
+		},
+	}
+
+	filePath = renderCmd.Flags().StringArrayP("file", "f", make([]string, 0), "Specify the files or folders to render. For folders, all .yaml, .yml, .json or .ndjson files within the folder will be rendered, while files in subfolders will be ignored. Use - to read stdin, an http(s) URL or git::<repository>//<path>?ref=<ref>")
+	recursiveFolder = renderCmd.Flags().BoolP("recursive", "r", false, "Render all .yaml, .yml, .json or .ndjson files in the specified folder and its subfolders")
+	renderCmd.Flags().VarP(enumflag.New(&format, "output", OutputFormatIds, enumflag.EnumCaseInsensitive), "output", "o", "Output format. One of: json|yaml|name")
+	templating = addTemplateFlags(renderCmd)
+	filtering = addFileFilterFlags(renderCmd)
+	strictYaml = renderCmd.Flags().Bool("strict-yaml", false, STRICT_YAML_DOC)
+	_ = renderCmd.MarkFlagRequired("file")
+
+	rootCmd.AddCommand(renderCmd)
+}
@@ -5,34 +5,26 @@ import (
 	"os"
 
 	"github.com/conduktor/ctl/internal/cli"
+	"github.com/conduktor/ctl/internal/utils"
 	"github.com/conduktor/ctl/pkg/client"
-	"github.com/conduktor/ctl/pkg/schema"
 	"github.com/spf13/cobra"
+	"github.com/thediveo/enumflag/v2"
 )
 
 var rootContext cli.RootContext
 var verbosity int
 var debug bool
 var trace bool
-var apiClient_ *client.Client
-var consoleAPIClientError error
-var gatewayAPIClient_ *client.GatewayClient
-var gatewayAPIClientError error
+var colorMode = utils.ColorAuto
+var offline bool
+var allowExecSecrets bool
 
 func consoleAPIClient() *client.Client {
-	if consoleAPIClientError != nil {
-		fmt.Fprintf(os.Stderr, "Cannot create client: %s", consoleAPIClientError)
-		os.Exit(1)
-	}
-	return apiClient_
+	return rootContext.ConsoleAPIClient()
 }
 
 func gatewayAPIClient() *client.GatewayClient {
-	if gatewayAPIClientError != nil {
-		fmt.Fprintf(os.Stderr, "Cannot create gateway client: %s", gatewayAPIClientError)
-		os.Exit(1)
-	}
-	return gatewayAPIClient_
+	return rootContext.GatewayAPIClient()
 }
 
 // rootCmd represents the base command when called without any subcommands.
@@ -47,14 +39,8 @@ For server TLS authentication, you can ignore the certificate by setting CDK_INS
 		trace = verbosity >= 2 // trace implies debug
 
 		if trace {
-			// ActivateDebug() will enable debug mode for the resty client.
-			// Doesn't need to be set if the client was not initialised correctly.
-			if consoleAPIClientError == nil {
-				consoleAPIClient().ActivateDebug()
-			}
-			if gatewayAPIClientError == nil {
-				gatewayAPIClient().ActivateDebug()
-			}
+			// ActivateDebug() will enable debug mode for the resty clients, once created.
+			rootContext.ActivateDebug()
 		}
 	},
 	Run: func(cmd *cobra.Command, args []string) {
@@ -66,6 +52,8 @@ For server TLS authentication, you can ignore the certificate by setting CDK_INS
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
+	runPlugin(os.Args[1:])
+	addLiveKindCommands(os.Args[1:])
 	err := rootCmd.Execute()
 	if err != nil {
 		os.Exit(1)
@@ -73,43 +61,61 @@ func Execute() {
 }
 
 func init() {
-	apiClient_, consoleAPIClientError = client.MakeFromEnv()
-	var consoleKinds *schema.Catalog
-	if consoleAPIClientError == nil {
-		consoleKinds = apiClient_.GetCatalog()
-	} else {
-		consoleKinds = schema.ConsoleDefaultCatalog()
-	}
-	gatewayAPIClient_, gatewayAPIClientError = client.MakeGatewayClientFromEnv()
-	var gatewayKinds *schema.Catalog
-	if gatewayAPIClientError == nil {
-		gatewayKinds = gatewayAPIClient().GetCatalog()
-	} else {
-		gatewayKinds = schema.GatewayDefaultCatalog()
+	// read when the clients are created, after the flags are parsed
+	getenv := func(name string) string {
+		if name == "CDK_OFFLINE" && offline {
+			return "true"
+		}
+		return os.Getenv(name)
 	}
-	catalog := consoleKinds.Merge(gatewayKinds)
+	// the commands of the kinds are registered without reaching the APIs, the clients being created on first use
+	consoleKinds, _ := client.LocalConsoleCatalog(getenv)
+	gatewayKinds, _ := client.LocalGatewayCatalog(getenv)
 	rootCmd.PersistentFlags().CountVarP(&verbosity, "verbose", "v", "verbose output (can be repeated e.g: -v = debug / -vv = trace)")
+	rootCmd.PersistentFlags().BoolVar(&offline, "offline", false, "Do not download the catalog of kinds, use the cached one whatever its age or the one embedded in the CLI. Also set by CDK_OFFLINE=true")
 	var permissive = rootCmd.PersistentFlags().Bool("permissive", false, "Permissive mode, allow undefined environment variables")
+	rootCmd.PersistentFlags().BoolVar(&allowExecSecrets, "allow-exec-secrets", false, "Resolve the ${exec:} and ${file:} secret references of local files, running commands and reading files of this machine. Never for URL and git sources")
+	rootCmd.PersistentFlags().Var(enumflag.New(&colorMode, "color", map[utils.ColorMode][]string{
+		utils.ColorAuto:   {"auto"},
+		utils.ColorAlways: {"always"},
+		utils.ColorNever:  {"never"},
+	}, enumflag.EnumCaseInsensitive), "color", "Colorize diffs. One of: auto|always|never. auto colors when the output is a terminal and NO_COLOR is not set")
 	strict := !*permissive
 
-	rootContext = cli.NewRootContext(
-		apiClient_,
-		consoleAPIClientError,
-		gatewayAPIClient_,
-		gatewayAPIClientError,
-		catalog,
+	rootContext = cli.NewLazyRootContext(
+		func() (*client.Client, error) { return client.MakeFromEnvLookup(getenv) },
+		consoleKinds,
+		func() (*client.GatewayClient, error) { return client.MakeGatewayClientFromEnvLookup(getenv) },
+		gatewayKinds,
 		strict,
 		&debug,
 	)
+	rootContext.AllowExecSecrets = &allowExecSecrets
+	gatewayInstances, err := client.GatewayInstanceNames(getenv)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", err)
+	}
+	rootContext = rootContext.WithGatewayInstances(gatewayInstances, func(name string) (*client.GatewayClient, error) {
+		return client.MakeGatewayInstanceClientFromEnvLookup(getenv, name)
+	})
 
 	initGet(rootContext)
 	initTemplate(rootContext)
 	initEdit(rootContext)
 	initDelete(rootContext)
 	initApply(rootContext)
+	initWait(rootContext)
+	initDiff(rootContext)
+	initRender(rootContext)
+	initValidate(rootContext)
+	initBuild(rootContext)
+	initFmt(rootContext)
+	initConvert(rootContext)
+	initCatalog(rootContext)
 	intConsoleMakeCatalog()
 	initGatewayMakeCatalog()
-	initPrintCatalog(catalog)
-	initSQL(catalog.Kind)
-	initRun(catalog.Run)
+	initPrintCatalog(rootContext)
+	initPlugin(rootContext)
+	initSQL(rootContext.Catalog.Kind)
+	initRun(rootContext.Catalog.Run)
 }
@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/conduktor/ctl/internal/cli"
+	"github.com/conduktor/ctl/pkg/schema"
 	"github.com/spf13/cobra"
 )
 
@@ -30,7 +31,7 @@ func initTemplate(rootContext cli.RootContext) {
 	apply = templateCmd.PersistentFlags().BoolP("apply", "a", false, "Apply the YAML file post-editing; this works only with --edit.")
 
 	// Add all kinds to the 'template' command
-	for name, kind := range rootContext.Catalog.Kind {
+	addKindCommands(templateCmd, rootContext.Catalog, func(name string, kind schema.Kind) {
 		kindCmd := &cobra.Command{
 			Use:     name,
 			Short:   "Get a yaml example for resource of kind " + name,
@@ -97,7 +98,7 @@ func initTemplate(rootContext cli.RootContext) {
 			},
 		}
 		templateCmd.AddCommand(kindCmd)
-	}
+	})
 }
 
 func editAndApply(rootContext cli.RootContext, edit *bool, file *string, apply *bool) {
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/conduktor/ctl/internal/cli"
+	"github.com/spf13/cobra"
+)
+
+func initValidate(rootContext cli.RootContext) {
+	var filePath *[]string
+	var recursiveFolder *bool
+	var templating templateFlags
+	var filtering fileFilterFlags
+	var strictYaml *bool
+
+	var validateCmd = &cobra.Command{
+		Use:   "validate",
+		Short: "Check resource files without applying them",
+		Long: `Load the files as apply would, without calling any API, and check that the kind and version of every resource
+are in the catalog and that it has the metadata of its parents, e.g. the cluster of a Topic.
+The structure of the files is validated as with --strict-yaml of apply, tabs in the indentation included, unless --strict-yaml=false.`,
+		Example: `  conduktor validate -f ./resources -r
+  conduktor validate -f ./resources -r --values values-prod.yaml`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true, // do not print usage on run error
+		RunE: func(cmd *cobra.Command, args []string) error {
+			templateValues, err := templating.values()
+			if err != nil {
+				return err
+			}
+			fileFilter, err := filtering.filter()
+			if err != nil {
+				return err
+			}
+			resources, err := cli.NewValidateHandler(rootContext).Handle(cli.ValidateHandlerContext{
+				FilePaths:      *filePath,
+				Recursive:      *recursiveFolder,
+				TemplateValues: templateValues,
+				FileFilter:     fileFilter,
+				StrictYaml:     *strictYaml,
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%d resource(s) valid\n", len(resources))
+			return nil
+		},
+	}
+
+	filePath = validateCmd.Flags().StringArrayP("file", "f", make([]string, 0), "Specify the files or folders to validate. For folders, all .yaml, .yml, .json or .ndjson files within the folder will be validated, while files in subfolders will be ignored. Use - to read stdin, an http(s) URL or git::<repository>//<path>?ref=<ref>")
+	recursiveFolder = validateCmd.Flags().BoolP("recursive", "r", false, "Validate all .yaml, .yml, .json or .ndjson files in the specified folder and its subfolders")
+	templating = addTemplateFlags(validateCmd)
+	filtering = addFileFilterFlags(validateCmd)
+	strictYaml = validateCmd.Flags().Bool("strict-yaml", true, STRICT_YAML_DOC)
+	_ = validateCmd.MarkFlagRequired("file")
+
+	rootCmd.AddCommand(validateCmd)
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/conduktor/ctl/internal/cli"
+	"github.com/conduktor/ctl/pkg/resource"
+	"github.com/conduktor/ctl/pkg/schema"
+	"github.com/spf13/cobra"
+)
+
+func initWait(rootContext cli.RootContext) {
+	var condition *string
+	var timeout *time.Duration
+	var interval *time.Duration
+	parentValues := make(map[string]*string)
+
+	var waitCmd = &cobra.Command{
+		Use:   "wait <kind>/<name>",
+		Short: "Wait for a resource to match a condition",
+		Long: `Poll the resource with an exponential backoff until it matches the condition.
+If --for is not provided, the ready condition declared by the kind in the catalog is used, or the resource existence if none is declared.
+On timeout, the last observed resource is printed and the command exits with a non-zero code.`,
+		Example: `  conduktor wait Connector/my-connector --cluster my-cluster --connectCluster my-connect --for 'jsonpath=.spec.state=RUNNING' --timeout 5m
+  conduktor wait Topic/my-topic --cluster my-cluster --for 'field=spec.partitions>=3'`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true, // do not print usage on run error
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rootContext.LoadCatalogs()
+			kind, name, err := parseKindAndName(rootContext.Catalog, args[0])
+			if err != nil {
+				return err
+			}
+			waitCondition, err := cli.ParseWaitCondition(*condition)
+			if err != nil {
+				return err
+			}
+			parentFlagValue := make([]*string, len(kind.GetParentFlag()))
+			for i, flag := range kind.GetParentFlag() {
+				if *parentValues[flag] == "" {
+					return fmt.Errorf("required flag \"%s\" not set for kind %s", flag, kind.GetName())
+				}
+				parentFlagValue[i] = parentValues[flag]
+			}
+			parentQueryFlagValue := make([]*string, len(kind.GetParentQueryFlag()))
+			for i, flag := range kind.GetParentQueryFlag() {
+				parentQueryFlagValue[i] = parentValues[flag]
+			}
+
+			cmdCtx := cli.WaitHandlerContext{
+				Name:                 name,
+				ParentFlagValue:      parentFlagValue,
+				ParentQueryFlagValue: parentQueryFlagValue,
+				Condition:            waitCondition,
+				Interval:             *interval,
+				MaxInterval:          cli.DefaultWaitMaxInterval,
+				Timeout:              *timeout,
+			}
+			lastObserved, err := cli.WaitHandler(kind, rootContext, cmdCtx)
+			if err != nil {
+				return waitError(lastObserved, err)
+			}
+			fmt.Printf("%s/%s: condition met\n", kind.GetName(), name)
+			return nil
+		},
+	}
+
+	condition = waitCmd.Flags().String("for", "", "Condition to wait for. One of: jsonpath=<path>[=<value>], field=<field selector>, exists. Defaults to the ready condition of the kind")
+	timeout = waitCmd.Flags().Duration("timeout", cli.DefaultWaitTimeout, "Maximum duration to wait for the condition")
+	interval = waitCmd.Flags().Duration("interval", cli.DefaultWaitInterval, "Initial polling interval, doubled after each poll up to "+cli.DefaultWaitMaxInterval.String())
+	for _, flag := range allParentFlags(rootContext.Catalog) {
+		parentValues[flag] = waitCmd.Flags().String(flag, "", "Parent "+flag+", required by kinds that are scoped by it")
+	}
+
+	rootCmd.AddCommand(waitCmd)
+}
+
+// parseKindAndName splits a <kind>/<name> argument, the kind being matched case-insensitively.
+func parseKindAndName(catalog schema.Catalog, arg string) (schema.Kind, string, error) {
+	kindName, name, found := strings.Cut(arg, "/")
+	if !found || kindName == "" || name == "" {
+		return schema.Kind{}, "", fmt.Errorf("invalid argument \"%s\": expected <kind>/<name>", arg)
+	}
+	for catalogKindName, kind := range catalog.Kind {
+		if strings.EqualFold(catalogKindName, kindName) {
+			return kind, name, nil
+		}
+	}
+	// a kind defined by both the Console and the Gateway must be qualified, e.g. gateway:ServiceAccount
+	kind, err := catalog.LookupKind(kindName, "")
+	return kind, name, err
+}
+
+// allParentFlags returns the sorted distinct parent path and query parameters of every kind of the catalog.
+func allParentFlags(catalog schema.Catalog) []string {
+	seen := make(map[string]bool)
+	var flags []string
+	for _, kind := range catalog.Kind {
+		kindFlags := append([]string{}, kind.GetParentFlag()...)
+		for _, flag := range append(kindFlags, kind.GetParentQueryFlag()...) {
+			if !seen[flag] {
+				seen[flag] = true
+				flags = append(flags, flag)
+			}
+		}
+	}
+	sort.Strings(flags)
+	return flags
+}
+
+// waitError prints the last observed resource, if any, on a wait timeout and returns the error to report.
+func waitError(lastObserved *resource.Resource, err error) error {
+	var timeoutErr *cli.ErrWatchTimeout
+	if errors.As(err, &timeoutErr) {
+		if lastObserved != nil {
+			fmt.Fprintln(os.Stderr, "Last observed resource:")
+			printErr := printResource([]resource.Resource{*lastObserved}, YAML)
+			if printErr != nil {
+				fmt.Fprintf(os.Stderr, "%s\n", printErr)
+			}
+		} else {
+			fmt.Fprintln(os.Stderr, "Resource was never observed")
+		}
+	}
+	return err
+}
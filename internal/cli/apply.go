@@ -12,13 +12,21 @@ import (
 )
 
 type ApplyHandlerContext struct {
-	FilePaths       []string
+	FilePaths []string
+	// Overlays are built and applied along the files, see resource.Loader.Build
+	Overlays        []string
 	RecursiveFolder bool
 	DryRun          bool
 	PrintDiff       bool
 	MaxParallel     int
 	StateEnabled    bool
 	StateRef        *model.State
+	// TemplateValues enable the rendering of the files as templates when not nil
+	TemplateValues map[string]interface{}
+	// FileFilter selects the files loaded from folders
+	FileFilter resource.FileFilter
+	// StrictYaml validates the structure of the files, see resource.Loader
+	StrictYaml bool
 }
 
 type ApplyResult struct {
@@ -42,17 +50,28 @@ func (h *ApplyHandler) Handle(cmdCtx ApplyHandlerContext) ([]ApplyResult, error)
 	dryRun := cmdCtx.DryRun
 	stateRef := cmdCtx.StateRef
 
-	// Load resources from files
-	resources, err := LoadResourcesFromFiles(cmdCtx.FilePaths, h.rootCtx.Strict, cmdCtx.RecursiveFolder)
+	// Load resources from files and overlays
+	loader := h.rootCtx.Loader(cmdCtx.RecursiveFolder, cmdCtx.TemplateValues, cmdCtx.FileFilter)
+	loader.StrictYaml = cmdCtx.StrictYaml
+	resources, err := LoadResourcesFromFiles(cmdCtx.FilePaths, loader)
 	if err != nil {
 		return nil, err
 	}
+	overlayResources, err := BuildOverlays(cmdCtx.Overlays, loader)
+	if err != nil {
+		return nil, err
+	}
+	resources = append(resources, overlayResources...)
 
 	if len(resources) == 0 {
 		fmt.Fprintln(os.Stderr, "No resources found to apply")
 		return []ApplyResult{}, nil
 	}
 
+	// the kinds of the files can be missing from the catalog the commands were registered with
+	h.rootCtx.LoadCatalogs(resources...)
+	WarnDeprecatedVersions(h.rootCtx.Catalog, resources)
+
 	// Sort resources for proper apply order
 	schema.SortResourcesForApply(h.rootCtx.Catalog.Kind, resources, debug)
 
@@ -84,37 +103,49 @@ func (h *ApplyHandler) Handle(cmdCtx ApplyHandlerContext) ([]ApplyResult, error)
 	}
 
 	fmt.Fprintln(os.Stderr, "Applying resources")
-	// Group resources by kind
-	kindGroups := make(map[string][]resource.Resource)
-	var kindOrder []string
+	// Group resources by their resolved kind, a kind defined by both the Console and the Gateway making two groups
+	type kindGroup struct {
+		name    string
+		gateway bool
+	}
+	kindGroups := make(map[kindGroup][]resource.Resource)
+	var kindOrder []kindGroup
 	for _, resrc := range resources {
-		if _, exists := kindGroups[resrc.Kind]; !exists {
-			kindOrder = append(kindOrder, resrc.Kind)
+		group := kindGroup{name: resrc.Kind}
+		if kind, err := h.rootCtx.Catalog.LookupKind(resrc.Kind, resrc.Version); err == nil {
+			group = kindGroup{name: kind.GetName(), gateway: kind.IsGatewayKind()}
+		}
+		if _, exists := kindGroups[group]; !exists {
+			kindOrder = append(kindOrder, group)
 		}
-		kindGroups[resrc.Kind] = append(kindGroups[resrc.Kind], resrc)
+		kindGroups[group] = append(kindGroups[group], resrc)
 	}
 
 	var allResults []ApplyResult
 
 	// Process each kind group
-	for _, kind := range kindOrder {
-		kindResources := kindGroups[kind]
+	for _, group := range kindOrder {
+		kindResources := kindGroups[group]
 		if len(kindResources) == 0 {
 			continue
 		}
 
 		var groupResults []ApplyResult
-		if h.rootCtx.Catalog.IsGatewayResource(kindResources[0]) {
-			if h.rootCtx.gatewayAPIClientError != nil && h.rootCtx.gatewayAPIClient == nil {
-				return nil, fmt.Errorf("cannot apply GatewayAPI resources %s: %s", kind, h.rootCtx.gatewayAPIClientError)
+		if group.gateway {
+			gatewayClients, err := h.rootCtx.gatewayClientsOf(kindResources)
+			if err != nil {
+				return nil, fmt.Errorf("cannot apply GatewayAPI resources %s: %s", group.name, err)
 			}
-			gatewayClient := h.rootCtx.gatewayAPIClient
-			groupResults = h.applyResources(kindResources, gatewayClient.Apply, cmdCtx)
+			groupResults = h.applyResources(kindResources, func(res *resource.Resource, dryRun, diff bool) (client.Result, error) {
+				// metadata.gateway only routes the resource to its Gateway instance
+				gatewayResource := res.WithGatewayInstance("")
+				return gatewayClients[res.GatewayInstance()].Apply(&gatewayResource, dryRun, diff)
+			}, cmdCtx)
 		} else {
-			if h.rootCtx.consoleAPIClientError != nil && h.rootCtx.consoleAPIClient == nil {
-				return nil, fmt.Errorf("cannot apply ConsoleAPI resources %s: %s", kind, h.rootCtx.consoleAPIClientError)
+			consoleClient, err := h.rootCtx.consoleClient()
+			if err != nil {
+				return nil, fmt.Errorf("cannot apply ConsoleAPI resources %s: %s", group.name, err)
 			}
-			consoleClient := h.rootCtx.consoleAPIClient
 			groupResults = h.applyResources(kindResources, consoleClient.Apply, cmdCtx)
 		}
 
@@ -2,6 +2,11 @@ package cli
 
 import (
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 
@@ -132,3 +137,120 @@ func TestApplyHandler_applyResources_Sequential(t *testing.T) {
 	assert.Equal(t, "applied-B-2", results[1].UpsertResult.UpsertResult)
 	assert.NoError(t, results[1].Err)
 }
+
+func TestApplyHandler_RoutesTheGatewayResourcesToTheirInstance(t *testing.T) {
+	var mu sync.Mutex
+	received := map[string][]string{}
+	gatewayServer := func(instance string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			mu.Lock()
+			received[instance] = append(received[instance], r.Method+" "+r.URL.Path+" "+string(body))
+			mu.Unlock()
+			_, _ = w.Write([]byte(`{"upsertResult": "Created"}`))
+		}))
+	}
+	defaultServer := gatewayServer("default")
+	defer defaultServer.Close()
+	euServer := gatewayServer("eu")
+	defer euServer.Close()
+	makeGateway := func(baseURL string) (*client.GatewayClient, error) {
+		return client.MakeGateway(client.GatewayAPIParameter{
+			BaseURL:            baseURL,
+			CdkGatewayUser:     "admin",
+			CdkGatewayPassword: "conduktor",
+			Catalog:            client.CatalogOptions{Offline: true},
+		})
+	}
+
+	dir := t.TempDir()
+	writeFile := func(path, content string) {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	writeFile(filepath.Join(dir, "default.yaml"), "apiVersion: gateway/v2\nkind: VirtualCluster\nmetadata:\n  name: vc1\nspec: {}\n")
+	writeFile(filepath.Join(dir, "metadata.yaml"), "apiVersion: gateway/v2\nkind: VirtualCluster\nmetadata:\n  name: vc2\n  gateway: eu\nspec: {}\n")
+	writeFile(filepath.Join(dir, "gateways", "eu", "folder.yaml"), "apiVersion: gateway/v2\nkind: VirtualCluster\nmetadata:\n  name: vc3\nspec: {}\n")
+
+	debug := false
+	rootCtx := NewLazyRootContext(
+		func() (*client.Client, error) { return nil, fmt.Errorf("Please set CDK_BASE_URL") },
+		schema.ConsoleDefaultCatalog(),
+		func() (*client.GatewayClient, error) { return makeGateway(defaultServer.URL) },
+		schema.GatewayDefaultCatalog(),
+		true,
+		&debug,
+	).WithGatewayInstances([]string{"eu"}, func(name string) (*client.GatewayClient, error) { return makeGateway(euServer.URL) })
+
+	results, err := NewApplyHandler(rootCtx).Handle(ApplyHandlerContext{FilePaths: []string{dir}, RecursiveFolder: true, MaxParallel: 1})
+
+	assert.NoError(t, err)
+	assert.Len(t, results, 3)
+	for _, result := range results {
+		assert.NoError(t, result.Err)
+	}
+	assert.Equal(t, []string{`PUT /gateway/v2/virtual-cluster {"apiVersion":"gateway/v2","kind":"VirtualCluster","metadata":{"name":"vc1"},"spec":{}}`}, received["default"])
+	assert.ElementsMatch(t, []string{
+		`PUT /gateway/v2/virtual-cluster {"apiVersion":"gateway/v2","kind":"VirtualCluster","metadata":{"name":"vc2"},"spec":{}}`,
+		`PUT /gateway/v2/virtual-cluster {"apiVersion":"gateway/v2","kind":"VirtualCluster","metadata":{"name":"vc3"},"spec":{}}`,
+	}, received["eu"])
+}
+
+func TestApplyHandler_UnknownGatewayInstance(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "vc.yaml"), []byte("apiVersion: gateway/v2\nkind: VirtualCluster\nmetadata:\n  name: vc1\n  gateway: us\nspec: {}\n"), 0644))
+	debug := false
+	rootCtx := NewRootContext(nil, fmt.Errorf("Please set CDK_BASE_URL"), nil, fmt.Errorf("Please set CDK_GATEWAY_BASE_URL"), schema.GatewayDefaultCatalog().Merge(schema.ConsoleDefaultCatalog()), true, &debug)
+
+	_, err := NewApplyHandler(rootCtx).Handle(ApplyHandlerContext{FilePaths: []string{dir}, MaxParallel: 1})
+
+	assert.EqualError(t, err, "cannot apply GatewayAPI resources VirtualCluster: unknown Gateway instance us, expected one of CDK_GATEWAY_INSTANCES")
+}
+
+func TestApplyHandler_RoutesAKindOfBothBackendsByItsAPIVersion(t *testing.T) {
+	var mu sync.Mutex
+	received := map[string][]string{}
+	recordingServer := func(backend string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			mu.Lock()
+			received[backend] = append(received[backend], r.Method+" "+r.URL.Path+" "+string(body))
+			mu.Unlock()
+			_, _ = w.Write([]byte(`{"upsertResult": "Created"}`))
+		}))
+	}
+	consoleServer := recordingServer("console")
+	defer consoleServer.Close()
+	gatewayServer := recordingServer("gateway")
+	defer gatewayServer.Close()
+
+	console, err := client.Make(client.APIParameter{BaseURL: consoleServer.URL, APIKey: "key", Catalog: client.CatalogOptions{Offline: true}})
+	assert.NoError(t, err)
+	gateway, err := client.MakeGateway(client.GatewayAPIParameter{
+		BaseURL:            gatewayServer.URL,
+		CdkGatewayUser:     "admin",
+		CdkGatewayPassword: "conduktor",
+		Catalog:            client.CatalogOptions{Offline: true},
+	})
+	assert.NoError(t, err)
+	console.GetCatalog().Kind["Plugin"] = schema.NewKind(1, &schema.ConsoleKindVersion{Name: "Plugin", ListPath: "/public/v1/plugin"})
+	gateway.GetCatalog().Kind["Plugin"] = schema.NewKind(2, &schema.GatewayKindVersion{Name: "Plugin", ListPath: "/gateway/v2/plugin"})
+	catalog := gateway.GetCatalog().Merge(console.GetCatalog())
+
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "plugins.yaml"), []byte(
+		"apiVersion: v1\nkind: Plugin\nmetadata:\n  name: p1\nspec: {}\n---\n"+
+			"apiVersion: gateway/v2\nkind: Plugin\nmetadata:\n  name: p2\nspec: {}\n"), 0644))
+	debug := false
+	rootCtx := NewRootContext(console, nil, gateway, nil, catalog, true, &debug)
+
+	results, err := NewApplyHandler(rootCtx).Handle(ApplyHandlerContext{FilePaths: []string{dir}, MaxParallel: 1})
+
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	for _, result := range results {
+		assert.NoError(t, result.Err)
+	}
+	assert.Equal(t, []string{`PUT /api/public/v1/plugin {"apiVersion":"v1","kind":"Plugin","metadata":{"name":"p1"},"spec":{}}`}, received["console"])
+	assert.Equal(t, []string{`PUT /gateway/v2/plugin {"apiVersion":"gateway/v2","kind":"Plugin","metadata":{"name":"p2"},"spec":{}}`}, received["gateway"])
+}
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/conduktor/ctl/pkg/client"
+)
+
+// CatalogStatus describes the catalog of a backend, Console or Gateway.
+type CatalogStatus struct {
+	Backend string
+	Info    client.CatalogInfo
+	// ClientError tells why the client of the backend could not be created, the embedded catalog being used
+	ClientError error
+}
+
+// CatalogStatuses tells where the catalogs of the Console and the Gateway in use come from.
+func CatalogStatuses(rootCtx RootContext) []CatalogStatus {
+	consoleClient, consoleErr := rootCtx.consoleClient()
+	console := CatalogStatus{Backend: "Console", Info: client.CatalogInfo{Source: client.CatalogEmbedded}, ClientError: consoleErr}
+	if consoleErr == nil {
+		console.Info = consoleClient.CatalogInfo()
+	}
+	gatewayClient, gatewayErr := rootCtx.gatewayClient()
+	gateway := CatalogStatus{Backend: "Gateway", Info: client.CatalogInfo{Source: client.CatalogEmbedded}, ClientError: gatewayErr}
+	if gatewayErr == nil {
+		gateway.Info = gatewayClient.CatalogInfo()
+	}
+	return []CatalogStatus{console, gateway}
+}
+
+// RefreshCatalogs downloads the catalogs of the configured backends and replaces the cached ones.
+// Backends that are not configured are skipped, their status telling why.
+func RefreshCatalogs(rootCtx RootContext) ([]CatalogStatus, []error) {
+	var errors []error
+	statuses := CatalogStatuses(rootCtx)
+	for i := range statuses {
+		var err error
+		switch {
+		case statuses[i].ClientError != nil:
+			continue
+		case statuses[i].Info.Offline:
+			err = fmt.Errorf("cannot refresh the catalog of the %s offline", statuses[i].Backend)
+		case statuses[i].Backend == "Console":
+			statuses[i].Info, err = rootCtx.ConsoleAPIClient().RefreshCatalog()
+		default:
+			statuses[i].Info, err = rootCtx.GatewayAPIClient().RefreshCatalog()
+		}
+		if err != nil {
+			errors = append(errors, err)
+		}
+	}
+	return statuses, errors
+}
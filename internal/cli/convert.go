@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/conduktor/ctl/pkg/resource"
+	"github.com/conduktor/ctl/pkg/schema"
+)
+
+// ConvertResources converts the resources to the version of apiVersion, e.g. v3, with the conversions declared
+// in the catalog. The resources whose kind has no such version are left as is.
+func ConvertResources(catalog schema.Catalog, resources []resource.Resource, apiVersion string) ([]resource.Resource, error) {
+	version, err := schema.ParseAPIVersion(apiVersion)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]resource.Resource, 0, len(resources))
+	for _, res := range resources {
+		kind, err := catalog.LookupKind(res.Kind, res.Version)
+		if err != nil {
+			return nil, fmt.Errorf("cannot convert %s/%s: %s", res.Kind, res.Name, err)
+		}
+		if _, ok := kind.Versions[version]; !ok {
+			fmt.Fprintf(os.Stderr, "Leaving %s/%s as is, kind %s has no version v%d\n", res.Kind, res.Name, res.Kind, version)
+			result = append(result, res)
+			continue
+		}
+		converted, err := kind.Convert(res, version)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, converted)
+	}
+	return result, nil
+}
+
+// WarnDeprecatedVersions reports once per kind and version the resources using a deprecated version of their kind.
+func WarnDeprecatedVersions(catalog schema.Catalog, resources []resource.Resource) {
+	warned := map[string]bool{}
+	for _, res := range resources {
+		kind, err := catalog.LookupKind(res.Kind, res.Version)
+		if err != nil {
+			continue
+		}
+		version, err := schema.ParseAPIVersion(res.Version)
+		if err != nil {
+			continue
+		}
+		deprecation := kind.Deprecation(version)
+		if deprecation == "" || warned[res.Kind+"/"+res.Version] {
+			continue
+		}
+		warned[res.Kind+"/"+res.Version] = true
+		if kind.CanConvert(version, kind.MaxVersion()) {
+			fmt.Fprintf(os.Stderr, "Warning: apiVersion %s of kind %s is deprecated: %s. Run conduktor convert to upgrade the files\n", res.Version, res.Kind, deprecation)
+		} else {
+			fmt.Fprintf(os.Stderr, "Warning: apiVersion %s of kind %s is deprecated: %s\n", res.Version, res.Kind, deprecation)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/conduktor/ctl/pkg/resource"
+	"github.com/conduktor/ctl/pkg/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertResources(t *testing.T) {
+	resources, err := resource.FromYamlByte([]byte(`
+apiVersion: gateway/v1
+kind: Plugin
+metadata:
+  name: retention
+spec:
+  retentionMs: 60000
+---
+apiVersion: v2
+kind: Topic
+metadata:
+  name: orders
+  cluster: prod
+spec:
+  partitions: 3
+`), true)
+	require.NoError(t, err)
+	catalog := *schema.ConsoleDefaultCatalog()
+	catalog.Kind["Plugin"] = schema.Kind{Versions: map[int]schema.KindVersion{
+		1: &schema.GatewayKindVersion{Name: "Plugin"},
+		2: &schema.GatewayKindVersion{Name: "Plugin", Conversions: []schema.Conversion{{
+			From:   1,
+			Rename: map[string]string{"spec.retentionMs": "spec.config.retentionMs"},
+		}}},
+	}}
+
+	converted, err := ConvertResources(catalog, resources, "v2")
+	require.NoError(t, err)
+	require.Len(t, converted, 2)
+	assert.Equal(t, "gateway/v2", converted[0].Version)
+	assert.Equal(t, map[string]interface{}{"config": map[string]interface{}{"retentionMs": float64(60000)}}, converted[0].Spec)
+	assert.Equal(t, resources[1], converted[1], "a resource already at the version is left as is")
+
+	_, err = ConvertResources(catalog, resources, "latest")
+	assert.Error(t, err)
+
+	alerts, err := resource.FromYamlByte([]byte("apiVersion: v2\nkind: Alert\nmetadata:\n  name: failed-tasks\n  cluster: prod\n"), true)
+	require.NoError(t, err)
+	_, err = ConvertResources(catalog, alerts, "v3")
+	assert.ErrorContains(t, err, "no conversion of kind Alert from v2 to v3")
+}
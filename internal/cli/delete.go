@@ -17,6 +17,10 @@ type DeleteFileHandlerContext struct {
 	DryRun          bool
 	StateEnabled    bool
 	StateRef        *model.State
+	// TemplateValues enable the rendering of the files as templates when not nil
+	TemplateValues map[string]interface{}
+	// FileFilter selects the files loaded from folders
+	FileFilter resource.FileFilter
 }
 
 type DeleteKindHandlerContext struct {
@@ -27,6 +31,8 @@ type DeleteKindHandlerContext struct {
 	DryRun               bool
 	StateEnabled         bool
 	StateRef             *model.State
+	// GatewayInstance is the named Gateway instance deleting a Gateway resource, the default Gateway if empty
+	GatewayInstance string
 }
 
 type DeleteByVClusterAndNameHandlerContext struct {
@@ -36,6 +42,8 @@ type DeleteByVClusterAndNameHandlerContext struct {
 	DryRun        bool
 	StateEnabled  bool
 	StateRef      *model.State
+	// GatewayInstance is the named Gateway instance deleting the resource, the default Gateway if empty
+	GatewayInstance string
 }
 
 type DeleteInterceptorHandlerContext struct {
@@ -47,6 +55,8 @@ type DeleteInterceptorHandlerContext struct {
 	DryRun        bool
 	StateEnabled  bool
 	StateRef      *model.State
+	// GatewayInstance is the named Gateway instance deleting the interceptor, the default Gateway if empty
+	GatewayInstance string
 }
 
 type DeleteResult struct {
@@ -71,7 +81,7 @@ func (h *DeleteHandler) HandleFromFiles(cmdCtx DeleteFileHandlerContext) ([]Dele
 	stateRef := cmdCtx.StateRef
 
 	// Load resources from files
-	resources, err := LoadResourcesFromFiles(cmdCtx.FilePaths, h.rootCtx.Strict, cmdCtx.RecursiveFolder)
+	resources, err := LoadResourcesFromFiles(cmdCtx.FilePaths, h.rootCtx.Loader(cmdCtx.RecursiveFolder, cmdCtx.TemplateValues, cmdCtx.FileFilter))
 	if err != nil {
 		return nil, err
 	}
@@ -80,6 +90,8 @@ func (h *DeleteHandler) HandleFromFiles(cmdCtx DeleteFileHandlerContext) ([]Dele
 }
 
 func (h *DeleteHandler) HandleFromList(resources []resource.Resource, stateRef *model.State, ignoreMissing, dryRun, debug bool) ([]DeleteResult, error) {
+	// the kinds of the resources can be missing from the catalog the commands were registered with
+	h.rootCtx.LoadCatalogs(resources...)
 	// Sort resources for proper delete order
 	schema.SortResourcesForDelete(h.rootCtx.Catalog.Kind, resources, *h.rootCtx.Debug)
 
@@ -92,26 +104,28 @@ func (h *DeleteHandler) HandleFromList(resources []resource.Resource, stateRef *
 			fmt.Printf("%s/%s: Deleted (dry-run)\n", res.Kind, res.Name)
 		} else {
 			if h.rootCtx.Catalog.IsGatewayResource(res) {
-				if h.rootCtx.gatewayAPIClientError != nil && h.rootCtx.gatewayAPIClient == nil {
+				gatewayClient, clientErr := h.rootCtx.gatewayInstanceClient(res.GatewayInstance())
+				if clientErr != nil {
 					// fail early if client is not initialized
-					return results, fmt.Errorf("cannot delete Gateway API resource %s/%s: %s", res.Kind, res.Name, h.rootCtx.gatewayAPIClientError)
+					return results, fmt.Errorf("cannot delete Gateway API resource %s/%s: %s", res.Kind, res.Name, clientErr)
 				}
-				gatewayClient := h.rootCtx.gatewayAPIClient
 
+				gatewayResource := res.WithGatewayInstance("")
 				if isResourceIdentifiedByName(res) {
-					err = gatewayClient.DeleteResourceByName(&res, ignoreMissing)
+					err = gatewayClient.DeleteResourceByName(&gatewayResource, ignoreMissing)
 				} else if isResourceIdentifiedByNameAndVCluster(res) {
-					err = gatewayClient.DeleteResourceByNameAndVCluster(&res, ignoreMissing)
+					err = gatewayClient.DeleteResourceByNameAndVCluster(&gatewayResource, ignoreMissing)
 				} else if isResourceInterceptor(res) {
-					err = gatewayClient.DeleteResourceInterceptors(&res, ignoreMissing)
+					err = gatewayClient.DeleteResourceInterceptors(&gatewayResource, ignoreMissing)
 				}
 			} else {
-				if h.rootCtx.consoleAPIClientError != nil && h.rootCtx.consoleAPIClient == nil {
+				consoleClient, clientErr := h.rootCtx.consoleClient()
+				if clientErr != nil {
 					// fail early if client is not initialized
-					return results, fmt.Errorf("cannot delete Console API resource %s/%s: %s", res.Kind, res.Name, h.rootCtx.consoleAPIClientError)
+					return results, fmt.Errorf("cannot delete Console API resource %s/%s: %s", res.Kind, res.Name, clientErr)
 				}
 
-				err = h.rootCtx.consoleAPIClient.DeleteResource(&res, ignoreMissing)
+				err = consoleClient.DeleteResource(&res, ignoreMissing)
 			}
 
 			// Remove successful deletions from state
@@ -149,17 +163,19 @@ func (h *DeleteHandler) HandleKind(kind schema.Kind, cmdCtx DeleteKindHandlerCon
 	} else {
 		var err error
 		if kind.IsGatewayKind() {
-			if h.rootCtx.gatewayAPIClientError != nil && h.rootCtx.gatewayAPIClient == nil {
+			gatewayClient, clientErr := h.rootCtx.gatewayInstanceClient(cmdCtx.GatewayInstance)
+			if clientErr != nil {
 				// fail early if client is not initialized
-				return fmt.Errorf("cannot delete Gateway API resource of kind %s: %s", kind.GetName(), h.rootCtx.gatewayAPIClientError)
+				return fmt.Errorf("cannot delete Gateway API resource of kind %s: %s", kind.GetName(), clientErr)
 			}
-			err = h.rootCtx.gatewayAPIClient.Delete(&kind, parentValue, parentQueryValue, name)
+			err = gatewayClient.Delete(&kind, parentValue, parentQueryValue, name)
 		} else {
-			if h.rootCtx.consoleAPIClientError != nil && h.rootCtx.consoleAPIClient == nil {
+			consoleClient, clientErr := h.rootCtx.consoleClient()
+			if clientErr != nil {
 				// fail early if client is not initialized
-				return fmt.Errorf("cannot delete Console API resource of kind %s: %s", kind.GetName(), h.rootCtx.consoleAPIClientError)
+				return fmt.Errorf("cannot delete Console API resource of kind %s: %s", kind.GetName(), clientErr)
 			}
-			err = h.rootCtx.consoleAPIClient.Delete(&kind, parentValue, parentQueryValue, name, cmdCtx.IgnoreMissing)
+			err = consoleClient.Delete(&kind, parentValue, parentQueryValue, name, cmdCtx.IgnoreMissing)
 		}
 
 		// Remove successful deletions from state
@@ -185,12 +201,13 @@ func (h *DeleteHandler) HandleByVClusterAndName(kind schema.Kind, cmdCtx DeleteB
 		fmt.Printf("%s/%s: Deleted (dry-run)\n", kind.GetName(), cmdCtx.Name)
 		return nil
 	} else {
-		if h.rootCtx.gatewayAPIClientError != nil && h.rootCtx.gatewayAPIClient == nil {
+		gatewayClient, err := h.rootCtx.gatewayInstanceClient(cmdCtx.GatewayInstance)
+		if err != nil {
 			// fail early if client is not initialized
-			return fmt.Errorf("cannot delete Gateway API resource of kind %s: %s", kind.GetName(), h.rootCtx.gatewayAPIClientError)
+			return fmt.Errorf("cannot delete Gateway API resource of kind %s: %s", kind.GetName(), err)
 		}
 
-		err := h.rootCtx.gatewayAPIClient.DeleteKindByNameAndVCluster(&kind, bodyParams, cmdCtx.IgnoreMissing)
+		err = gatewayClient.DeleteKindByNameAndVCluster(&kind, bodyParams, cmdCtx.IgnoreMissing)
 
 		// Remove successful deletions from state
 		if err == nil && cmdCtx.StateRef != nil {
@@ -225,12 +242,13 @@ func (h *DeleteHandler) HandleInterceptor(kind schema.Kind, cmdCtx DeleteInterce
 		fmt.Printf("%s/%s: Deleted (dry-run)\n", kind.GetName(), cmdCtx.Name)
 		return nil
 	} else {
-		if h.rootCtx.gatewayAPIClientError != nil && h.rootCtx.gatewayAPIClient == nil {
+		gatewayClient, err := h.rootCtx.gatewayInstanceClient(cmdCtx.GatewayInstance)
+		if err != nil {
 			// fail early if client is not initialized
-			return fmt.Errorf("cannot delete Gateway API resource of kind %s: %s", kind.GetName(), h.rootCtx.gatewayAPIClientError)
+			return fmt.Errorf("cannot delete Gateway API resource of kind %s: %s", kind.GetName(), err)
 		}
 
-		err := h.rootCtx.gatewayAPIClient.DeleteInterceptor(&kind, cmdCtx.Name, bodyParams, cmdCtx.IgnoreMissing)
+		err = gatewayClient.DeleteInterceptor(&kind, cmdCtx.Name, bodyParams, cmdCtx.IgnoreMissing)
 
 		// Remove successful deletions from state
 		if err == nil && cmdCtx.StateRef != nil {
@@ -1,8 +1,14 @@
 package cli
 
 import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/conduktor/ctl/pkg/client"
 	"github.com/conduktor/ctl/pkg/resource"
 	"github.com/conduktor/ctl/pkg/schema"
 	"github.com/stretchr/testify/assert"
@@ -49,3 +55,65 @@ func TestDeleteHandler_isResourceInterceptor(t *testing.T) {
 	assert.True(t, isResourceInterceptor(resource2))
 	assert.False(t, isResourceInterceptor(resource3))
 }
+
+func TestDeleteHandler_RoutesTheGatewayResourcesToTheirInstance(t *testing.T) {
+	var deleted []string
+	euServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deleted = append(deleted, r.Method+" "+r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer euServer.Close()
+	debug := false
+	rootCtx := NewRootContext(nil, fmt.Errorf("Please set CDK_BASE_URL"), nil, fmt.Errorf("Please set CDK_GATEWAY_BASE_URL"), schema.GatewayDefaultCatalog().Merge(schema.ConsoleDefaultCatalog()), true, &debug).
+		WithGatewayInstances([]string{"eu"}, func(name string) (*client.GatewayClient, error) {
+			return client.MakeGateway(client.GatewayAPIParameter{
+				BaseURL:            euServer.URL,
+				CdkGatewayUser:     "admin",
+				CdkGatewayPassword: "conduktor",
+				Catalog:            client.CatalogOptions{Offline: true},
+			})
+		})
+	resources, err := resource.FromYamlByte([]byte("apiVersion: gateway/v2\nkind: VirtualCluster\nmetadata:\n  name: vc1\n  gateway: eu\n"), true)
+	assert.NoError(t, err)
+
+	results, err := NewDeleteHandler(rootCtx).HandleFromList(resources, nil, false, false, false)
+
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, []string{"DELETE /gateway/v2/virtual-cluster/vc1"}, deleted)
+}
+
+func TestDeleteHandler_DeletesAKindFromItsGatewayInstance(t *testing.T) {
+	var deleted []string
+	euServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		deleted = append(deleted, strings.TrimSpace(r.Method+" "+r.URL.Path+" "+string(body)))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer euServer.Close()
+	debug := false
+	catalog := schema.GatewayDefaultCatalog().Merge(schema.ConsoleDefaultCatalog())
+	rootCtx := NewRootContext(nil, fmt.Errorf("Please set CDK_BASE_URL"), nil, fmt.Errorf("Please set CDK_GATEWAY_BASE_URL"), catalog, true, &debug).
+		WithGatewayInstances([]string{"eu"}, func(name string) (*client.GatewayClient, error) {
+			return client.MakeGateway(client.GatewayAPIParameter{
+				BaseURL:            euServer.URL,
+				CdkGatewayUser:     "admin",
+				CdkGatewayPassword: "conduktor",
+				Catalog:            client.CatalogOptions{Offline: true},
+			})
+		})
+	handler := NewDeleteHandler(rootCtx)
+
+	assert.NoError(t, handler.HandleKind(catalog.Kind["VirtualCluster"], DeleteKindHandlerContext{Args: []string{"vc1"}, GatewayInstance: "eu"}))
+	assert.NoError(t, handler.HandleByVClusterAndName(catalog.Kind["AliasTopic"], DeleteByVClusterAndNameHandlerContext{Name: "alias", VCluster: "passthrough", GatewayInstance: "eu"}))
+	assert.NoError(t, handler.HandleInterceptor(catalog.Kind["Interceptor"], DeleteInterceptorHandlerContext{Name: "masking", GatewayInstance: "eu"}))
+	assert.EqualError(t, handler.HandleKind(catalog.Kind["VirtualCluster"], DeleteKindHandlerContext{Args: []string{"vc1"}}),
+		"cannot delete Gateway API resource of kind VirtualCluster: Please set CDK_GATEWAY_BASE_URL")
+
+	assert.Equal(t, []string{
+		"DELETE /gateway/v2/virtual-cluster/vc1",
+		`DELETE /gateway/v2/alias-topic {"name":"alias","vCluster":"passthrough"}`,
+		"DELETE /gateway/v2/interceptor/masking {}",
+	}, deleted)
+}
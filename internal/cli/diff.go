@@ -0,0 +1,371 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/conduktor/ctl/internal/state"
+	"github.com/conduktor/ctl/internal/state/model"
+	"github.com/conduktor/ctl/internal/state/storage"
+	"github.com/conduktor/ctl/internal/utils"
+	"github.com/conduktor/ctl/pkg/client"
+	"github.com/conduktor/ctl/pkg/resource"
+	"github.com/conduktor/ctl/pkg/schema"
+)
+
+type DiffSourceType string
+
+const (
+	DiffSourceFile    DiffSourceType = "file"
+	DiffSourceLive    DiffSourceType = "live"
+	DiffSourceContext DiffSourceType = "context"
+	DiffSourceState   DiffSourceType = "state"
+)
+
+type DiffSource struct {
+	Type DiffSourceType
+	// Location is the file or folder path, the env file of a context, or the state file path or remote URI
+	Location string
+	// Label names the source in the output
+	Label string
+}
+
+func (s DiffSource) isRemote() bool {
+	return s.Type == DiffSourceLive || s.Type == DiffSourceContext
+}
+
+// ParseDiffSource parses one side of a diff:
+//   - live: the Console and Gateway configured in the current environment
+//   - context:<name>: the environment described by the env file <config dir>/contexts/<name>.env
+//   - env:<path>: the environment described by the env file at path
+//   - state or state:<path|uri>: the resources recorded in a state file, CDK_STATE_FILE or CDK_STATE_REMOTE_URI if omitted
+//   - file:<path> or <path>: the resources of a local file or folder
+func ParseDiffSource(spec string) (DiffSource, error) {
+	sourceType, location, hasLocation := strings.Cut(spec, ":")
+	switch {
+	case spec == "":
+		return DiffSource{}, fmt.Errorf("empty diff source")
+	case spec == "live":
+		return DiffSource{Type: DiffSourceLive, Label: spec}, nil
+	case spec == "state":
+		return DiffSource{Type: DiffSourceState, Label: spec}, nil
+	case hasLocation && sourceType == "state":
+		return DiffSource{Type: DiffSourceState, Location: location, Label: spec}, nil
+	case hasLocation && sourceType == "env":
+		return DiffSource{Type: DiffSourceContext, Location: location, Label: spec}, nil
+	case hasLocation && sourceType == "context":
+		configDir, err := utils.GetConfigDir()
+		if err != nil {
+			return DiffSource{}, err
+		}
+		return DiffSource{Type: DiffSourceContext, Location: filepath.Join(configDir, "contexts", location+".env"), Label: spec}, nil
+	case hasLocation && sourceType == "file":
+		return DiffSource{Type: DiffSourceFile, Location: location, Label: spec}, nil
+	default:
+		return DiffSource{Type: DiffSourceFile, Location: spec, Label: spec}, nil
+	}
+}
+
+type DiffChange string
+
+const (
+	// DiffAdded resource only exists in the target
+	DiffAdded DiffChange = "ADDED"
+	// DiffRemoved resource only exists in the source
+	DiffRemoved DiffChange = "REMOVED"
+	// DiffModified resource exists on both sides with a different content
+	DiffModified DiffChange = "MODIFIED"
+)
+
+type ResourceDiff struct {
+	Change   DiffChange         `json:"change"`
+	Kind     string             `json:"kind"`
+	Name     string             `json:"name"`
+	Identity string             `json:"identity"`
+	Source   *resource.Resource `json:"source,omitempty"`
+	Target   *resource.Resource `json:"target,omitempty"`
+	// Fields lists the changed fields of a modified resource
+	Fields []utils.FieldChange `json:"fields,omitempty"`
+}
+
+type DiffHandlerContext struct {
+	Source DiffSource
+	Target DiffSource
+	// KindName restricts the diff to a kind, remote sides are then listed instead of looked up resource by resource
+	KindName string
+	// ParentValues restricts the diff to the resources with these metadata values, e.g. cluster=prod
+	ParentValues    map[string]string
+	RecursiveFolder bool
+	// TemplateValues enable the rendering of file sources as templates when not nil
+	TemplateValues map[string]interface{}
+	// FileFilter selects the files loaded from folders
+	FileFilter resource.FileFilter
+}
+
+// DiffHandler loads both sides and compares them resource by resource.
+// Remote sides (live or context) are scoped by KindName if set, by the resources of the other side if it is local,
+// or to every listable resource otherwise.
+// When a side is a state file, which only records resource identities, only apiVersion, kind and metadata are compared.
+func DiffHandler(rootCtx RootContext, cmdCtx DiffHandlerContext) ([]ResourceDiff, error) {
+	if cmdCtx.Source.isRemote() || cmdCtx.Target.isRemote() {
+		rootCtx.LoadCatalogs()
+	}
+	var kind *schema.Kind
+	if cmdCtx.KindName != "" {
+		k, err := rootCtx.Catalog.LookupKind(cmdCtx.KindName, "")
+		if err != nil {
+			return nil, err
+		}
+		kind = &k
+	}
+
+	loaded := make(map[DiffSource][]resource.Resource, 2)
+	var references []resource.Resource
+	for _, src := range []DiffSource{cmdCtx.Source, cmdCtx.Target} {
+		if src.isRemote() {
+			continue
+		}
+		resources, err := loadLocalDiffSource(src, rootCtx, cmdCtx)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load %s: %s", src.Label, err)
+		}
+		resources = filterDiffScope(resources, kind, cmdCtx.ParentValues)
+		loaded[src] = resources
+		references = append(references, resources...)
+	}
+	for _, src := range []DiffSource{cmdCtx.Source, cmdCtx.Target} {
+		if !src.isRemote() {
+			continue
+		}
+		remoteCtx := rootCtx
+		if src.Type == DiffSourceContext {
+			var err error
+			remoteCtx, err = NewRootContextFromEnvFile(src.Location, rootCtx)
+			if err != nil {
+				return nil, fmt.Errorf("cannot load %s: %s", src.Label, err)
+			}
+		}
+		resources, err := loadRemoteDiffSource(remoteCtx, kind, cmdCtx, references, len(loaded) == 0)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load %s: %s", src.Label, err)
+		}
+		loaded[src] = resources
+	}
+
+	source, target := loaded[cmdCtx.Source], loaded[cmdCtx.Target]
+	if cmdCtx.Source.Type == DiffSourceState || cmdCtx.Target.Type == DiffSourceState {
+		source, target = identitiesOnly(source), identitiesOnly(target)
+	}
+	return CompareResources(rootCtx.Catalog, source, target)
+}
+
+// NewRootContextFromEnvFile builds a RootContext for the Console and Gateway described by the CDK_* variables of an env file.
+// The catalog of base is kept. The current environment is never used: the credentials are the ones of the env file,
+// CDK_AUTH_EXEC or the session cached by conduktor login for its base URL, missing ones being reported by the clients.
+func NewRootContextFromEnvFile(path string, base RootContext) (RootContext, error) {
+	env, err := utils.ReadEnvFile(path)
+	if err != nil {
+		return RootContext{}, err
+	}
+	if env["CDK_BASE_URL"] == "" && env["CDK_GATEWAY_BASE_URL"] == "" {
+		return RootContext{}, fmt.Errorf("%s sets neither CDK_BASE_URL nor CDK_GATEWAY_BASE_URL", path)
+	}
+	getenv := func(key string) string { return env[key] }
+	consoleClient, consoleErr := client.MakeFromEnvLookup(getenv)
+	gatewayClient, gatewayErr := client.MakeGatewayClientFromEnvLookup(getenv)
+	return NewRootContext(consoleClient, consoleErr, gatewayClient, gatewayErr, base.Catalog, base.Strict, base.Debug), nil
+}
+
+func loadLocalDiffSource(src DiffSource, rootCtx RootContext, cmdCtx DiffHandlerContext) ([]resource.Resource, error) {
+	if src.Type == DiffSourceFile {
+		return ResourceForPath(src.Location, rootCtx.Loader(cmdCtx.RecursiveFolder, cmdCtx.TemplateValues, cmdCtx.FileFilter))
+	}
+
+	enabled := true
+	stateCfg := storage.NewStorageConfig(&enabled, nil, nil)
+	if strings.Contains(src.Location, "://") {
+		stateCfg.RemoteURI = &src.Location
+	} else if src.Location != "" {
+		stateCfg.FilePath = &src.Location
+		stateCfg.RemoteURI = nil
+	}
+	var resources []resource.Resource
+	// dry run, the state is only read
+	err := state.RunWithState(stateCfg, true, *rootCtx.Debug, func(stateRef *model.State) error {
+		for _, resState := range stateRef.Resources {
+			resources = append(resources, resState.ToResource())
+		}
+		return nil
+	})
+	return resources, err
+}
+
+func loadRemoteDiffSource(rootCtx RootContext, kind *schema.Kind, cmdCtx DiffHandlerContext, references []resource.Resource, noLocalSide bool) ([]resource.Resource, error) {
+	if kind != nil {
+		getCtx := GetKindHandlerContext{}
+		for _, flag := range kind.GetParentFlag() {
+			value, ok := cmdCtx.ParentValues[flag]
+			if !ok {
+				return nil, fmt.Errorf("parent %s is required to list kind %s", flag, kind.GetName())
+			}
+			getCtx.ParentFlagValue = append(getCtx.ParentFlagValue, &value)
+		}
+		for _, flag := range kind.GetParentQueryFlag() {
+			value := cmdCtx.ParentValues[flag]
+			getCtx.ParentQueryFlagValue = append(getCtx.ParentQueryFlagValue, &value)
+		}
+		resources, errs := GetKindHandler(*kind, rootCtx, getCtx)
+		return resources, errors.Join(errs...)
+	}
+
+	if noLocalSide {
+		onlyGateway, onlyConsole := false, false
+		resources, errs := GetAllsHandler(rootCtx, GetAllHandlerContext{OnlyGateway: &onlyGateway, OnlyConsole: &onlyConsole})
+		return filterDiffScope(resources, nil, cmdCtx.ParentValues), errors.Join(errs...)
+	}
+
+	var resources []resource.Resource
+	seen := make(map[string]bool)
+	for _, ref := range references {
+		id := diffIdentity(rootCtx.Catalog, ref)
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		var current resource.Resource
+		var err error
+		if rootCtx.Catalog.IsGatewayResource(ref) {
+			gatewayClient, clientErr := rootCtx.gatewayInstanceClient(ref.GatewayInstance())
+			if clientErr != nil {
+				return nil, fmt.Errorf("cannot fetch %s/%s: %s", ref.Kind, ref.Name, clientErr)
+			}
+			gatewayRef := ref.WithGatewayInstance("")
+			current, err = gatewayClient.GetFromResource(&gatewayRef)
+			current = current.WithGatewayInstance(ref.GatewayInstance())
+		} else {
+			consoleClient, clientErr := rootCtx.consoleClient()
+			if clientErr != nil {
+				return nil, fmt.Errorf("cannot fetch %s/%s: %s", ref.Kind, ref.Name, clientErr)
+			}
+			current, err = consoleClient.GetFromResource(&ref)
+		}
+		if errors.Is(err, client.ErrResourceNotFound) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("cannot fetch %s/%s: %s", ref.Kind, ref.Name, err)
+		}
+		resources = append(resources, current)
+	}
+	return resources, nil
+}
+
+func filterDiffScope(resources []resource.Resource, kind *schema.Kind, parentValues map[string]string) []resource.Resource {
+	var result []resource.Resource
+	for _, res := range resources {
+		if kind != nil && res.Kind != kind.GetName() {
+			continue
+		}
+		inScope := true
+		for key, value := range parentValues {
+			if metadataValue, ok := res.Metadata[key]; ok && fmt.Sprintf("%v", metadataValue) != value {
+				inScope = false
+				break
+			}
+		}
+		if inScope {
+			result = append(result, res)
+		}
+	}
+	return result
+}
+
+// identitiesOnly keeps the apiVersion, kind and metadata of the resources, as recorded in a state file.
+func identitiesOnly(resources []resource.Resource) []resource.Resource {
+	result := make([]resource.Resource, len(resources))
+	for i, res := range resources {
+		res.Spec = nil
+		res.Json, _ = json.Marshal(map[string]interface{}{
+			"apiVersion": res.Version,
+			"kind":       res.Kind,
+			"metadata":   res.Metadata,
+		})
+		result[i] = res
+	}
+	return result
+}
+
+func diffIdentity(catalog schema.Catalog, res resource.Resource) string {
+	kind, err := catalog.LookupKind(res.Kind, res.Version)
+	if err != nil {
+		return res.Kind + "/" + res.Name
+	}
+	return ResourceIdentity(kind, res)
+}
+
+// ArrayOrdering returns the array ordering rules declared in the catalog for a kind, nil if the kind is unknown.
+func ArrayOrdering(catalog schema.Catalog, kindName string) map[string]string {
+	kind, err := catalog.LookupKind(kindName, "")
+	if err != nil {
+		return nil
+	}
+	return kind.GetLatestKindVersion().GetArrayOrdering()
+}
+
+// CompareResources matches the resources of both sides by identity and returns the differences sorted by identity.
+func CompareResources(catalog schema.Catalog, source, target []resource.Resource) ([]ResourceDiff, error) {
+	sourceByID := make(map[string]resource.Resource, len(source))
+	for _, res := range source {
+		sourceByID[diffIdentity(catalog, res)] = res
+	}
+	targetByID := make(map[string]resource.Resource, len(target))
+	for _, res := range target {
+		targetByID[diffIdentity(catalog, res)] = res
+	}
+
+	var result []ResourceDiff
+	for id, src := range sourceByID {
+		tgt, exists := targetByID[id]
+		sensitiveFields := catalog.SensitiveFields(src.Kind)
+		if !exists {
+			masked, _ := utils.MaskSensitiveFieldsPair(&src, nil, sensitiveFields)
+			result = append(result, ResourceDiff{Change: DiffRemoved, Kind: src.Kind, Name: src.Name, Identity: id, Source: masked})
+			continue
+		}
+		ordering := ArrayOrdering(catalog, src.Kind)
+		srcYaml, err := utils.NormalizedYaml(&src, ordering)
+		if err != nil {
+			return nil, err
+		}
+		tgtYaml, err := utils.NormalizedYaml(&tgt, ordering)
+		if err != nil {
+			return nil, err
+		}
+		if srcYaml != tgtYaml {
+			// sensitive fields are compared before being masked for the output
+			maskedSrc, maskedTgt := utils.MaskSensitiveFieldsPair(&src, &tgt, sensitiveFields)
+			result = append(result, ResourceDiff{
+				Change:   DiffModified,
+				Kind:     src.Kind,
+				Name:     src.Name,
+				Identity: id,
+				Source:   maskedSrc,
+				Target:   maskedTgt,
+				Fields:   utils.DiffFields(maskedSrc, maskedTgt, ordering),
+			})
+		}
+	}
+	for id, tgt := range targetByID {
+		if _, exists := sourceByID[id]; !exists {
+			_, masked := utils.MaskSensitiveFieldsPair(nil, &tgt, catalog.SensitiveFields(tgt.Kind))
+			result = append(result, ResourceDiff{Change: DiffAdded, Kind: tgt.Kind, Name: tgt.Name, Identity: id, Target: masked})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Identity < result[j].Identity
+	})
+	return result, nil
+}
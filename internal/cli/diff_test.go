@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/conduktor/ctl/pkg/resource"
+	"github.com/conduktor/ctl/pkg/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDiffSource(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	tests := []struct {
+		spec     string
+		expected DiffSource
+	}{
+		{"live", DiffSource{Type: DiffSourceLive, Label: "live"}},
+		{"state", DiffSource{Type: DiffSourceState, Label: "state"}},
+		{"state:s3://bucket/state/", DiffSource{Type: DiffSourceState, Location: "s3://bucket/state/", Label: "state:s3://bucket/state/"}},
+		{"env:prod.env", DiffSource{Type: DiffSourceContext, Location: "prod.env", Label: "env:prod.env"}},
+		{"file:./live", DiffSource{Type: DiffSourceFile, Location: "./live", Label: "file:./live"}},
+		{"./resources", DiffSource{Type: DiffSourceFile, Location: "./resources", Label: "./resources"}},
+	}
+	for _, test := range tests {
+		t.Run(test.spec, func(t *testing.T) {
+			source, err := ParseDiffSource(test.spec)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, source)
+		})
+	}
+
+	source, err := ParseDiffSource("context:prod")
+	require.NoError(t, err)
+	assert.Equal(t, DiffSourceContext, source.Type)
+	assert.True(t, strings.HasSuffix(source.Location, filepath.Join("conduktor", "contexts", "prod.env")))
+
+	_, err = ParseDiffSource("")
+	assert.Error(t, err)
+}
+
+func TestCompareResources(t *testing.T) {
+	catalog := schema.Catalog{Kind: schema.KindCatalog{"Connector": connectorKind}}
+	source := []resource.Resource{
+		connector("a", "prod", "RUNNING"),
+		connector("b", "prod", "RUNNING"),
+		connector("c", "prod", "RUNNING"),
+	}
+	target := []resource.Resource{
+		connector("a", "prod", "RUNNING"),
+		connector("b", "prod", "PAUSED"),
+		connector("c", "staging", "RUNNING"),
+	}
+
+	diffs, err := CompareResources(catalog, source, target)
+	require.NoError(t, err)
+
+	var changes []string
+	for _, diff := range diffs {
+		changes = append(changes, string(diff.Change)+" "+diff.Identity)
+	}
+	assert.Equal(t, []string{
+		"MODIFIED Connector/cluster=prod/connectCluster=connect/b",
+		"REMOVED Connector/cluster=prod/connectCluster=connect/c",
+		"ADDED Connector/cluster=staging/connectCluster=connect/c",
+	}, changes)
+
+	diffs, err = CompareResources(catalog, source, source)
+	require.NoError(t, err)
+	assert.Empty(t, diffs)
+}
+
+func TestDiffHandler_StateAndFiles(t *testing.T) {
+	dir := t.TempDir()
+	statePath := filepath.Join(dir, "state.json")
+	require.NoError(t, os.WriteFile(statePath, []byte(`{"version":"v1","lastUpdated":"2024-01-01T00:00:00Z","resources":[
+		{"apiVersion":"v2","kind":"Connector","metadata":{"name":"a","cluster":"prod","connectCluster":"connect"}},
+		{"apiVersion":"v2","kind":"Connector","metadata":{"name":"old","cluster":"prod","connectCluster":"connect"}}
+	]}`), 0600))
+	filesPath := filepath.Join(dir, "resources.yaml")
+	require.NoError(t, os.WriteFile(filesPath, []byte(`apiVersion: v2
+kind: Connector
+metadata:
+  name: a
+  cluster: prod
+  connectCluster: connect
+spec:
+  state: RUNNING
+`), 0600))
+
+	debug := false
+	rootCtx := RootContext{
+		Catalog: schema.Catalog{Kind: schema.KindCatalog{"Connector": connectorKind}},
+		Strict:  true,
+		Debug:   &debug,
+	}
+	diffs, err := DiffHandler(rootCtx, DiffHandlerContext{
+		Source: DiffSource{Type: DiffSourceState, Location: statePath, Label: "state"},
+		Target: DiffSource{Type: DiffSourceFile, Location: filesPath, Label: "files"},
+	})
+	require.NoError(t, err)
+
+	// the spec is not recorded in the state so connector a is identical
+	require.Len(t, diffs, 1)
+	assert.Equal(t, DiffRemoved, diffs[0].Change)
+	assert.Equal(t, "old", diffs[0].Name)
+}
+
+func TestCompareResourcesShouldMaskSensitiveFields(t *testing.T) {
+	kafkaCluster := schema.NewKind(2, &schema.ConsoleKindVersion{Name: "KafkaCluster", SensitiveFields: []string{"spec.password"}})
+	catalog := schema.Catalog{Kind: schema.KindCatalog{"KafkaCluster": kafkaCluster}}
+	cluster := func(name, password string) resource.Resource {
+		return resource.Resource{Kind: "KafkaCluster", Name: name, Version: "v2",
+			Json: []byte(`{"apiVersion":"v2","kind":"KafkaCluster","metadata":{"name":"` + name + `"},"spec":{"password":"` + password + `"}}`)}
+	}
+
+	diffs, err := CompareResources(catalog, []resource.Resource{cluster("prod", "old-secret"), cluster("dev", "dev-secret")}, []resource.Resource{cluster("prod", "new-secret")})
+	require.NoError(t, err)
+	require.Len(t, diffs, 2)
+	assert.Equal(t, DiffRemoved, diffs[0].Change)
+	assert.JSONEq(t, `{"apiVersion":"v2","kind":"KafkaCluster","metadata":{"name":"dev"},"spec":{"password":"***"}}`, string(diffs[0].Source.Json))
+	assert.Equal(t, DiffModified, diffs[1].Change)
+	require.Len(t, diffs[1].Fields, 1)
+	assert.Equal(t, "*** (before)", diffs[1].Fields[0].Old)
+	assert.Equal(t, "*** (after)", diffs[1].Fields[0].New)
+}
+
+func TestNewRootContextFromEnvFileShouldAcceptExecCredentials(t *testing.T) {
+	dir := t.TempDir()
+	envFile := filepath.Join(dir, "prod.env")
+	require.NoError(t, os.WriteFile(envFile, []byte("CDK_BASE_URL=https://console.prod\nCDK_AUTH_EXEC=echo token\nCDK_OFFLINE=true\n"), 0644))
+	debug := false
+	base := RootContext{Catalog: *schema.ConsoleDefaultCatalog(), Debug: &debug}
+
+	rootCtx, err := NewRootContextFromEnvFile(envFile, base)
+
+	require.NoError(t, err)
+	_, err = rootCtx.consoleClient()
+	assert.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(envFile, []byte("CDK_API_KEY=key\n"), 0644))
+	_, err = NewRootContextFromEnvFile(envFile, base)
+	assert.EqualError(t, err, envFile+" sets neither CDK_BASE_URL nor CDK_GATEWAY_BASE_URL")
+}
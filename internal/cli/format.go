@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/conduktor/ctl/pkg/resource"
+	yaml "gopkg.in/yaml.v3"
+)
+
+type FormatHandlerContext struct {
+	FilePaths  []string
+	Recursive  bool
+	FileFilter resource.FileFilter
+	// Write rewrites the files that are not formatted
+	Write bool
+}
+
+type FormatResult struct {
+	// Path is the formatted file, resource.StdinSource for stdin
+	Path      string
+	Formatted []byte
+	Changed   bool
+	// Skipped is why the file was left as is, e.g. a Go template, empty if it was formatted
+	Skipped string
+}
+
+type FormatHandler struct {
+	rootCtx RootContext
+	// Stdin is read for resource.StdinSource, os.Stdin if nil
+	Stdin io.Reader
+}
+
+func NewFormatHandler(rootCtx RootContext) *FormatHandler {
+	return &FormatHandler{
+		rootCtx: rootCtx,
+	}
+}
+
+// Handle formats the YAML files of the given files and folders in the canonical layout, see resource.FormatYaml,
+// with the key order of the schemas of the catalog, see schema.Catalog.KeyOrder.
+// JSON files and Go templates are left as is and reported on stderr.
+func (h *FormatHandler) Handle(cmdCtx FormatHandlerContext) ([]FormatResult, error) {
+	loader := h.rootCtx.Loader(cmdCtx.Recursive, nil, cmdCtx.FileFilter)
+	var results []FormatResult
+	for _, path := range cmdCtx.FilePaths {
+		if path == resource.StdinSource {
+			result, err := h.formatStdin()
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, result)
+			continue
+		}
+		if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") || strings.HasPrefix(path, resource.GitSourcePrefix) {
+			return nil, fmt.Errorf("cannot format %s, only local files and folders can be formatted", path)
+		}
+		files := []string{path}
+		isDir, err := IsDirectory(path)
+		if err != nil {
+			return nil, err
+		}
+		if isDir {
+			files, err = loader.Files(path)
+			if err != nil {
+				return nil, err
+			}
+		}
+		for _, file := range files {
+			if ext := filepath.Ext(file); ext != ".yaml" && ext != ".yml" {
+				h.reportSkipped(file, "not a YAML file")
+				continue
+			}
+			result, err := h.formatFile(file, cmdCtx.Write)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+func (h *FormatHandler) formatStdin() (FormatResult, error) {
+	stdin := h.Stdin
+	if stdin == nil {
+		stdin = os.Stdin
+	}
+	data, err := io.ReadAll(stdin)
+	if err != nil {
+		return FormatResult{}, fmt.Errorf("failed to read stdin: %w", err)
+	}
+	return h.format(resource.StdinSource, data)
+}
+
+func (h *FormatHandler) formatFile(file string, write bool) (FormatResult, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return FormatResult{}, err
+	}
+	// a template is not YAML until rendered, formatting it could break its actions
+	if isGoTemplate(data) {
+		h.reportSkipped(file, "Go template")
+		return FormatResult{Path: file, Formatted: data, Skipped: "Go template"}, nil
+	}
+	result, err := h.format(file, data)
+	if err != nil || !write || !result.Changed {
+		return result, err
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return result, err
+	}
+	err = os.WriteFile(file, result.Formatted, info.Mode().Perm())
+	if err != nil {
+		return result, fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	return result, nil
+}
+
+func (h *FormatHandler) format(path string, data []byte) (FormatResult, error) {
+	formatted, err := resource.FormatYaml(data, h.rootCtx.Catalog.KeyOrder)
+	if err != nil {
+		return FormatResult{}, fmt.Errorf("failed to format %s: %w", path, err)
+	}
+	return FormatResult{Path: path, Formatted: formatted, Changed: !bytes.Equal(data, formatted)}, nil
+}
+
+func (h *FormatHandler) reportSkipped(path, reason string) {
+	fmt.Fprintf(os.Stderr, "Skipping %s: %s\n", path, reason)
+}
+
+// isGoTemplate returns true if the {{ }} actions of data are not plain YAML values, e.g. name: {{ .Values.name }}
+// or a {{ range }} line, which make the file invalid YAML or are read as nested flow mappings. {{ }} within a scalar,
+// like the message of an Alert, is kept as is by the formatting, and such a file is formatted.
+func isGoTemplate(data []byte) bool {
+	if !bytes.Contains(data, []byte("{{")) {
+		return false
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var document yaml.Node
+		err := decoder.Decode(&document)
+		if err == io.EOF {
+			return false
+		}
+		if err != nil || hasMappingKey(&document) {
+			return true
+		}
+	}
+}
+
+// hasMappingKey returns true if a mapping of node has a mapping as key, which is how YAML reads {{ .x }}.
+func hasMappingKey(node *yaml.Node) bool {
+	for i, child := range node.Content {
+		if node.Kind == yaml.MappingNode && i%2 == 0 && child.Kind == yaml.MappingNode {
+			return true
+		}
+		if hasMappingKey(child) {
+			return true
+		}
+	}
+	return false
+}
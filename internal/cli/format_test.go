@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/conduktor/ctl/pkg/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatHandler(t *testing.T) {
+	dir := t.TempDir()
+	unformatted := "kind: Topic\napiVersion: v2\nmetadata: {name: orders}\n"
+	formatted := "apiVersion: v2\nkind: Topic\nmetadata:\n  name: orders\n"
+	template := "kind: Topic\napiVersion: v2\nmetadata: {name: {{ .Values.name }}}\n"
+	rangeTemplate := "{{ range .Values.topics }}\n---\napiVersion: v2\nkind: Topic\nmetadata:\n  name: {{ . }}\n{{ end }}\n"
+	// {{ }} within a scalar is not a template action for the loader without --values
+	alert := "kind: Alert\napiVersion: v3\nmetadata:\n  name: lag\nspec:\n  message: 'Lag of {{ .consumerGroup }}'\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "unformatted.yaml"), []byte(unformatted), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "formatted.yaml"), []byte(formatted), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "template.yaml"), []byte(template), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "range.yaml"), []byte(rangeTemplate), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "alert.yaml"), []byte(alert), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "topics.json"), []byte(`{"kind":"Topic","apiVersion":"v2","metadata":{"name":"a"}}`), 0644))
+
+	handler := NewFormatHandler(RootContext{Catalog: *schema.ConsoleDefaultCatalog()})
+	results, err := handler.Handle(FormatHandlerContext{FilePaths: []string{dir}})
+	require.NoError(t, err)
+	require.Len(t, results, 5)
+	changed := map[string]bool{}
+	skipped := map[string]string{}
+	for _, result := range results {
+		changed[filepath.Base(result.Path)] = result.Changed
+		skipped[filepath.Base(result.Path)] = result.Skipped
+	}
+	assert.Equal(t, map[string]bool{"alert.yaml": true, "formatted.yaml": false, "range.yaml": false, "template.yaml": false, "unformatted.yaml": true}, changed)
+	assert.Equal(t, map[string]string{"alert.yaml": "", "formatted.yaml": "", "range.yaml": "Go template", "template.yaml": "Go template", "unformatted.yaml": ""}, skipped)
+
+	_, err = handler.Handle(FormatHandlerContext{FilePaths: []string{dir}, Write: true})
+	require.NoError(t, err)
+	data, err := os.ReadFile(filepath.Join(dir, "unformatted.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, formatted, string(data))
+
+	handler.Stdin = strings.NewReader(unformatted)
+	results, err = handler.Handle(FormatHandlerContext{FilePaths: []string{"-"}})
+	require.NoError(t, err)
+	assert.Equal(t, formatted, string(results[0].Formatted))
+
+	_, err = handler.Handle(FormatHandlerContext{FilePaths: []string{"https://example.com/topics.yaml"}})
+	assert.ErrorContains(t, err, "only local files and folders")
+}
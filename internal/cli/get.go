@@ -3,6 +3,7 @@ package cli
 import (
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/conduktor/ctl/pkg/resource"
 	"github.com/conduktor/ctl/pkg/schema"
@@ -11,6 +12,11 @@ import (
 type GetAllHandlerContext struct {
 	OnlyGateway *bool
 	OnlyConsole *bool
+	Selector    resource.Selector
+	// GatewayInstance lists the Gateway resources of a named Gateway instance instead of the default Gateway
+	GatewayInstance string
+	// MaskSensitiveFields masks the sensitive fields declared in the catalog, e.g. cluster passwords
+	MaskSensitiveFields bool
 }
 
 type GetKindHandlerContext struct {
@@ -18,23 +24,31 @@ type GetKindHandlerContext struct {
 	ParentFlagValue      []*string
 	ParentQueryFlagValue []*string
 	QueryParams          map[string]string
+	Selector             resource.Selector
+	// GatewayInstance reads the resources of a named Gateway instance instead of the default Gateway
+	GatewayInstance string
+	// MaskSensitiveFields masks the sensitive fields declared in the catalog, e.g. cluster passwords
+	MaskSensitiveFields bool
 }
 
 func GetAllsHandler(rootCtx RootContext, cmdCtx GetAllHandlerContext) ([]resource.Resource, []error) {
 	var allResources []resource.Resource
 	var allErrors []error
 
-	kindsByName := sortedKeys(rootCtx.Catalog.Kind)
-	if rootCtx.gatewayAPIClientError != nil {
+	gatewayClient, gatewayClientErr := rootCtx.gatewayInstanceClient(cmdCtx.GatewayInstance)
+	if gatewayClientErr != nil {
 		if *rootCtx.Debug || *cmdCtx.OnlyGateway {
-			return allResources, []error{fmt.Errorf("Cannot create Gateway client: %s\n", rootCtx.gatewayAPIClientError)}
+			return allResources, []error{fmt.Errorf("Cannot create Gateway client: %s\n", gatewayClientErr)}
 		}
 	}
-	if rootCtx.consoleAPIClientError != nil {
+	consoleClient, consoleClientErr := rootCtx.consoleClient()
+	if consoleClientErr != nil {
 		if *rootCtx.Debug || *cmdCtx.OnlyConsole {
-			return allResources, []error{fmt.Errorf("Cannot create Console client: %s\n", rootCtx.consoleAPIClientError)}
+			return allResources, []error{fmt.Errorf("Cannot create Console client: %s\n", consoleClientErr)}
 		}
 	}
+	// sorted once the clients add their live catalogs
+	kindsByName := sortedKeys(rootCtx.Catalog.Kind)
 	for _, key := range kindsByName {
 		kind := rootCtx.Catalog.Kind[key]
 		// keep only the Kinds where listing is provided TODO fix if config is provided
@@ -43,17 +57,22 @@ func GetAllsHandler(rootCtx RootContext, cmdCtx GetAllHandlerContext) ([]resourc
 		}
 		var resources []resource.Resource
 		var err error
-		if kind.IsGatewayKind() && !*cmdCtx.OnlyConsole && rootCtx.gatewayAPIClientError == nil {
-			resources, err = rootCtx.GatewayAPIClient().Get(&kind, []string{}, []string{}, map[string]string{})
-		} else if kind.IsConsoleKind() && !*cmdCtx.OnlyGateway && rootCtx.consoleAPIClientError == nil {
-			resources, err = rootCtx.ConsoleAPIClient().Get(&kind, []string{}, []string{}, map[string]string{})
+		queryParams := pushDownSelector(kind, cmdCtx.Selector, map[string]string{})
+		if kind.IsGatewayKind() && !*cmdCtx.OnlyConsole && gatewayClientErr == nil {
+			resources, err = gatewayClient.Get(&kind, []string{}, []string{}, queryParams)
+			resources = withGatewayInstance(resources, cmdCtx.GatewayInstance)
+		} else if kind.IsConsoleKind() && !*cmdCtx.OnlyGateway && consoleClientErr == nil {
+			resources, err = consoleClient.Get(&kind, []string{}, []string{}, queryParams)
 		}
 		if err != nil {
 			allErrors = append(allErrors, fmt.Errorf("Error fetching resource %s: %s\n", kind.GetName(), err))
 			continue
 		}
 
-		allResources = append(allResources, resources...)
+		allResources = append(allResources, cmdCtx.Selector.Filter(resources)...)
+	}
+	if cmdCtx.MaskSensitiveFields {
+		allResources = maskSensitiveFields(rootCtx.Catalog, allResources)
 	}
 	return allResources, allErrors
 }
@@ -67,7 +86,7 @@ func GetKindHandler(kind schema.Kind, rootCtx RootContext, cmdCtx GetKindHandler
 
 	parentValue := make([]string, len(cmdCtx.ParentFlagValue))
 	parentQueryValue := make([]string, len(cmdCtx.ParentQueryFlagValue))
-	queryParams := cmdCtx.QueryParams
+	queryParams := pushDownSelector(kind, cmdCtx.Selector, cmdCtx.QueryParams)
 	for i, v := range cmdCtx.ParentFlagValue {
 		parentValue[i] = *v
 	}
@@ -79,29 +98,92 @@ func GetKindHandler(kind schema.Kind, rootCtx RootContext, cmdCtx GetKindHandler
 
 	if len(cmdCtx.Args) == 0 {
 		if isGatewayKind {
-			result, err = rootCtx.GatewayAPIClient().Get(&kind, parentValue, parentQueryValue, queryParams)
+			result, err = rootCtx.GatewayInstanceAPIClient(cmdCtx.GatewayInstance).Get(&kind, parentValue, parentQueryValue, queryParams)
+			result = withGatewayInstance(result, cmdCtx.GatewayInstance)
 		} else {
 			result, err = rootCtx.ConsoleAPIClient().Get(&kind, parentValue, parentQueryValue, queryParams)
 		}
 		if err != nil {
 			errors = append(errors, fmt.Errorf("Error fetching resources: %s", err))
 		}
+		result = cmdCtx.Selector.Filter(result)
 	} else if len(cmdCtx.Args) == 1 {
 		var res resource.Resource
 		if isGatewayKind {
-			res, err = rootCtx.GatewayAPIClient().Describe(&kind, parentValue, parentQueryValue, cmdCtx.Args[0])
+			res, err = rootCtx.GatewayInstanceAPIClient(cmdCtx.GatewayInstance).Describe(&kind, parentValue, parentQueryValue, cmdCtx.Args[0])
+			res = res.WithGatewayInstance(cmdCtx.GatewayInstance)
 		} else {
 			res, err = rootCtx.ConsoleAPIClient().Describe(&kind, parentValue, parentQueryValue, cmdCtx.Args[0])
 		}
-		result = append(result, res)
 		if err != nil {
 			errors = append(errors, fmt.Errorf("Error describing resources: %s", err))
+			result = append(result, res)
+		} else if cmdCtx.Selector.Matches(res) {
+			// describe has no query parameter, the whole selector is evaluated client-side
+			result = append(result, res)
 		}
 
 	}
+	if cmdCtx.MaskSensitiveFields {
+		result = maskSensitiveFields(rootCtx.Catalog, result)
+	}
 	return result, errors
 }
 
+// withGatewayInstance makes the resources read from a named Gateway instance target it, so that they can be applied back.
+func withGatewayInstance(resources []resource.Resource, instance string) []resource.Resource {
+	if instance == "" {
+		return resources
+	}
+	for i, res := range resources {
+		resources[i] = res.WithGatewayInstance(instance)
+	}
+	return resources
+}
+
+func maskSensitiveFields(catalog schema.Catalog, resources []resource.Resource) []resource.Resource {
+	for i, res := range resources {
+		resources[i] = res.WithSensitiveFieldsMasked(catalog.SensitiveFields(res.Kind))
+	}
+	return resources
+}
+
+// pushDownSelector copies the equality requirements that the kind supports as list query filter
+// into the query parameters sent to the server.
+// A requirement matches a query parameter when its path is metadata.<parameter name>, e.g.
+// metadata.vCluster=passthrough is sent as ?vcluster=passthrough for a Gateway AliasTopic.
+// Boolean parameters like showDefaults are options changing the response rather than filters, they are never set.
+// The server can match more loosely than an exact equality, so the whole selector is still evaluated client-side.
+// Query parameters explicitly given by the user are never overridden.
+func pushDownSelector(kind schema.Kind, selector resource.Selector, queryParams map[string]string) map[string]string {
+	resultParams := make(map[string]string, len(queryParams))
+	for k, v := range queryParams {
+		resultParams[k] = v
+	}
+	listFlags := kind.GetListFlag()
+	for _, requirement := range selector {
+		paramName, supported := findListQueryFilter(listFlags, requirement)
+		if _, alreadySet := resultParams[paramName]; supported && !alreadySet {
+			resultParams[paramName] = requirement.Value
+		}
+	}
+	return resultParams
+}
+
+// findListQueryFilter returns the list query parameter filtering on the field of requirement, if any.
+func findListQueryFilter(listFlags map[string]schema.FlagParameterOption, requirement resource.Requirement) (string, bool) {
+	if requirement.Operator != resource.OpEqual || len(requirement.Path) != 2 || requirement.Path[0] != "metadata" {
+		return "", false
+	}
+	field := requirement.Path[1]
+	for paramName, flag := range listFlags {
+		if strings.EqualFold(paramName, field) && flag.Type != "boolean" {
+			return paramName, true
+		}
+	}
+	return "", false
+}
+
 func sortedKeys(kinds schema.KindCatalog) []string {
 	keys := make([]string, 0, len(kinds))
 	for key := range kinds {
@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/conduktor/ctl/pkg/resource"
+	"github.com/conduktor/ctl/pkg/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPushDownSelector(t *testing.T) {
+	kind := schema.NewKind(2, &schema.GatewayKindVersion{
+		Name:     "AliasTopic",
+		ListPath: "/gateway/v2/alias-topic",
+		ListQueryParameter: map[string]schema.FlagParameterOption{
+			"vcluster":     {FlagName: "vcluster", Type: "string"},
+			"name":         {FlagName: "name", Type: "string"},
+			"showDefaults": {FlagName: "show-defaults", Type: "boolean"},
+		},
+	})
+
+	selector, err := resource.ParseFieldSelector("spec.target.vcluster=other,metadata.vCluster=passthrough,metadata.name=explicit,spec.physicalName!=a")
+	assert.NoError(t, err)
+	labels, err := resource.ParseLabelSelector("name=x")
+	assert.NoError(t, err)
+	selector = append(selector, labels...)
+
+	queryParams := pushDownSelector(kind, selector, map[string]string{"name": "fromFlag"})
+
+	// only metadata.<param> is pushed down, without overriding the query parameters given by the user
+	assert.Equal(t, map[string]string{
+		"vcluster": "passthrough",
+		"name":     "fromFlag",
+	}, queryParams)
+}
@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/conduktor/ctl/internal/utils"
+	"github.com/conduktor/ctl/pkg/client"
+)
+
+// PluginPrefix is the prefix of the plugin executables, conduktor-<name> being run by conduktor <name>.
+const PluginPrefix = "conduktor-"
+
+const (
+	PluginInfoAPIVersion = "conduktor.io/v1"
+	PluginInfoKind       = "PluginInfo"
+)
+
+// PluginInfo is the document given to a plugin in the CDK_PLUGIN_INFO variable, with the connections of the
+// Console and Gateways that could be created, e.g. without the Gateway when CDK_GATEWAY_BASE_URL is not set.
+type PluginInfo struct {
+	APIVersion string         `json:"apiVersion"`
+	Kind       string         `json:"kind"`
+	Spec       PluginInfoSpec `json:"spec"`
+}
+
+type PluginInfoSpec struct {
+	Name       string             `json:"name"`
+	CLIVersion string             `json:"cliVersion"`
+	Console    *client.Connection `json:"console,omitempty"`
+	Gateway    *client.Connection `json:"gateway,omitempty"`
+	// GatewayInstances are the connections of the named Gateway instances, see RootContext.WithGatewayInstances
+	GatewayInstances map[string]client.Connection `json:"gatewayInstances,omitempty"`
+}
+
+var pluginNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+// Plugin is an executable of the PATH named after PluginPrefix.
+type Plugin struct {
+	Name string
+	Path string
+	// ShadowedBy tells why the plugin is never run, e.g. a built-in command or a plugin earlier in the PATH, empty otherwise
+	ShadowedBy string
+}
+
+// FindPlugins returns the plugins of the directories of pathList, e.g. the PATH variable, sorted by name,
+// the ones of the same name in the order of pathList. isBuiltin tells whether a name is taken by a built-in command.
+func FindPlugins(pathList string, isBuiltin func(name string) bool) []Plugin {
+	var plugins []Plugin
+	found := make(map[string]string)
+	for _, dir := range filepath.SplitList(pathList) {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := pluginName(entry.Name())
+			if !ok {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if !isExecutable(path) {
+				continue
+			}
+			plugin := Plugin{Name: name, Path: path}
+			if isBuiltin(name) {
+				plugin.ShadowedBy = "built-in command " + name
+			} else if first, exists := found[name]; exists {
+				plugin.ShadowedBy = first
+			} else {
+				found[name] = path
+			}
+			plugins = append(plugins, plugin)
+		}
+	}
+	sort.SliceStable(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins
+}
+
+// pluginName returns the name of the plugin of an executable, e.g. topic-report for conduktor-topic-report,
+// without the extension of the executable on Windows.
+func pluginName(fileName string) (string, bool) {
+	name, ok := strings.CutPrefix(fileName, PluginPrefix)
+	if !ok || !IsPluginName(name) {
+		return "", false
+	}
+	if runtime.GOOS == "windows" {
+		extension := strings.ToLower(filepath.Ext(name))
+		if !isWindowsExecutableExtension(extension) {
+			return "", false
+		}
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	return name, name != ""
+}
+
+func isWindowsExecutableExtension(extension string) bool {
+	extensions := os.Getenv("PATHEXT")
+	if extensions == "" {
+		extensions = ".com;.exe;.bat;.cmd"
+	}
+	for _, executable := range filepath.SplitList(strings.ToLower(extensions)) {
+		if extension == executable {
+			return true
+		}
+	}
+	return false
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	return runtime.GOOS == "windows" || info.Mode().Perm()&0111 != 0
+}
+
+// PluginEnv returns the variables given to a plugin on top of the environment: its PluginInfo in CDK_PLUGIN_INFO
+// and the catalog of kinds, with the live catalogs of the clients that could be created, in CDK_PLUGIN_CATALOG.
+// The clients are created, logging in if needed, so that the plugin gets their Authorization header.
+func PluginEnv(rootCtx RootContext, name string) ([]string, error) {
+	info := PluginInfo{
+		APIVersion: PluginInfoAPIVersion,
+		Kind:       PluginInfoKind,
+		Spec: PluginInfoSpec{
+			Name:       name,
+			CLIVersion: utils.GetConduktorVersion(),
+		},
+	}
+	if consoleClient, err := rootCtx.consoleClient(); err == nil {
+		connection := consoleClient.Connection()
+		info.Spec.Console = &connection
+	}
+	if gatewayClient, err := rootCtx.gatewayClient(); err == nil {
+		connection := gatewayClient.Connection()
+		info.Spec.Gateway = &connection
+	}
+	for _, instance := range rootCtx.GatewayInstances() {
+		if gatewayClient, err := rootCtx.gatewayInstanceClient(instance); err == nil {
+			if info.Spec.GatewayInstances == nil {
+				info.Spec.GatewayInstances = make(map[string]client.Connection)
+			}
+			info.Spec.GatewayInstances[instance] = gatewayClient.Connection()
+		}
+	}
+	infoJSON, err := json.Marshal(info)
+	if err != nil {
+		return nil, err
+	}
+	catalogJSON, err := json.Marshal(rootCtx.Catalog)
+	if err != nil {
+		return nil, err
+	}
+	return []string{"CDK_PLUGIN_INFO=" + string(infoJSON), "CDK_PLUGIN_CATALOG=" + string(catalogJSON)}, nil
+}
+
+// IsPluginName tells whether a command line argument can name a plugin, e.g. not a flag or a path.
+func IsPluginName(name string) bool {
+	return pluginNamePattern.MatchString(name)
+}
@@ -3,19 +3,61 @@ package cli
 import (
 	"fmt"
 	"os"
+	"sort"
+	"sync"
 
 	"github.com/conduktor/ctl/pkg/client"
+	"github.com/conduktor/ctl/pkg/resource"
 	"github.com/conduktor/ctl/pkg/schema"
 )
 
 type RootContext struct {
-	consoleAPIClient      *client.Client
-	consoleAPIClientError error
-	gatewayAPIClient      *client.GatewayClient
-	gatewayAPIClientError error
-	Catalog               schema.Catalog
-	Strict                bool
-	Debug                 *bool
+	console *lazyClient[*client.Client]
+	gateway *lazyClient[*client.GatewayClient]
+	// gatewayInstances are the named Gateways next to the default one, see WithGatewayInstances
+	gatewayInstances map[string]*lazyClient[*client.GatewayClient]
+	Catalog          schema.Catalog
+	Strict           bool
+	Debug            *bool
+	// AllowExecSecrets resolves the ${exec:} and ${file:} secret references of local files, see resource.SecretPolicy
+	AllowExecSecrets *bool
+	// refreshCachedCatalogs downloads again the cached catalogs of the created clients, see LoadCatalogs
+	refreshCachedCatalogs func()
+}
+
+// lazyClient creates a client on its first use, so that the commands not calling an API never wait for it.
+type lazyClient[T interface{ ActivateDebug() }] struct {
+	once    sync.Once
+	create  func() (T, error)
+	client  T
+	err     error
+	created bool
+	debug   bool
+}
+
+func (l *lazyClient[T]) get() (T, error) {
+	if l == nil {
+		var none T
+		return none, fmt.Errorf("client not configured")
+	}
+	l.once.Do(func() {
+		l.client, l.err = l.create()
+		l.created = true
+		if l.err == nil && l.debug {
+			l.client.ActivateDebug()
+		}
+	})
+	return l.client, l.err
+}
+
+func (l *lazyClient[T]) activateDebug() {
+	if l == nil {
+		return
+	}
+	l.debug = true
+	if l.created && l.err == nil {
+		l.client.ActivateDebug()
+	}
 }
 
 func NewRootContext(
@@ -28,30 +70,200 @@ func NewRootContext(
 	debug *bool,
 ) RootContext {
 	return RootContext{
-		consoleAPIClient:      consoleAPIClient,
-		consoleAPIClientError: consoleAPIClientError,
-		gatewayAPIClient:      gatewayAPIClient,
-		gatewayAPIClientError: gatewayAPIClientError,
-		Catalog:               catalog,
-		Strict:                strict,
-		Debug:                 debug,
+		console: &lazyClient[*client.Client]{create: func() (*client.Client, error) { return consoleAPIClient, consoleAPIClientError }},
+		gateway: &lazyClient[*client.GatewayClient]{create: func() (*client.GatewayClient, error) { return gatewayAPIClient, gatewayAPIClientError }},
+		Catalog: catalog,
+		Strict:  strict,
+		Debug:   debug,
+	}
+}
+
+// NewLazyRootContext builds a RootContext whose clients are created on first use, e.g. logging in.
+// The catalog is the merge of the Console and Gateway ones, replaced by the live catalog of each client once created.
+// The copies of the context share the clients and the catalog.
+func NewLazyRootContext(
+	makeConsoleAPIClient func() (*client.Client, error),
+	consoleCatalog *schema.Catalog,
+	makeGatewayAPIClient func() (*client.GatewayClient, error),
+	gatewayCatalog *schema.Catalog,
+	strict bool,
+	debug *bool,
+) RootContext {
+	catalog := consoleCatalog.Merge(gatewayCatalog)
+	console := &lazyClient[*client.Client]{create: func() (*client.Client, error) {
+		consoleAPIClient, err := makeConsoleAPIClient()
+		if err == nil {
+			consoleCatalog = consoleAPIClient.GetCatalog()
+			catalog.Replace(consoleCatalog.Merge(gatewayCatalog))
+		}
+		return consoleAPIClient, err
+	}}
+	gateway := &lazyClient[*client.GatewayClient]{create: func() (*client.GatewayClient, error) {
+		gatewayAPIClient, err := makeGatewayAPIClient()
+		if err == nil {
+			gatewayCatalog = gatewayAPIClient.GetCatalog()
+			catalog.Replace(consoleCatalog.Merge(gatewayCatalog))
+		}
+		return gatewayAPIClient, err
+	}}
+	return RootContext{
+		console: console,
+		gateway: gateway,
+		Catalog: catalog,
+		Strict:  strict,
+		Debug:   debug,
+		refreshCachedCatalogs: func() {
+			refreshed := false
+			if console.created && console.err == nil && console.client.RefreshCachedCatalog() {
+				consoleCatalog = console.client.GetCatalog()
+				refreshed = true
+			}
+			if gateway.created && gateway.err == nil && gateway.client.RefreshCachedCatalog() {
+				gatewayCatalog = gateway.client.GetCatalog()
+				refreshed = true
+			}
+			if refreshed {
+				catalog.Replace(consoleCatalog.Merge(gatewayCatalog))
+			}
+		},
+	}
+}
+
+// WithGatewayInstances returns a copy of the context with named Gateway instances, targeted by the resources setting
+// metadata.gateway, see resource.GatewayInstanceKey. Their clients are created on first use.
+func (c RootContext) WithGatewayInstances(names []string, makeGatewayAPIClient func(name string) (*client.GatewayClient, error)) RootContext {
+	c.gatewayInstances = make(map[string]*lazyClient[*client.GatewayClient], len(names))
+	for _, name := range names {
+		c.gatewayInstances[name] = &lazyClient[*client.GatewayClient]{create: func() (*client.GatewayClient, error) { return makeGatewayAPIClient(name) }}
+	}
+	return c
+}
+
+// GatewayInstances returns the names of the named Gateway instances, sorted.
+func (c *RootContext) GatewayInstances() []string {
+	names := make([]string, 0, len(c.gatewayInstances))
+	for name := range c.gatewayInstances {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (c *RootContext) consoleClient() (*client.Client, error) {
+	return c.console.get()
+}
+
+func (c *RootContext) gatewayClient() (*client.GatewayClient, error) {
+	return c.gateway.get()
+}
+
+// gatewayInstanceClient returns the client of a named Gateway instance, the one of the default Gateway if name is empty.
+func (c *RootContext) gatewayInstanceClient(name string) (*client.GatewayClient, error) {
+	if name == "" {
+		return c.gatewayClient()
 	}
+	instance, ok := c.gatewayInstances[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown Gateway instance %s, expected one of CDK_GATEWAY_INSTANCES", name)
+	}
+	return instance.get()
+}
+
+// gatewayClientsOf returns the clients of the Gateway instances targeted by resources, by instance name.
+func (c *RootContext) gatewayClientsOf(resources []resource.Resource) (map[string]*client.GatewayClient, error) {
+	clients := make(map[string]*client.GatewayClient)
+	for _, res := range resources {
+		instance := res.GatewayInstance()
+		if _, ok := clients[instance]; ok {
+			continue
+		}
+		gatewayClient, err := c.gatewayInstanceClient(instance)
+		if err != nil {
+			return nil, err
+		}
+		clients[instance] = gatewayClient
+	}
+	return clients, nil
 }
 
 func (c *RootContext) ConsoleAPIClient() *client.Client {
-	if c.consoleAPIClientError != nil {
-		fmt.Fprintf(os.Stderr, "Cannot create client: %s", c.consoleAPIClientError)
+	consoleAPIClient, err := c.consoleClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot create client: %s", err)
 		// Fail fast if client cannot be created
 		os.Exit(1)
 	}
-	return c.consoleAPIClient
+	return consoleAPIClient
 }
 
 func (c *RootContext) GatewayAPIClient() *client.GatewayClient {
-	if c.gatewayAPIClientError != nil {
-		fmt.Fprintf(os.Stderr, "Cannot create gateway client: %s", c.gatewayAPIClientError)
+	gatewayAPIClient, err := c.gatewayClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot create gateway client: %s", err)
+		// Fail fast if client cannot be created
+		os.Exit(1)
+	}
+	return gatewayAPIClient
+}
+
+// GatewayInstanceAPIClient returns the client of a named Gateway instance, the one of the default Gateway if name is empty.
+func (c *RootContext) GatewayInstanceAPIClient(name string) *client.GatewayClient {
+	gatewayAPIClient, err := c.gatewayInstanceClient(name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot create gateway client: %s\n", err)
 		// Fail fast if client cannot be created
 		os.Exit(1)
 	}
-	return c.gatewayAPIClient
+	return gatewayAPIClient
+}
+
+// LoadCatalogs creates the clients so that the catalog holds their catalogs, for the commands resolving kinds
+// missing from the cached or embedded catalogs the commands are registered with. Clients that cannot be created are skipped.
+// A cached catalog lacking the kind or version of one of resources no longer matches the server, e.g. upgraded since
+// it was cached, and is downloaded again.
+func (c *RootContext) LoadCatalogs(resources ...resource.Resource) {
+	_, _ = c.consoleClient()
+	_, _ = c.gatewayClient()
+	if c.refreshCachedCatalogs != nil && lacksKindOf(&c.Catalog, resources) {
+		c.refreshCachedCatalogs()
+	}
+}
+
+// lacksKindOf returns true if the catalog has not the kind or the version of one of resources.
+func lacksKindOf(catalog *schema.Catalog, resources []resource.Resource) bool {
+	for _, res := range resources {
+		kind, err := catalog.LookupKind(res.Kind, res.Version)
+		if err != nil {
+			return true
+		}
+		version, err := schema.ParseAPIVersion(res.Version)
+		if _, ok := kind.Versions[version]; err == nil && !ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ActivateDebug enables the debug mode of the clients, on creation for the ones not created yet.
+func (c *RootContext) ActivateDebug() {
+	c.console.activateDebug()
+	c.gateway.activateDebug()
+	for _, instance := range c.gatewayInstances {
+		instance.activateDebug()
+	}
+}
+
+// Loader returns the loader of the resource files of a command, rendering them as templates when values is not nil.
+// URLs are fetched with the TLS settings of the Console client. With --verbose, the files skipped in folders are reported.
+func (c *RootContext) Loader(recursiveFolder bool, values map[string]interface{}, filter resource.FileFilter) resource.Loader {
+	return resource.Loader{
+		Strict:     c.Strict,
+		Recursive:  recursiveFolder,
+		Values:     values,
+		HTTPClient: client.NewHTTPClientFromEnv(),
+		Filter:     filter,
+		Debug:      c.Debug != nil && *c.Debug,
+		// read when the command runs, after the flags are parsed
+		AllowExecSecrets: c.AllowExecSecrets != nil && *c.AllowExecSecrets,
+	}
 }
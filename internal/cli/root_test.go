@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/conduktor/ctl/pkg/client"
+	"github.com/conduktor/ctl/pkg/resource"
+	"github.com/conduktor/ctl/pkg/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLazyRootContextCreatesTheClientsOnFirstUse(t *testing.T) {
+	consoleCalls, gatewayCalls := 0, 0
+	debug := false
+	rootCtx := NewLazyRootContext(
+		func() (*client.Client, error) {
+			consoleCalls++
+			return nil, errors.New("Please set CDK_BASE_URL")
+		},
+		schema.ConsoleDefaultCatalog(),
+		func() (*client.GatewayClient, error) {
+			gatewayCalls++
+			return nil, errors.New("Please set CDK_GATEWAY_BASE_URL")
+		},
+		schema.GatewayDefaultCatalog(),
+		true,
+		&debug,
+	)
+	assert.Equal(t, 0, consoleCalls+gatewayCalls)
+	assert.Contains(t, rootCtx.Catalog.Kind, "Topic")
+	assert.Contains(t, rootCtx.Catalog.Kind, "VirtualCluster")
+
+	copied := rootCtx
+	_, err := copied.consoleClient()
+	assert.EqualError(t, err, "Please set CDK_BASE_URL")
+	rootCtx.LoadCatalogs()
+	assert.Equal(t, 1, consoleCalls)
+	assert.Equal(t, 1, gatewayCalls)
+	assert.Contains(t, rootCtx.Catalog.Kind, "Topic")
+}
+
+func TestRootContextWithoutClients(t *testing.T) {
+	_, err := (&RootContext{}).gatewayClient()
+	assert.EqualError(t, err, "client not configured")
+}
+
+func TestLoadCatalogsShouldRefreshACachedCatalogLackingAKind(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		_, _ = w.Write([]byte(`openapi: 3.0.3
+info:
+  title: Conduktor Public API
+  version: 1.30.0
+paths:
+  /public/kafka/v2/cluster/{cluster}/topic:
+    get:
+      tags: [cli_topic_kafka_v2_12]
+      parameters:
+        - {name: cluster, in: path, required: true, schema: {type: string}}
+      responses: {'200': {description: ''}}
+    put:
+      tags: [cli_topic_kafka_v2_12]
+      parameters:
+        - {name: cluster, in: path, required: true, schema: {type: string}}
+      requestBody:
+        content:
+          application/json:
+            schema: {type: object}
+      responses: {'200': {description: ''}}
+`))
+	}))
+	defer server.Close()
+	makeConsoleClient := func() (*client.Client, error) {
+		return client.Make(client.APIParameter{BaseURL: server.URL, APIKey: "key", Catalog: client.CatalogOptions{TTL: time.Hour}})
+	}
+	_, err := makeConsoleClient()
+	require.NoError(t, err)
+	require.Equal(t, 1, fetches)
+
+	debug := false
+	rootCtx := NewLazyRootContext(makeConsoleClient, schema.ConsoleDefaultCatalog(),
+		func() (*client.GatewayClient, error) { return nil, errors.New("Please set CDK_GATEWAY_BASE_URL") },
+		schema.GatewayDefaultCatalog(), true, &debug)
+	topic := resource.Resource{Kind: "Topic", Version: "v2", Name: "orders"}
+	application := resource.Resource{Kind: "Application", Version: "v1", Name: "shop"}
+
+	rootCtx.LoadCatalogs(topic)
+	assert.Equal(t, 1, fetches, "the cached catalog has the kind of the resources")
+	assert.Equal(t, client.CatalogCached, rootCtx.ConsoleAPIClient().CatalogInfo().Source)
+
+	rootCtx.LoadCatalogs(topic, application)
+	assert.Equal(t, 2, fetches, "the cached catalog lacks the kind of a resource")
+	assert.Equal(t, client.CatalogLive, rootCtx.ConsoleAPIClient().CatalogInfo().Source)
+
+	rootCtx.LoadCatalogs(application)
+	assert.Equal(t, 2, fetches, "the live catalog is not downloaded again")
+}
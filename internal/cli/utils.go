@@ -1,17 +1,19 @@
 package cli
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/conduktor/ctl/pkg/resource"
 )
 
 // LoadResourcesFromFiles loads resources from multiple file paths.
-func LoadResourcesFromFiles(filePaths []string, strict, recursiveFolder bool) ([]resource.Resource, error) {
+// Every command loading files goes through it so they all render templates identically.
+func LoadResourcesFromFiles(filePaths []string, loader resource.Loader) ([]resource.Resource, error) {
 	var allResources []resource.Resource
 
 	for _, path := range filePaths {
-		resources, err := ResourceForPath(path, strict, recursiveFolder)
+		resources, err := ResourceForPath(path, loader)
 		if err != nil {
 			return nil, err
 		}
@@ -21,17 +23,28 @@ func LoadResourcesFromFiles(filePaths []string, strict, recursiveFolder bool) ([
 	return allResources, nil
 }
 
-// ResourceForPath loads resources from a single path (file or directory).
-func ResourceForPath(path string, strict, recursiveFolder bool) ([]resource.Resource, error) {
-	directory, err := IsDirectory(path)
-	if err != nil {
-		return nil, err
-	}
-	if directory {
-		return resource.FromFolder(path, strict, recursiveFolder)
-	} else {
-		return resource.FromFile(path, strict)
+// BuildOverlays builds the resources of multiple overlays.
+func BuildOverlays(overlays []string, loader resource.Loader) ([]resource.Resource, error) {
+	var allResources []resource.Resource
+
+	for _, overlay := range overlays {
+		if !resource.IsOverlay(overlay) {
+			return nil, fmt.Errorf("%s is not an overlay, missing %s", overlay, resource.OverlayFileName)
+		}
+		resources, err := loader.Build(overlay)
+		if err != nil {
+			return nil, err
+		}
+		allResources = append(allResources, resources...)
 	}
+
+	return allResources, nil
+}
+
+// ResourceForPath loads resources from a single path: a file, a directory, "-" for stdin, a URL or a git source.
+// See resource.Loader.Load.
+func ResourceForPath(path string, loader resource.Loader) ([]resource.Resource, error) {
+	return loader.Load(path)
 }
 
 // IsDirectory checks if the given path is a directory.
@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/conduktor/ctl/pkg/resource"
+)
+
+type ValidateHandlerContext struct {
+	FilePaths      []string
+	Recursive      bool
+	TemplateValues map[string]interface{}
+	FileFilter     resource.FileFilter
+	// StrictYaml validates the structure of the files, see resource.Loader
+	StrictYaml bool
+}
+
+type ValidateHandler struct {
+	rootCtx RootContext
+}
+
+func NewValidateHandler(rootCtx RootContext) *ValidateHandler {
+	return &ValidateHandler{
+		rootCtx: rootCtx,
+	}
+}
+
+// Handle loads the resources of files as apply does, without calling any API, and checks that the catalog has their kind
+// and version and that they have the metadata of their parents, e.g. the cluster of a Topic. It returns the resources.
+func (h *ValidateHandler) Handle(cmdCtx ValidateHandlerContext) ([]resource.Resource, error) {
+	loader := h.rootCtx.Loader(cmdCtx.Recursive, cmdCtx.TemplateValues, cmdCtx.FileFilter)
+	loader.StrictYaml = cmdCtx.StrictYaml
+	resources, err := LoadResourcesFromFiles(cmdCtx.FilePaths, loader)
+	if err != nil {
+		return nil, err
+	}
+	var invalid []string
+	for i := range resources {
+		err = h.validate(&resources[i])
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s/%s: %s", resources[i].Kind, resources[i].Name, err))
+		}
+	}
+	if len(invalid) > 0 {
+		return nil, fmt.Errorf("%d invalid resource(s):\n%s", len(invalid), strings.Join(invalid, "\n"))
+	}
+	return resources, nil
+}
+
+func (h *ValidateHandler) validate(res *resource.Resource) error {
+	kind, err := h.rootCtx.Catalog.LookupKind(res.Kind, res.Version)
+	if err != nil {
+		return err
+	}
+	_, err = kind.ApplyPath(res)
+	return err
+}
@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/conduktor/ctl/pkg/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateHandler(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+	valid := write("valid.yaml", "apiVersion: v2\nkind: Topic\nmetadata:\n  name: orders\n  cluster: prod\nspec:\n  partitions: 3\n")
+	noCluster := write("no-cluster.yaml", "apiVersion: v2\nkind: Topic\nmetadata:\n  name: orders\nspec:\n  partitions: 3\n")
+	unknownKind := write("unknown.yaml", "apiVersion: v1\nkind: Topik\nmetadata:\n  name: orders\n")
+	unknownKey := write("unknown-key.yaml", "apiVersion: v2\nkind: Topic\nmetadata:\n  name: orders\n  cluster: prod\nspecs:\n  partitions: 3\n")
+
+	handler := NewValidateHandler(RootContext{Catalog: *schema.ConsoleDefaultCatalog()})
+	resources, err := handler.Handle(ValidateHandlerContext{FilePaths: []string{valid}, StrictYaml: true})
+	require.NoError(t, err)
+	assert.Len(t, resources, 1)
+
+	_, err = handler.Handle(ValidateHandlerContext{FilePaths: []string{valid, noCluster, unknownKind}, StrictYaml: true})
+	assert.ErrorContains(t, err, "2 invalid resource(s)")
+	assert.ErrorContains(t, err, "Topic/orders: ")
+	assert.ErrorContains(t, err, "Topik/orders: kind Topik not found")
+
+	_, err = handler.Handle(ValidateHandlerContext{FilePaths: []string{unknownKey}, StrictYaml: true})
+	assert.ErrorContains(t, err, unknownKey+":6:1: unknown top-level key specs of Topic")
+	_, err = handler.Handle(ValidateHandlerContext{FilePaths: []string{unknownKey}})
+	assert.NoError(t, err)
+}
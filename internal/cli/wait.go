@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/conduktor/ctl/pkg/resource"
+	"github.com/conduktor/ctl/pkg/schema"
+)
+
+const (
+	DefaultWaitTimeout     = 5 * time.Minute
+	DefaultWaitInterval    = 1 * time.Second
+	DefaultWaitMaxInterval = 15 * time.Second
+)
+
+// existCondition is used when neither the user nor the catalog provide a readiness condition.
+var existCondition = resource.Selector{{Path: []string{"metadata", "name"}, Operator: resource.OpExists}}
+
+type WaitHandlerContext struct {
+	Name                 string
+	ParentFlagValue      []*string
+	ParentQueryFlagValue []*string
+	// Condition to wait for, if empty the kind ReadyCondition from the catalog is used
+	Condition   resource.Selector
+	Interval    time.Duration
+	MaxInterval time.Duration
+	Timeout     time.Duration
+	// GatewayInstance polls a named Gateway instance instead of the default Gateway
+	GatewayInstance string
+}
+
+// ParseWaitCondition parses a condition given to --for.
+// Supported formats are:
+//   - jsonpath=.metadata.status=Ready: wait for the field to be equal to the value
+//   - jsonpath=.metadata.status: wait for the field to exist
+//   - field=spec.state==RUNNING,spec.tasks>0: wait for a field selector to match
+//   - exists: wait for the resource to exist
+func ParseWaitCondition(input string) (resource.Selector, error) {
+	conditionType, expression, _ := strings.Cut(strings.TrimSpace(input), "=")
+	switch conditionType {
+	case "":
+		return nil, nil
+	case "exists":
+		return existCondition, nil
+	case "field":
+		return resource.ParseFieldSelector(expression)
+	case "jsonpath":
+		path, value, hasValue := strings.Cut(expression, "=")
+		path = strings.TrimPrefix(strings.Trim(strings.TrimSpace(path), "{}"), ".")
+		if path == "" {
+			return nil, fmt.Errorf("invalid condition \"%s\": missing json path", input)
+		}
+		requirement := resource.Requirement{Path: strings.Split(path, "."), Operator: resource.OpExists}
+		if hasValue {
+			requirement.Operator = resource.OpEqual
+			requirement.Value = strings.TrimSpace(value)
+		}
+		return resource.Selector{requirement}, nil
+	default:
+		return nil, fmt.Errorf("invalid condition \"%s\": expected jsonpath=<path>[=<value>], field=<field selector> or exists", input)
+	}
+}
+
+// ReadyCondition returns the condition declared in the catalog for the kind with the x-cdk-ready-condition extension,
+// or a condition on the resource existence if the kind does not declare one.
+func ReadyCondition(kind schema.Kind) (resource.Selector, error) {
+	condition, err := ParseWaitCondition(kind.GetLatestKindVersion().GetReadyCondition())
+	if err != nil {
+		return nil, fmt.Errorf("invalid ready condition in catalog for kind %s: %s", kind.GetName(), err)
+	}
+	if len(condition) == 0 {
+		return existCondition, nil
+	}
+	return condition, nil
+}
+
+// WaitHandler polls the resource with an exponential backoff until it matches the condition.
+// On timeout, it returns the last observed resource, if any, along with an ErrWatchTimeout.
+func WaitHandler(kind schema.Kind, rootCtx RootContext, cmdCtx WaitHandlerContext) (*resource.Resource, error) {
+	condition := cmdCtx.Condition
+	if len(condition) == 0 {
+		var err error
+		condition, err = ReadyCondition(kind)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	getCtx := GetKindHandlerContext{
+		Args:                 []string{cmdCtx.Name},
+		ParentFlagValue:      cmdCtx.ParentFlagValue,
+		ParentQueryFlagValue: cmdCtx.ParentQueryFlagValue,
+		GatewayInstance:      cmdCtx.GatewayInstance,
+	}
+	gatewayKind, isGatewayKind := kind.GetLatestKindVersion().(*schema.GatewayKindVersion)
+	if isGatewayKind && !gatewayKind.GetAvailable {
+		// no describe endpoint, list and filter by name instead
+		getCtx.Args = []string{}
+		getCtx.Selector = resource.Selector{{Path: []string{"metadata", "name"}, Operator: resource.OpEqual, Value: cmdCtx.Name}}
+	}
+	fetch := func() ([]resource.Resource, []error) {
+		return GetKindHandler(kind, rootCtx, getCtx)
+	}
+
+	var lastObserved *resource.Resource
+	watchCtx := WatchHandlerContext{
+		Interval:    cmdCtx.Interval,
+		MaxInterval: cmdCtx.MaxInterval,
+		Until:       condition,
+		Timeout:     cmdCtx.Timeout,
+		// resource may not exist yet, only report errors in debug
+		SilentErrors: !*rootCtx.Debug,
+	}
+	err := Watch(kind, fetch, watchCtx, func(event WatchEvent) error {
+		if event.Type != Deleted {
+			lastObserved = &event.Resource
+		} else {
+			lastObserved = nil
+		}
+		return nil
+	})
+	return lastObserved, err
+}
+
+// WaitForResource waits for an applied resource to match the ready condition of its kind.
+// The parent values are read from the resource metadata.
+func WaitForResource(rootCtx RootContext, res resource.Resource, timeout time.Duration) (*resource.Resource, error) {
+	kind, err := rootCtx.Catalog.LookupKind(res.Kind, res.Version)
+	if err != nil {
+		return nil, err
+	}
+	parentFlagValue := make([]*string, len(kind.GetParentFlag()))
+	for i, param := range kind.GetParentFlag() {
+		value, err := res.StringFromMetadata(param)
+		if err != nil {
+			return nil, err
+		}
+		parentFlagValue[i] = &value
+	}
+	parentQueryFlagValue := make([]*string, len(kind.GetParentQueryFlag()))
+	for i, param := range kind.GetParentQueryFlag() {
+		// optional parent query params are sent only when present
+		value, _ := res.StringFromMetadata(param)
+		parentQueryFlagValue[i] = &value
+	}
+	return WaitHandler(kind, rootCtx, WaitHandlerContext{
+		Name:                 res.Name,
+		ParentFlagValue:      parentFlagValue,
+		ParentQueryFlagValue: parentQueryFlagValue,
+		Interval:             DefaultWaitInterval,
+		MaxInterval:          DefaultWaitMaxInterval,
+		Timeout:              timeout,
+		GatewayInstance:      res.GatewayInstance(),
+	})
+}
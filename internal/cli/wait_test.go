@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/conduktor/ctl/pkg/resource"
+	"github.com/conduktor/ctl/pkg/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWaitCondition(t *testing.T) {
+	condition, err := ParseWaitCondition("jsonpath=.metadata.status=Ready")
+	assert.NoError(t, err)
+	assert.Equal(t, resource.Selector{{Path: []string{"metadata", "status"}, Operator: resource.OpEqual, Value: "Ready"}}, condition)
+
+	condition, err = ParseWaitCondition("jsonpath={.spec.state}")
+	assert.NoError(t, err)
+	assert.Equal(t, resource.Selector{{Path: []string{"spec", "state"}, Operator: resource.OpExists}}, condition)
+
+	condition, err = ParseWaitCondition("field=spec.state==RUNNING,spec.tasks>0")
+	assert.NoError(t, err)
+	assert.Equal(t, resource.Selector{
+		{Path: []string{"spec", "state"}, Operator: resource.OpEqual, Value: "RUNNING"},
+		{Path: []string{"spec", "tasks"}, Operator: resource.OpGreater, Value: "0"},
+	}, condition)
+
+	condition, err = ParseWaitCondition("exists")
+	assert.NoError(t, err)
+	assert.Equal(t, existCondition, condition)
+
+	condition, err = ParseWaitCondition("")
+	assert.NoError(t, err)
+	assert.Empty(t, condition)
+
+	_, err = ParseWaitCondition("jsonpath=")
+	assert.Error(t, err)
+	_, err = ParseWaitCondition("condition=Ready")
+	assert.Error(t, err)
+}
+
+func TestReadyCondition(t *testing.T) {
+	withoutCondition := schema.NewKind(2, &schema.ConsoleKindVersion{Name: "Topic"})
+	condition, err := ReadyCondition(withoutCondition)
+	assert.NoError(t, err)
+	assert.Equal(t, existCondition, condition)
+
+	withCondition := schema.NewKind(2, &schema.ConsoleKindVersion{Name: "Connector", ReadyCondition: "jsonpath=.metadata.status=Ready"})
+	condition, err = ReadyCondition(withCondition)
+	assert.NoError(t, err)
+	assert.Equal(t, resource.Selector{{Path: []string{"metadata", "status"}, Operator: resource.OpEqual, Value: "Ready"}}, condition)
+
+	invalidCondition := schema.NewKind(2, &schema.ConsoleKindVersion{Name: "Connector", ReadyCondition: "Ready"})
+	_, err = ReadyCondition(invalidCondition)
+	assert.Error(t, err)
+}
@@ -0,0 +1,193 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/conduktor/ctl/pkg/resource"
+	"github.com/conduktor/ctl/pkg/schema"
+)
+
+type WatchEventType string
+
+const (
+	Added    WatchEventType = "ADDED"
+	Modified WatchEventType = "MODIFIED"
+	Deleted  WatchEventType = "DELETED"
+)
+
+type WatchEvent struct {
+	Type     WatchEventType    `json:"type"`
+	Resource resource.Resource `json:"object"`
+}
+
+type WatchHandlerContext struct {
+	Interval time.Duration
+	// MaxInterval enable an exponential backoff of the polling interval up to this value, ignored if lower than Interval
+	MaxInterval time.Duration
+	// Until stop watching once every resource of the snapshot matches it, ignored if empty.
+	// An empty snapshot never matches, e.g. to wait for a resource to be created, use Timeout to bound the watch.
+	Until resource.Selector
+	// Timeout stop watching after the given duration, with an ErrWatchTimeout if Until is set, ignored if zero
+	Timeout time.Duration
+	// SilentErrors do not report fetch errors on stderr, e.g. while waiting for a resource to be created
+	SilentErrors bool
+}
+
+// ErrWatchTimeout is returned by Watch when the Until condition did not hold before the timeout.
+type ErrWatchTimeout struct {
+	Timeout time.Duration
+	Until   resource.Selector
+	// LastSnapshot is the last successfully fetched list of resources
+	LastSnapshot []resource.Resource
+}
+
+func (e *ErrWatchTimeout) Error() string {
+	conditions := make([]string, len(e.Until))
+	for i, requirement := range e.Until {
+		conditions[i] = requirement.String()
+	}
+	return fmt.Sprintf("timed out after %s waiting for condition %s", e.Timeout, strings.Join(conditions, ","))
+}
+
+// Watch polls fetch every cmdCtx.Interval and calls onEvent for each resource added, modified or deleted
+// between two successive snapshots. The first snapshot reports every resource as ADDED.
+// It returns when the Until condition holds, when the timeout expires or when onEvent returns an error.
+// Without Until, the expiry of the timeout is the expected end of the watch and returns no error.
+// Errors from fetch are reported on stderr unless SilentErrors is set and the next poll is attempted.
+func Watch(kind schema.Kind, fetch func() ([]resource.Resource, []error), cmdCtx WatchHandlerContext, onEvent func(WatchEvent) error) error {
+	return watch(kind, fetch, cmdCtx, onEvent, time.Sleep, time.Now)
+}
+
+func watch(kind schema.Kind, fetch func() ([]resource.Resource, []error), cmdCtx WatchHandlerContext, onEvent func(WatchEvent) error, sleep func(time.Duration), now func() time.Time) error {
+	var previous []resource.Resource
+	start := now()
+	interval := cmdCtx.Interval
+	for {
+		current, errs := fetch()
+		if len(errs) > 0 {
+			if !cmdCtx.SilentErrors {
+				for _, err := range errs {
+					fmt.Fprintf(os.Stderr, "%s\n", err)
+				}
+			}
+		} else {
+			for _, event := range DiffSnapshots(kind, previous, current) {
+				err := onEvent(event)
+				if err != nil {
+					return err
+				}
+			}
+			previous = current
+			if len(cmdCtx.Until) > 0 && allMatch(cmdCtx.Until, current) {
+				return nil
+			}
+		}
+		elapsed := now().Sub(start)
+		if cmdCtx.Timeout > 0 {
+			if elapsed >= cmdCtx.Timeout {
+				if len(cmdCtx.Until) == 0 {
+					return nil
+				}
+				return &ErrWatchTimeout{Timeout: cmdCtx.Timeout, Until: cmdCtx.Until, LastSnapshot: previous}
+			}
+			// do not oversleep the timeout
+			interval = min(interval, cmdCtx.Timeout-elapsed)
+		}
+		sleep(interval)
+		if cmdCtx.MaxInterval > interval {
+			interval = min(interval*2, cmdCtx.MaxInterval)
+		}
+	}
+}
+
+// allMatch tells whether every resource matches the selector, false for no resources as the watched resources
+// are expected to exist, see WatchHandlerContext.Until.
+func allMatch(selector resource.Selector, resources []resource.Resource) bool {
+	if len(resources) == 0 {
+		return false
+	}
+	for _, res := range resources {
+		if !selector.Matches(res) {
+			return false
+		}
+	}
+	return true
+}
+
+// DiffSnapshots compares two lists of resources of the same kind by identity and returns the resulting events.
+// Events are sorted by type (ADDED, MODIFIED then DELETED) and by identity.
+func DiffSnapshots(kind schema.Kind, previous, current []resource.Resource) []WatchEvent {
+	previousByID := indexByIdentity(kind, previous)
+	currentByID := indexByIdentity(kind, current)
+	var added, modified, deleted []WatchEvent
+
+	for _, id := range sortedIdentities(currentByID) {
+		res := currentByID[id]
+		old, existed := previousByID[id]
+		if !existed {
+			added = append(added, WatchEvent{Type: Added, Resource: res})
+		} else if !sameContent(old, res) {
+			modified = append(modified, WatchEvent{Type: Modified, Resource: res})
+		}
+	}
+	for _, id := range sortedIdentities(previousByID) {
+		if _, exists := currentByID[id]; !exists {
+			deleted = append(deleted, WatchEvent{Type: Deleted, Resource: previousByID[id]})
+		}
+	}
+
+	events := make([]WatchEvent, 0, len(added)+len(modified)+len(deleted))
+	events = append(events, added...)
+	events = append(events, modified...)
+	return append(events, deleted...)
+}
+
+// ResourceIdentity identifies a resource of the given kind by its name and the metadata scoping it:
+// the parent path and query parameters of the kind and, for Gateway resources, the vCluster or scope.
+func ResourceIdentity(kind schema.Kind, res resource.Resource) string {
+	parts := []string{res.Kind}
+	var scopeKeys []string
+	scopeKeys = append(scopeKeys, kind.GetParentFlag()...)
+	scopeKeys = append(scopeKeys, kind.GetParentQueryFlag()...)
+	scopeKeys = append(scopeKeys, "vCluster", "scope", resource.GatewayInstanceKey)
+	for _, key := range scopeKeys {
+		if value, ok := res.Metadata[key]; ok && value != nil {
+			parts = append(parts, fmt.Sprintf("%s=%v", key, value))
+		}
+	}
+	parts = append(parts, res.Name)
+	return strings.Join(parts, "/")
+}
+
+func indexByIdentity(kind schema.Kind, resources []resource.Resource) map[string]resource.Resource {
+	result := make(map[string]resource.Resource, len(resources))
+	for _, res := range resources {
+		result[ResourceIdentity(kind, res)] = res
+	}
+	return result
+}
+
+func sortedIdentities(resources map[string]resource.Resource) []string {
+	keys := make([]string, 0, len(resources))
+	for key := range resources {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sameContent(a, b resource.Resource) bool {
+	var aData, bData interface{}
+	errA := json.Unmarshal(a.Json, &aData)
+	errB := json.Unmarshal(b.Json, &bData)
+	if errA != nil || errB != nil {
+		return string(a.Json) == string(b.Json)
+	}
+	return reflect.DeepEqual(aData, bData)
+}
@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/conduktor/ctl/pkg/resource"
+	"github.com/conduktor/ctl/pkg/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+var connectorKind = schema.NewKind(2, &schema.ConsoleKindVersion{
+	Name:            "Connector",
+	ListPath:        "/public/kafka/v2/cluster/{cluster}/connect/{connectCluster}/connector",
+	ParentPathParam: []string{"cluster", "connectCluster"},
+})
+
+func connector(name, cluster, state string) resource.Resource {
+	return resource.Resource{
+		Kind:     "Connector",
+		Name:     name,
+		Version:  "v2",
+		Metadata: map[string]interface{}{"name": name, "cluster": cluster, "connectCluster": "connect"},
+		Json:     []byte(fmt.Sprintf(`{"apiVersion":"v2","kind":"Connector","metadata":{"name":"%s","cluster":"%s","connectCluster":"connect"},"spec":{"state":"%s"}}`, name, cluster, state)),
+	}
+}
+
+func eventsAsString(events []WatchEvent) []string {
+	result := make([]string, len(events))
+	for i, event := range events {
+		result[i] = fmt.Sprintf("%s %s/%s", event.Type, event.Resource.Metadata["cluster"], event.Resource.Name)
+	}
+	return result
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	previous := []resource.Resource{
+		connector("a", "prod", "RUNNING"),
+		connector("b", "prod", "PAUSED"),
+		connector("c", "prod", "RUNNING"),
+	}
+	current := []resource.Resource{
+		connector("a", "prod", "RUNNING"),
+		connector("b", "prod", "RUNNING"),
+		connector("c", "staging", "RUNNING"),
+	}
+
+	events := DiffSnapshots(connectorKind, previous, current)
+
+	assert.Equal(t, []string{
+		"ADDED staging/c",
+		"MODIFIED prod/b",
+		"DELETED prod/c",
+	}, eventsAsString(events))
+
+	assert.Equal(t, []string{
+		"ADDED prod/a",
+		"ADDED prod/b",
+		"ADDED prod/c",
+	}, eventsAsString(DiffSnapshots(connectorKind, nil, previous)))
+}
+
+func TestWatchUntil(t *testing.T) {
+	snapshots := [][]resource.Resource{
+		{connector("a", "prod", "UNASSIGNED")},
+		{connector("a", "prod", "UNASSIGNED"), connector("b", "prod", "UNASSIGNED")},
+		nil, // error
+		{connector("a", "prod", "RUNNING"), connector("b", "prod", "UNASSIGNED")},
+		{connector("a", "prod", "RUNNING"), connector("b", "prod", "RUNNING")},
+		{connector("a", "prod", "RUNNING")},
+	}
+	polls := 0
+	fetch := func() ([]resource.Resource, []error) {
+		snapshot := snapshots[polls]
+		polls++
+		if snapshot == nil {
+			return nil, []error{fmt.Errorf("transient error")}
+		}
+		return snapshot, nil
+	}
+	var sleeps []time.Duration
+	sleep := func(d time.Duration) { sleeps = append(sleeps, d) }
+	until, err := resource.ParseFieldSelector("spec.state==RUNNING")
+	assert.NoError(t, err)
+
+	var events []WatchEvent
+	err = watch(connectorKind, fetch, WatchHandlerContext{Interval: time.Second, Until: until}, func(event WatchEvent) error {
+		events = append(events, event)
+		return nil
+	}, sleep, time.Now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 5, polls)
+	assert.Equal(t, []time.Duration{time.Second, time.Second, time.Second, time.Second}, sleeps)
+	assert.Equal(t, []string{
+		"ADDED prod/a",
+		"ADDED prod/b",
+		"MODIFIED prod/a",
+		"MODIFIED prod/b",
+	}, eventsAsString(events))
+}
+
+func TestWatchStopOnEventError(t *testing.T) {
+	fetch := func() ([]resource.Resource, []error) {
+		return []resource.Resource{connector("a", "prod", "RUNNING")}, nil
+	}
+	expectedErr := fmt.Errorf("broken pipe")
+	err := watch(connectorKind, fetch, WatchHandlerContext{Interval: time.Second}, func(event WatchEvent) error {
+		return expectedErr
+	}, func(time.Duration) {}, time.Now)
+	assert.Equal(t, expectedErr, err)
+}
+
+func TestWatchUntilEmptySnapshotTimesOut(t *testing.T) {
+	fetch := func() ([]resource.Resource, []error) {
+		return nil, nil
+	}
+	clock := time.Unix(0, 0)
+	now := func() time.Time { return clock }
+	sleep := func(d time.Duration) { clock = clock.Add(d) }
+	until, err := resource.ParseFieldSelector("spec.state==RUNNING")
+	assert.NoError(t, err)
+
+	err = watch(connectorKind, fetch, WatchHandlerContext{Interval: 2 * time.Second, Until: until, Timeout: 5 * time.Second}, func(event WatchEvent) error {
+		return nil
+	}, sleep, now)
+
+	var timeoutErr *ErrWatchTimeout
+	assert.ErrorAs(t, err, &timeoutErr)
+	assert.Equal(t, time.Unix(5, 0), clock)
+}
+
+func TestWatchTimeoutWithoutUntil(t *testing.T) {
+	fetch := func() ([]resource.Resource, []error) {
+		return []resource.Resource{connector("a", "prod", "RUNNING")}, nil
+	}
+	clock := time.Unix(0, 0)
+	now := func() time.Time { return clock }
+	sleep := func(d time.Duration) { clock = clock.Add(d) }
+
+	var events []WatchEvent
+	err := watch(connectorKind, fetch, WatchHandlerContext{Interval: time.Second, Timeout: 3 * time.Second}, func(event WatchEvent) error {
+		events = append(events, event)
+		return nil
+	}, sleep, now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ADDED prod/a"}, eventsAsString(events))
+	assert.Equal(t, time.Unix(3, 0), clock)
+}
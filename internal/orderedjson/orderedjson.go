@@ -29,6 +29,11 @@ func (orderedData *OrderedData) UnmarshalJSON(data []byte) error {
 	return err
 }
 
+// FromValue wraps a decoded JSON value, e.g. to replace a value of an ordered map.
+func FromValue(value interface{}) OrderedData {
+	return OrderedData{fallback: &value}
+}
+
 // TODO: remove once hack in printYaml is not needed anymore.
 func (orderedData *OrderedData) GetMapOrNil() *orderedmap.OrderedMap[string, OrderedData] {
 	return orderedData.orderedMap
@@ -6,5 +6,10 @@ import (
 )
 
 func CdkDebug() bool {
-	return strings.ToLower(os.Getenv("CDK_DEBUG")) == "true"
+	return CdkDebugFromEnvLookup(os.Getenv)
+}
+
+// CdkDebugFromEnvLookup tells whether CDK_DEBUG is set to true in the variables returned by getenv, e.g. read from an env file.
+func CdkDebugFromEnvLookup(getenv func(string) string) bool {
+	return strings.ToLower(getenv("CDK_DEBUG")) == "true"
 }
@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SplitCommandLine splits a command line into its arguments like a POSIX shell would, honoring single quotes,
+// double quotes and backslash escapes, without any expansion.
+func SplitCommandLine(commandLine string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+	for _, r := range commandLine {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\\':
+			escaped = true
+			inArg = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote in: %s", quote, commandLine)
+	}
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash in: %s", commandLine)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitCommandLine(t *testing.T) {
+	tests := map[string][]string{
+		"vault read -field=token secret/cdk":        {"vault", "read", "-field=token", "secret/cdk"},
+		`  helper  "with space" 'single "quoted"' `: {"helper", "with space", `single "quoted"`},
+		`a\ b "c\"d" ''`:                            {"a b", `c"d`, ""},
+		"":                                          nil,
+	}
+	for commandLine, expected := range tests {
+		args, err := SplitCommandLine(commandLine)
+		require.NoError(t, err, commandLine)
+		assert.Equal(t, expected, args, commandLine)
+	}
+
+	_, err := SplitCommandLine(`helper "unterminated`)
+	assert.Error(t, err)
+	_, err = SplitCommandLine(`helper \`)
+	assert.Error(t, err)
+}
@@ -3,110 +3,483 @@ package utils
 import (
 	"encoding/json"
 	"fmt"
+	"os"
+	"reflect"
 	"sort"
+	"strings"
 
 	"github.com/conduktor/ctl/pkg/resource"
 	"github.com/sergi/go-diff/diffmatchpatch"
 	"gopkg.in/yaml.v3"
 )
 
-// PrintDiff dispatches the diff operation for supported resource types
-// and prints the resulting diff to stdout.
-func PrintDiff(currentResource *resource.Resource, modifiedResource *resource.Resource) error {
-	txt, err := DiffResources(currentResource, modifiedResource)
+const DefaultDiffContext = 3
 
-	if err != nil {
-		return err
-	}
-	fmt.Printf("%s\n", txt)
-	return nil
-}
-
-// DiffResources compares two Resources objects and returns a unified diff in git-like format.
-func DiffResources(curRes, newRes *resource.Resource) (string, error) {
-	var curResObj, newResObj interface{}
-	var err error
+// Array ordering modes, declared per kind with the x-cdk-array-ordering catalog extension
+// as a map from the dotted path of an array (e.g. spec.members) to a mode. The "*" path sets the default of the kind.
+const (
+	// ArraySet arrays are compared regardless of the order of their elements, this is the default.
+	ArraySet = "set"
+	// ArrayOrdered arrays are compared element by element, e.g. an ordered chain of interceptors.
+	ArrayOrdered = "ordered"
+	// ArrayKeyPrefix arrays of objects are sorted by the value of a field, e.g. key:name.
+	ArrayKeyPrefix = "key:"
+)
 
-	isNotYetCreated := false
+type DiffOptions struct {
+	// Context is the number of unchanged lines shown around each change
+	Context int
+	// SourceLabel and TargetLabel are appended to the ---/+++ headers, live and local by default
+	SourceLabel string
+	TargetLabel string
+	// ArrayOrdering maps array paths to an ordering mode, see ArraySet, ArrayOrdered and ArrayKeyPrefix
+	ArrayOrdering map[string]string
+	// SensitiveFields are the dotted paths of the fields masked in the diff, see resource.MaskSensitiveFieldsPair
+	SensitiveFields []string
+}
 
-	// unmarshall the JSON data into a generic interface{}
-	err = json.Unmarshal(curRes.Json, &curResObj)
+// DiffResourcesWithOptions compares two resources and returns a unified diff with ---/+++ headers naming their kind and name.
+// Values resolved from secret references and sensitive fields are masked.
+// A current resource with invalid or empty JSON is considered not yet created, and a nil new resource deleted,
+// their header being /dev/null. An empty string is returned if they are identical.
+func DiffResourcesWithOptions(curRes, newRes *resource.Resource, options DiffOptions) (string, error) {
+	curRes, newRes = MaskSensitiveFieldsPair(curRes, newRes, options.SensitiveFields)
+	// a side with invalid JSON renders empty
+	curExists := curRes != nil && json.Valid(curRes.Json)
+	if !curExists {
+		curRes = nil
+	}
+	curYaml, err := NormalizedYaml(curRes, options.ArrayOrdering)
 	if err != nil {
-		isNotYetCreated = true
+		return "", err
+	}
+	newSide := newRes
+	if newSide != nil && !json.Valid(newSide.Json) {
+		newSide = nil
 	}
-	err = json.Unmarshal(newRes.Json, &newResObj)
+	newYaml, err := NormalizedYaml(newSide, options.ArrayOrdering)
 	if err != nil {
-		emptyRes := resource.Resource{}
-		_ = json.Unmarshal(emptyRes.Json, &newResObj)
+		return "", err
+	}
+
+	sourceLabel, targetLabel := options.SourceLabel, options.TargetLabel
+	if sourceLabel == "" {
+		sourceLabel = "live"
+	}
+	if targetLabel == "" {
+		targetLabel = "local"
 	}
+	named := newRes
+	if named == nil {
+		named = curRes
+	}
+	var name string
+	if named != nil {
+		name = named.Kind + "/" + named.Name
+	}
+	sourceHeader, targetHeader := name+"\t"+sourceLabel, name+"\t"+targetLabel
+	if !curExists {
+		sourceHeader = "/dev/null"
+	}
+	if newRes == nil {
+		targetHeader = "/dev/null"
+	}
+	lines := DiffLines(curYaml, newYaml)
+	return UnifiedDiff(sourceHeader, targetHeader, lines, options.Context), nil
+}
 
-	// recursively sort the maps and slices in generic interface{}
-	sortedCurRes := sortInterface(curResObj)
-	sortedNewRes := sortInterface(newResObj)
+// MaskSensitiveFieldsPair returns copies of both sides of a diff with their sensitive fields, and the fields resolved
+// from secret references of either side, masked, see resource.MaskSensitiveFieldsPair. A nil side stays nil.
+func MaskSensitiveFieldsPair(curRes, newRes *resource.Resource, paths []string) (*resource.Resource, *resource.Resource) {
+	var curJson, newJson []byte
+	if curRes != nil {
+		curJson = curRes.Json
+		paths = append(paths[:len(paths):len(paths)], curRes.SecretFields...)
+	}
+	if newRes != nil {
+		newJson = newRes.Json
+		paths = append(paths[:len(paths):len(paths)], newRes.SecretFields...)
+	}
+	if len(paths) == 0 {
+		return curRes, newRes
+	}
+	curJson, newJson = resource.MaskSensitiveFieldsPair(curJson, newJson, paths)
+	if curRes != nil {
+		masked := *curRes
+		masked.Json = curJson
+		curRes = &masked
+	}
+	if newRes != nil {
+		masked := *newRes
+		masked.Json = newJson
+		newRes = &masked
+	}
+	return curRes, newRes
+}
 
-	// Marshal both structs back to YAML
-	yamlCurRes, err := yaml.Marshal(sortedCurRes)
+// NormalizedYaml renders a resource as YAML with sorted keys and arrays ordered according to ordering, so that
+// two equivalent resources render the same. An empty string is returned for a resource without JSON, e.g. a missing one.
+func NormalizedYaml(res *resource.Resource, ordering map[string]string) (string, error) {
+	if res == nil || len(res.Json) == 0 {
+		return "", nil
+	}
+	var obj interface{}
+	err := json.Unmarshal(res.Json, &obj)
 	if err != nil {
 		return "", err
 	}
-	yamlNewRes, err := yaml.Marshal(sortedNewRes)
+	out, err := yaml.Marshal(NormalizeArrays(obj, ordering))
 	if err != nil {
 		return "", err
 	}
+	return string(out), nil
+}
+
+type DiffOperation byte
+
+const (
+	LineEqual  DiffOperation = ' '
+	LineDelete DiffOperation = '-'
+	LineInsert DiffOperation = '+'
+)
 
-	// Create a new diff instance
+type DiffLine struct {
+	Operation DiffOperation
+	Text      string
+}
+
+// DiffLines computes a line-based diff between two texts.
+func DiffLines(before, after string) []DiffLine {
 	dmp := diffmatchpatch.New()
+	beforeChars, afterChars, lines := dmp.DiffLinesToChars(before, after)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(beforeChars, afterChars, false), lines)
 
-	if isNotYetCreated {
-		yamlCurRes = []byte{0}
+	var result []DiffLine
+	for _, diff := range diffs {
+		operation := LineEqual
+		switch diff.Type {
+		case diffmatchpatch.DiffDelete:
+			operation = LineDelete
+		case diffmatchpatch.DiffInsert:
+			operation = LineInsert
+		}
+		for _, line := range strings.SplitAfter(diff.Text, "\n") {
+			if line != "" {
+				result = append(result, DiffLine{Operation: operation, Text: strings.TrimSuffix(line, "\n")})
+			}
+		}
 	}
-	// Generate unified diff
-	diffs := dmp.DiffMain(string(yamlCurRes), string(yamlNewRes), false)
+	return result
+}
 
-	// Check if there are any actual differences
-	hasChanges := false
-	for _, diff := range diffs {
-		if diff.Type != diffmatchpatch.DiffEqual {
-			hasChanges = true
-			break
+// UnifiedDiff formats diff lines as a unified diff with the given headers and number of context lines.
+// An empty string is returned if there is no change.
+func UnifiedDiff(sourceHeader, targetHeader string, lines []DiffLine, context int) string {
+	context = max(context, 0)
+	var hunks strings.Builder
+	// current hunk as a [start, end) range of lines, start is -1 when there is none
+	start, end := -1, -1
+	// number of source and target lines before the current line
+	sourceLine, targetLine := 0, 0
+	hunkSourceStart, hunkTargetStart := 0, 0
+	flush := func() {
+		if start < 0 {
+			return
+		}
+		sourceCount, targetCount := 0, 0
+		for _, line := range lines[start:end] {
+			if line.Operation != LineInsert {
+				sourceCount++
+			}
+			if line.Operation != LineDelete {
+				targetCount++
+			}
+		}
+		fmt.Fprintf(&hunks, "@@ -%s +%s @@\n", hunkRange(hunkSourceStart, sourceCount), hunkRange(hunkTargetStart, targetCount))
+		for _, line := range lines[start:end] {
+			hunks.WriteByte(byte(line.Operation))
+			hunks.WriteString(line.Text)
+			hunks.WriteString("\n")
 		}
+		start, end = -1, -1
 	}
 
-	// Return empty string if no changes
-	if !hasChanges {
-		return "", nil
+	for i, line := range lines {
+		if line.Operation != LineEqual {
+			hunkStart := max(i-context, 0)
+			if start >= 0 && hunkStart > end {
+				flush()
+			}
+			if start < 0 {
+				start = hunkStart
+				// the context lines before the change are equal lines
+				hunkSourceStart, hunkTargetStart = sourceLine-(i-hunkStart), targetLine-(i-hunkStart)
+			}
+			end = min(i+1+context, len(lines))
+		}
+		if line.Operation != LineInsert {
+			sourceLine++
+		}
+		if line.Operation != LineDelete {
+			targetLine++
+		}
+	}
+	flush()
+	if hunks.Len() == 0 {
+		return ""
 	}
+	return fmt.Sprintf("--- %s\n+++ %s\n", sourceHeader, targetHeader) + hunks.String()
+}
 
-	// Format the diff nicely
-	diffText := dmp.DiffPrettyText(diffs)
-	return "\n" + diffText, nil
+// hunkRange formats a hunk range from a 0-based start line, following the GNU diff conventions.
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, count)
+	}
 }
 
-func sortInterface(input interface{}) interface{} {
-	switch v := input.(type) {
-	case map[string]interface{}:
-		// Sort map keys
-		sortedMap := make(map[string]interface{})
-		keys := make([]string, 0, len(v))
-		for key := range v {
+// SideBySide renders diff lines in two columns of the given width. Changed lines are marked with |,
+// lines only on the left with < and lines only on the right with >.
+func SideBySide(lines []DiffLine, width int) string {
+	var sb strings.Builder
+	writeRow := func(left, marker, right string) {
+		row := fitColumn(left, width) + " " + marker + " " + fitColumn(right, width)
+		sb.WriteString(strings.TrimRight(row, " ") + "\n")
+	}
+	var deleted, inserted []string
+	flush := func() {
+		for i := 0; i < max(len(deleted), len(inserted)); i++ {
+			switch {
+			case i < len(deleted) && i < len(inserted):
+				writeRow(deleted[i], "|", inserted[i])
+			case i < len(deleted):
+				writeRow(deleted[i], "<", "")
+			default:
+				writeRow("", ">", inserted[i])
+			}
+		}
+		deleted, inserted = nil, nil
+	}
+	for _, line := range lines {
+		switch line.Operation {
+		case LineDelete:
+			deleted = append(deleted, line.Text)
+		case LineInsert:
+			inserted = append(inserted, line.Text)
+		default:
+			flush()
+			writeRow(line.Text, " ", line.Text)
+		}
+	}
+	flush()
+	return sb.String()
+}
+
+func fitColumn(text string, width int) string {
+	runes := []rune(text)
+	if len(runes) > width {
+		return string(runes[:width])
+	}
+	return text + strings.Repeat(" ", width-len(runes))
+}
+
+const (
+	FieldAdded    = "add"
+	FieldRemoved  = "remove"
+	FieldReplaced = "replace"
+)
+
+// FieldChange is the change of a single field, identified by its JSON pointer (RFC 6901).
+type FieldChange struct {
+	Op   string      `json:"op"`
+	Path string      `json:"path"`
+	Old  interface{} `json:"old"`
+	New  interface{} `json:"new"`
+}
+
+// DiffFields compares two resources field by field, after ordering their arrays according to ordering.
+func DiffFields(curRes, newRes *resource.Resource, ordering map[string]string) []FieldChange {
+	var curObj, newObj interface{}
+	if curRes != nil {
+		_ = json.Unmarshal(curRes.Json, &curObj)
+	}
+	if newRes != nil {
+		_ = json.Unmarshal(newRes.Json, &newObj)
+	}
+	var changes []FieldChange
+	diffFields("", NormalizeArrays(curObj, ordering), NormalizeArrays(newObj, ordering), &changes)
+	return changes
+}
+
+func diffFields(pointer string, before, after interface{}, changes *[]FieldChange) {
+	beforeMap, beforeIsMap := before.(map[string]interface{})
+	afterMap, afterIsMap := after.(map[string]interface{})
+	if beforeIsMap && afterIsMap {
+		keys := make([]string, 0, len(beforeMap)+len(afterMap))
+		for key := range beforeMap {
 			keys = append(keys, key)
 		}
+		for key := range afterMap {
+			if _, ok := beforeMap[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
 		sort.Strings(keys)
 		for _, key := range keys {
-			sortedMap[key] = sortInterface(v[key])
+			childPointer := pointer + "/" + escapeJSONPointer(key)
+			beforeValue, inBefore := beforeMap[key]
+			afterValue, inAfter := afterMap[key]
+			switch {
+			case !inBefore:
+				*changes = append(*changes, FieldChange{Op: FieldAdded, Path: childPointer, New: afterValue})
+			case !inAfter:
+				*changes = append(*changes, FieldChange{Op: FieldRemoved, Path: childPointer, Old: beforeValue})
+			default:
+				diffFields(childPointer, beforeValue, afterValue, changes)
+			}
 		}
-		return sortedMap
+		return
+	}
+	beforeSlice, beforeIsSlice := before.([]interface{})
+	afterSlice, afterIsSlice := after.([]interface{})
+	if beforeIsSlice && afterIsSlice {
+		for i := 0; i < max(len(beforeSlice), len(afterSlice)); i++ {
+			childPointer := fmt.Sprintf("%s/%d", pointer, i)
+			switch {
+			case i >= len(beforeSlice):
+				*changes = append(*changes, FieldChange{Op: FieldAdded, Path: childPointer, New: afterSlice[i]})
+			case i >= len(afterSlice):
+				*changes = append(*changes, FieldChange{Op: FieldRemoved, Path: childPointer, Old: beforeSlice[i]})
+			default:
+				diffFields(childPointer, beforeSlice[i], afterSlice[i], changes)
+			}
+		}
+		return
+	}
+	if !reflect.DeepEqual(before, after) {
+		*changes = append(*changes, FieldChange{Op: FieldReplaced, Path: pointer, Old: before, New: after})
+	}
+}
+
+func escapeJSONPointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+// NormalizeArrays orders the arrays of a decoded JSON value according to ordering.
+// Arrays without a rule follow the "*" rule, or are compared as sets if there is none.
+func NormalizeArrays(input interface{}, ordering map[string]string) interface{} {
+	return normalizeArrays(input, "", ordering)
+}
+
+func normalizeArrays(input interface{}, path string, ordering map[string]string) interface{} {
+	switch v := input.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			result[key] = normalizeArrays(value, childPath, ordering)
+		}
+		return result
 	case []interface{}:
-		// Sort slices
+		// elements of an array share the path of the array
 		for i := range v {
-			v[i] = sortInterface(v[i])
+			v[i] = normalizeArrays(v[i], path, ordering)
+		}
+		mode, ok := ordering[path]
+		if !ok {
+			mode, ok = ordering["*"]
+		}
+		if !ok {
+			mode = ArraySet
+		}
+		switch {
+		case mode == ArrayOrdered:
+		case strings.HasPrefix(mode, ArrayKeyPrefix):
+			field := strings.TrimPrefix(mode, ArrayKeyPrefix)
+			sort.SliceStable(v, func(i, j int) bool {
+				return fmt.Sprintf("%v", fieldValue(v[i], field)) < fmt.Sprintf("%v", fieldValue(v[j], field))
+			})
+		default:
+			sort.SliceStable(v, func(i, j int) bool {
+				return fmt.Sprintf("%v", v[i]) < fmt.Sprintf("%v", v[j])
+			})
 		}
-		sort.SliceStable(v, func(i, j int) bool {
-			return fmt.Sprintf("%v", v[i]) < fmt.Sprintf("%v", v[j])
-		})
 		return v
 	default:
 		// Return other types as-is
 		return v
 	}
 }
+
+func fieldValue(element interface{}, field string) interface{} {
+	if m, ok := element.(map[string]interface{}); ok {
+		return m[field]
+	}
+	return element
+}
+
+type ColorMode string
+
+const (
+	ColorAuto   ColorMode = "auto"
+	ColorAlways ColorMode = "always"
+	ColorNever  ColorMode = "never"
+)
+
+// ShouldColor tells whether to color the output written to file: always, never, or in auto mode
+// when file is a terminal and NO_COLOR is not set.
+func ShouldColor(mode ColorMode, file *os.File) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	default:
+		if os.Getenv("NO_COLOR") != "" {
+			return false
+		}
+		info, err := file.Stat()
+		return err == nil && info.Mode()&os.ModeCharDevice != 0
+	}
+}
+
+const (
+	ansiReset = "\x1b[0m"
+	ansiBold  = "\x1b[1m"
+	ansiRed   = "\x1b[31m"
+	ansiGreen = "\x1b[32m"
+	ansiCyan  = "\x1b[36m"
+)
+
+// ColorizeUnifiedDiff adds ANSI colors to a unified diff: bold headers, cyan hunk ranges, red deletions and green insertions.
+func ColorizeUnifiedDiff(diff string) string {
+	var sb strings.Builder
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		text := strings.TrimSuffix(line, "\n")
+		color := ""
+		switch {
+		case strings.HasPrefix(text, "--- ") || strings.HasPrefix(text, "+++ "):
+			color = ansiBold
+		case strings.HasPrefix(text, "@@"):
+			color = ansiCyan
+		case strings.HasPrefix(text, "-"):
+			color = ansiRed
+		case strings.HasPrefix(text, "+"):
+			color = ansiGreen
+		}
+		if color == "" {
+			sb.WriteString(line)
+		} else {
+			sb.WriteString(color + text + ansiReset + line[len(text):])
+		}
+	}
+	return sb.String()
+}
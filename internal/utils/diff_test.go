@@ -3,6 +3,8 @@ package utils
 import (
 	"encoding/json"
 	"fmt"
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/conduktor/ctl/pkg/resource"
@@ -10,7 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func TestDiffResources(t *testing.T) {
+func TestDiffResourcesWithOptions(t *testing.T) {
 	tests := []struct {
 		name        string
 		currentRes  *resource.Resource
@@ -165,7 +167,7 @@ func TestDiffResources(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := DiffResources(tt.currentRes, tt.modifiedRes)
+			result, err := DiffResourcesWithOptions(tt.currentRes, tt.modifiedRes, DiffOptions{Context: DefaultDiffContext})
 
 			assert.NoError(t, err)
 
@@ -179,162 +181,12 @@ func TestDiffResources(t *testing.T) {
 	}
 }
 
-func TestSortInterface(t *testing.T) {
-	tests := []struct {
-		name     string
-		input    interface{}
-		expected interface{}
-	}{
-		{
-			name:     "simple string",
-			input:    "hello",
-			expected: "hello",
-		},
-		{
-			name:     "simple number",
-			input:    42,
-			expected: 42,
-		},
-		{
-			name:     "simple boolean",
-			input:    true,
-			expected: true,
-		},
-		{
-			name: "simple map",
-			input: map[string]interface{}{
-				"z": "zebra",
-				"a": "apple",
-				"m": "mango",
-			},
-			expected: map[string]interface{}{
-				"a": "apple",
-				"m": "mango",
-				"z": "zebra",
-			},
-		},
-		{
-			name:     "simple slice of strings",
-			input:    []interface{}{"zebra", "apple", "mango"},
-			expected: []interface{}{"apple", "mango", "zebra"},
-		},
-		{
-			name:     "simple slice of numbers",
-			input:    []interface{}{3, 1, 2},
-			expected: []interface{}{1, 2, 3},
-		},
-		{
-			name: "nested map with arrays",
-			input: map[string]interface{}{
-				"z": []interface{}{3, 1, 2},
-				"a": []interface{}{"c", "a", "b"},
-			},
-			expected: map[string]interface{}{
-				"a": []interface{}{"a", "b", "c"},
-				"z": []interface{}{1, 2, 3},
-			},
-		},
-		{
-			name: "array of maps",
-			input: []interface{}{
-				map[string]interface{}{"name": "zebra", "value": 3},
-				map[string]interface{}{"name": "apple", "value": 1},
-			},
-			expected: []interface{}{
-				map[string]interface{}{"name": "apple", "value": 1},
-				map[string]interface{}{"name": "zebra", "value": 3},
-			},
-		},
-		{
-			name: "deeply nested structure",
-			input: map[string]interface{}{
-				"z": map[string]interface{}{
-					"nested": []interface{}{
-						map[string]interface{}{"id": 2, "name": "second"},
-						map[string]interface{}{"id": 1, "name": "first"},
-					},
-				},
-				"a": "simple",
-			},
-			expected: map[string]interface{}{
-				"a": "simple",
-				"z": map[string]interface{}{
-					"nested": []interface{}{
-						map[string]interface{}{"id": 1, "name": "first"},
-						map[string]interface{}{"id": 2, "name": "second"},
-					},
-				},
-			},
-		},
-		{
-			name:     "empty map",
-			input:    map[string]interface{}{},
-			expected: map[string]interface{}{},
-		},
-		{
-			name:     "empty slice",
-			input:    []interface{}{},
-			expected: []interface{}{},
-		},
-		{
-			name:     "nil input",
-			input:    nil,
-			expected: nil,
-		},
-		{
-			name: "mixed types in slice",
-			input: []interface{}{
-				"string",
-				42,
-				map[string]interface{}{"key": "value"},
-				true,
-			},
-			expected: []interface{}{
-				42,
-				map[string]interface{}{"key": "value"},
-				"string",
-				true,
-			},
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := sortInterface(tt.input)
-
-			// For complex structures, we'll compare JSON representations
-			// since assert.Equal might not work well with nested interfaces
-			if isComplexType(tt.input) {
-				expectedJSON, err := json.Marshal(tt.expected)
-				require.NoError(t, err)
-
-				resultJSON, err := json.Marshal(result)
-				require.NoError(t, err)
-
-				assert.JSONEq(t, string(expectedJSON), string(resultJSON))
-			} else {
-				assert.Equal(t, tt.expected, result)
-			}
-		})
-	}
-}
-
-// Helper function to determine if we need JSON comparison.
-func isComplexType(input interface{}) bool {
-	switch input.(type) {
-	case map[string]interface{}, []interface{}:
-		return true
-	default:
-		return false
-	}
-}
-
 func TestDiffResourcesEdgeCases(t *testing.T) {
 	t.Run("empty JSON bytes", func(t *testing.T) {
 		currentRes := &resource.Resource{Json: []byte("")}
 		modifiedRes := &resource.Resource{Json: []byte("")}
 
-		result, err := DiffResources(currentRes, modifiedRes)
+		result, err := DiffResourcesWithOptions(currentRes, modifiedRes, DiffOptions{Context: DefaultDiffContext})
 		assert.NoError(t, err)
 		// Both should be treated as invalid/empty, so minimal diff
 		assert.NotNil(t, result)
@@ -353,8 +205,167 @@ func TestDiffResourcesEdgeCases(t *testing.T) {
 		currentRes := &resource.Resource{Json: largeJSON}
 		modifiedRes := &resource.Resource{Json: largeJSON}
 
-		result, err := DiffResources(currentRes, modifiedRes)
+		result, err := DiffResourcesWithOptions(currentRes, modifiedRes, DiffOptions{Context: DefaultDiffContext})
 		assert.NoError(t, err)
 		assert.NotNil(t, result)
 	})
 }
+
+func TestDiffLines(t *testing.T) {
+	lines := DiffLines("a\nb\nc\n", "a\nB\nc\nd\n")
+	assert.Equal(t, []DiffLine{
+		{Operation: LineEqual, Text: "a"},
+		{Operation: LineDelete, Text: "b"},
+		{Operation: LineInsert, Text: "B"},
+		{Operation: LineEqual, Text: "c"},
+		{Operation: LineInsert, Text: "d"},
+	}, lines)
+
+	assert.Empty(t, DiffLines("", ""))
+}
+
+func TestNormalizedYaml(t *testing.T) {
+	a, err := NormalizedYaml(&resource.Resource{Json: []byte(`{"spec":{"b":1,"a":[2,1]},"kind":"Topic"}`)}, nil)
+	require.NoError(t, err)
+	b, err := NormalizedYaml(&resource.Resource{Json: []byte(`{"kind":"Topic","spec":{"a":[1,2],"b":1}}`)}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, a, b)
+
+	empty, err := NormalizedYaml(nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "", empty)
+}
+
+func TestSideBySide(t *testing.T) {
+	lines := DiffLines("a: 1\nb: 2\nc: 3\n", "a: 1\nb: 20\nd: 4\ne: 5\n")
+	expected := "" +
+		"a: 1    a: 1\n" +
+		"b: 2  | b: 20\n" +
+		"c: 3  | d: 4\n" +
+		"      > e: 5\n"
+	assert.Equal(t, expected, SideBySide(lines, 5))
+
+	assert.Equal(t, "long_ <\n", SideBySide([]DiffLine{{Operation: LineDelete, Text: "long_line"}}, 5))
+}
+
+func TestDiffResourcesUnifiedFormat(t *testing.T) {
+	current := &resource.Resource{Kind: "Topic", Name: "orders", Json: []byte(`{"kind":"Topic","metadata":{"name":"orders"},"spec":{"partitions":3,"replicationFactor":2}}`)}
+	modified := &resource.Resource{Kind: "Topic", Name: "orders", Json: []byte(`{"kind":"Topic","metadata":{"name":"orders"},"spec":{"partitions":6,"replicationFactor":2}}`)}
+
+	result, err := DiffResourcesWithOptions(current, modified, DiffOptions{Context: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "--- Topic/orders\tlive\n"+
+		"+++ Topic/orders\tlocal\n"+
+		"@@ -4,3 +4,3 @@\n"+
+		" spec:\n"+
+		"-    partitions: 3\n"+
+		"+    partitions: 6\n"+
+		"     replicationFactor: 2\n", result)
+
+	result, err = DiffResourcesWithOptions(&resource.Resource{}, modified, DiffOptions{Context: DefaultDiffContext})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(result, "--- /dev/null\n+++ Topic/orders\tlocal\n@@ -0,0 +1,6 @@\n"), result)
+
+	result, err = DiffResourcesWithOptions(current, nil, DiffOptions{Context: 1})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(result, "--- Topic/orders\tlive\n+++ /dev/null\n@@ -1,6 +0,0 @@\n"), result)
+}
+
+func TestUnifiedDiffHunks(t *testing.T) {
+	before := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n"
+	after := "1\nII\n3\n4\n5\n6\n7\n8\nIX\n10\n"
+	lines := DiffLines(before, after)
+
+	// changes far enough apart produce two hunks
+	assert.Equal(t, "--- a\n+++ b\n"+
+		"@@ -1,3 +1,3 @@\n 1\n-2\n+II\n 3\n"+
+		"@@ -8,3 +8,3 @@\n 8\n-9\n+IX\n 10\n", UnifiedDiff("a", "b", lines, 1))
+
+	// overlapping context merges them
+	assert.Equal(t, "--- a\n+++ b\n"+
+		"@@ -1,10 +1,10 @@\n 1\n-2\n+II\n 3\n 4\n 5\n 6\n 7\n 8\n-9\n+IX\n 10\n", UnifiedDiff("a", "b", lines, 3))
+
+	assert.Equal(t, "", UnifiedDiff("a", "b", DiffLines(before, before), 3))
+}
+
+func TestNormalizeArrays(t *testing.T) {
+	input := func() interface{} {
+		return map[string]interface{}{
+			"spec": map[string]interface{}{
+				"chain":   []interface{}{"b", "a"},
+				"members": []interface{}{"y", "x"},
+				"acls": []interface{}{
+					map[string]interface{}{"name": "b", "op": "READ"},
+					map[string]interface{}{"name": "a", "op": "WRITE"},
+				},
+			},
+		}
+	}
+
+	sorted := NormalizeArrays(input(), nil).(map[string]interface{})["spec"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"a", "b"}, sorted["chain"])
+	assert.Equal(t, []interface{}{"x", "y"}, sorted["members"])
+
+	ordering := map[string]string{"spec.chain": ArrayOrdered, "spec.acls": "key:name", "*": ArraySet}
+	normalized := NormalizeArrays(input(), ordering).(map[string]interface{})["spec"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"b", "a"}, normalized["chain"])
+	assert.Equal(t, []interface{}{"x", "y"}, normalized["members"])
+	assert.Equal(t, "a", normalized["acls"].([]interface{})[0].(map[string]interface{})["name"])
+
+	normalized = NormalizeArrays(input(), map[string]string{"*": ArrayOrdered}).(map[string]interface{})["spec"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"y", "x"}, normalized["members"])
+}
+
+func TestDiffFields(t *testing.T) {
+	current := &resource.Resource{Json: []byte(`{"metadata":{"labels":{"a/b":"1"}},"spec":{"partitions":3,"configs":{"retention.ms":"100"},"chain":["x","y"],"old":true}}`)}
+	modified := &resource.Resource{Json: []byte(`{"metadata":{"labels":{"a/b":"2"}},"spec":{"partitions":6,"configs":{"retention.ms":"100","cleanup.policy":"compact"},"chain":["y","x","z"]}}`)}
+
+	changes := DiffFields(current, modified, map[string]string{"spec.chain": ArrayOrdered})
+	assert.Equal(t, []FieldChange{
+		{Op: FieldReplaced, Path: "/metadata/labels/a~1b", Old: "1", New: "2"},
+		{Op: FieldReplaced, Path: "/spec/chain/0", Old: "x", New: "y"},
+		{Op: FieldReplaced, Path: "/spec/chain/1", Old: "y", New: "x"},
+		{Op: FieldAdded, Path: "/spec/chain/2", New: "z"},
+		{Op: FieldAdded, Path: "/spec/configs/cleanup.policy", New: "compact"},
+		{Op: FieldRemoved, Path: "/spec/old", Old: true},
+		{Op: FieldReplaced, Path: "/spec/partitions", Old: float64(3), New: float64(6)},
+	}, changes)
+
+	assert.Empty(t, DiffFields(current, current, nil))
+}
+
+func TestColorizeUnifiedDiff(t *testing.T) {
+	diff := "--- a\n+++ b\n@@ -1 +1 @@\n-x\n+y\n z\n"
+	assert.Equal(t, "\x1b[1m--- a\x1b[0m\n\x1b[1m+++ b\x1b[0m\n\x1b[36m@@ -1 +1 @@\x1b[0m\n\x1b[31m-x\x1b[0m\n\x1b[32m+y\x1b[0m\n z\n", ColorizeUnifiedDiff(diff))
+
+	assert.True(t, ShouldColor(ColorAlways, os.Stdout))
+	assert.False(t, ShouldColor(ColorNever, os.Stdout))
+	t.Setenv("NO_COLOR", "1")
+	assert.False(t, ShouldColor(ColorAuto, os.Stdout))
+}
+
+func TestDiffResourcesShouldMaskSecrets(t *testing.T) {
+	resource.RegisterSecretResolver("diff", resource.SecretResolverFunc(func(reference string) (string, error) {
+		return "diff-secret", nil
+	}))
+	resources, err := resource.FromYamlByte([]byte("kind: KafkaCluster\nmetadata:\n  name: prod\nspec:\n  password: ${diff:prod}\n"), true)
+	require.NoError(t, err)
+	current := &resource.Resource{Kind: "KafkaCluster", Name: "prod", Json: []byte(`{"kind":"KafkaCluster","metadata":{"name":"prod"},"spec":{"password":"old"}}`)}
+
+	result, err := DiffResourcesWithOptions(current, &resources[0], DiffOptions{Context: DefaultDiffContext})
+	require.NoError(t, err)
+	assert.NotContains(t, result, "diff-secret")
+	assert.NotContains(t, result, "old")
+	assert.Contains(t, result, "+    password: '*** (after)'\n")
+}
+
+func TestDiffResourcesShouldMaskSensitiveFields(t *testing.T) {
+	current := &resource.Resource{Kind: "KafkaCluster", Name: "prod", Json: []byte(`{"kind":"KafkaCluster","metadata":{"name":"prod"},"spec":{"properties":{"sasl.jaas.config":"old-jaas"},"user":"admin"}}`)}
+	next := &resource.Resource{Kind: "KafkaCluster", Name: "prod", Json: []byte(`{"kind":"KafkaCluster","metadata":{"name":"prod"},"spec":{"properties":{"sasl.jaas.config":"new-jaas"},"user":"admin"}}`)}
+
+	result, err := DiffResourcesWithOptions(current, next, DiffOptions{Context: DefaultDiffContext, SensitiveFields: []string{"spec.properties.sasl.jaas.config"}})
+	require.NoError(t, err)
+	assert.NotContains(t, result, "-jaas")
+	assert.Contains(t, result, "-        sasl.jaas.config: '*** (before)'\n")
+	assert.Contains(t, result, "+        sasl.jaas.config: '*** (after)'\n")
+}
@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadEnvFile(t *testing.T) {
+	path := t.TempDir() + "/prod.env"
+	require.NoError(t, os.WriteFile(path, []byte("# prod\nCDK_BASE_URL=https://prod\nexport CDK_API_KEY=\"secret\"\n\nCDK_GATEWAY_USER='admin'\n"), 0600))
+
+	env, err := ReadEnvFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"CDK_BASE_URL":     "https://prod",
+		"CDK_API_KEY":      "secret",
+		"CDK_GATEWAY_USER": "admin",
+	}, env)
+
+	require.NoError(t, os.WriteFile(path, []byte("CDK_BASE_URL\n"), 0600))
+	_, err = ReadEnvFile(path)
+	assert.Error(t, err)
+}
@@ -101,3 +101,17 @@ func GetDataDir() (string, error) {
 
 	return dataDir, nil
 }
+
+// GetCacheDir returns the path to the cache directory for the application based on the OS conventions,
+// e.g. $XDG_CACHE_HOME/conduktor on Linux.
+func GetCacheDir() (string, error) {
+	userCacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	cacheDir := filepath.Join(userCacheDir, AppName)
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return "", err
+	}
+	return cacheDir, nil
+}
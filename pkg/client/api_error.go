@@ -1,5 +1,10 @@
 package client
 
+import "errors"
+
+// ErrResourceNotFound is returned by GetFromResource when the server does not know the resource.
+var ErrResourceNotFound = errors.New("could not find any matching resource")
+
 type APIError struct {
 	Title string `json:"title"`
 	Msg   string `json:"msg"`
@@ -0,0 +1,284 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/conduktor/ctl/internal/utils"
+	"github.com/conduktor/ctl/pkg/schema"
+)
+
+// DefaultCatalogTTL is how long a cached catalog is used before being downloaded again, see CDK_CATALOG_TTL.
+const DefaultCatalogTTL = 24 * time.Hour
+
+// CatalogSource tells where the catalog of a client comes from.
+type CatalogSource string
+
+const (
+	// CatalogLive is downloaded from the server by this invocation
+	CatalogLive CatalogSource = "live"
+	// CatalogCached was downloaded from the server by a previous invocation
+	CatalogCached CatalogSource = "cached"
+	// CatalogEmbedded is the offline default built in the CLI
+	CatalogEmbedded CatalogSource = "embedded"
+)
+
+// CatalogOptions controls how a client gets its catalog.
+type CatalogOptions struct {
+	// Offline never downloads the catalog, using the cached one whatever its age, or the embedded one
+	Offline bool
+	// TTL is how long a cached catalog is used, 0 disables the cache
+	TTL time.Duration
+}
+
+// CatalogOptionsFromEnv reads CDK_OFFLINE and CDK_CATALOG_TTL, e.g. 1h or 0 to disable the cache.
+func CatalogOptionsFromEnv(getenv func(string) string) (CatalogOptions, error) {
+	options := CatalogOptions{
+		Offline: strings.ToLower(getenv("CDK_OFFLINE")) == "true",
+		TTL:     DefaultCatalogTTL,
+	}
+	if ttl := getenv("CDK_CATALOG_TTL"); ttl != "" {
+		duration, err := time.ParseDuration(ttl)
+		if err != nil || duration < 0 {
+			return options, fmt.Errorf("invalid CDK_CATALOG_TTL %s, expected a duration like 1h", ttl)
+		}
+		options.TTL = duration
+	}
+	return options, nil
+}
+
+// CatalogInfo describes the catalog in use by a client.
+type CatalogInfo struct {
+	Source  CatalogSource
+	BaseURL string
+	// ServerVersion is the version of the API the catalog was built from, empty for the embedded catalog
+	ServerVersion string
+	// FetchedAt is when the catalog was downloaded, zero for the embedded catalog
+	FetchedAt time.Time
+	CacheFile string
+	TTL       time.Duration
+	Offline   bool
+	// Error tells why the live catalog is not in use, nil if it was not needed
+	Error error
+}
+
+// refreshable returns true if the catalog comes from a cache that can be downloaded again, i.e. not offline
+// and not because the server could not be reached.
+func (i CatalogInfo) refreshable() bool {
+	return i.Source == CatalogCached && !i.Offline && i.Error == nil
+}
+
+// Age returns how long ago the catalog was downloaded, 0 for the embedded catalog.
+func (i CatalogInfo) Age() time.Duration {
+	if i.FetchedAt.IsZero() {
+		return 0
+	}
+	return time.Since(i.FetchedAt)
+}
+
+type catalogCacheEntry struct {
+	BaseURL       string          `json:"baseUrl"`
+	ServerVersion string          `json:"serverVersion"`
+	CLIVersion    string          `json:"cliVersion"`
+	FetchedAt     time.Time       `json:"fetchedAt"`
+	Catalog       json.RawMessage `json:"catalog"`
+}
+
+// catalogBackend gets the catalog of a Console or a Gateway.
+type catalogBackend struct {
+	name     string
+	baseURL  string
+	fetch    func() ([]byte, error)
+	build    func(parser *schema.OpenAPIParser) (*schema.Catalog, error)
+	fromJSON func([]byte) (*schema.Catalog, error)
+	embedded func() *schema.Catalog
+}
+
+// consoleCatalogBackend returns the backend of the catalog of a Console, fetch being set by the client reaching it.
+func consoleCatalogBackend(baseURL string) catalogBackend {
+	return catalogBackend{
+		name:    "console",
+		baseURL: baseURL,
+		build: func(parser *schema.OpenAPIParser) (*schema.Catalog, error) {
+			strict := false
+			return parser.GetConsoleCatalog(strict)
+		},
+		fromJSON: schema.ConsoleCatalogFromJSON,
+		embedded: schema.ConsoleDefaultCatalog,
+	}
+}
+
+// gatewayCatalogBackend returns the backend of the catalog of a Gateway, fetch being set by the client reaching it.
+func gatewayCatalogBackend(baseURL string) catalogBackend {
+	return catalogBackend{
+		name:    "gateway",
+		baseURL: baseURL,
+		build: func(parser *schema.OpenAPIParser) (*schema.Catalog, error) {
+			strict := false
+			return parser.GetGatewayCatalog(strict)
+		},
+		fromJSON: schema.GatewayCatalogFromJSON,
+		embedded: schema.GatewayDefaultCatalog,
+	}
+}
+
+// LocalConsoleCatalog returns the catalog cached for the Console of CDK_BASE_URL whatever its age, or the embedded one.
+// It never sends a request, so that the commands can be registered without waiting for the Console.
+func LocalConsoleCatalog(getenv func(string) string) (*schema.Catalog, CatalogInfo) {
+	baseURL := getenv("CDK_BASE_URL")
+	if baseURL != "" {
+		baseURL = uniformizeBaseURL(baseURL)
+	}
+	return consoleCatalogBackend(baseURL).local(getenv)
+}
+
+// LocalGatewayCatalog returns the catalog cached for the Gateway of CDK_GATEWAY_BASE_URL whatever its age, or the embedded one.
+// It never sends a request, so that the commands can be registered without waiting for the Gateway.
+func LocalGatewayCatalog(getenv func(string) string) (*schema.Catalog, CatalogInfo) {
+	return gatewayCatalogBackend(getenv("CDK_GATEWAY_BASE_URL")).local(getenv)
+}
+
+func (b catalogBackend) local(getenv func(string) string) (*schema.Catalog, CatalogInfo) {
+	options, err := CatalogOptionsFromEnv(getenv)
+	if b.baseURL == "" || err != nil || (options.TTL == 0 && !options.Offline) {
+		return b.embedded(), CatalogInfo{Source: CatalogEmbedded, BaseURL: b.baseURL, Error: err}
+	}
+	options.Offline = true
+	return b.load(options)
+}
+
+// catalogCacheFile returns the file caching the catalog of a backend, named after a hash of its base URL.
+func catalogCacheFile(backend, baseURL string) (string, error) {
+	cacheDir, err := utils.GetCacheDir()
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256([]byte(baseURL))
+	return filepath.Join(cacheDir, "catalogs", backend+"-"+hex.EncodeToString(hash[:8])+".json"), nil
+}
+
+// load returns the catalog cached if fresh, the live one otherwise, cached for the next invocations.
+// If the server cannot be reached, the cached catalog is used whatever its age, or the embedded one.
+func (b catalogBackend) load(options CatalogOptions) (*schema.Catalog, CatalogInfo) {
+	info := CatalogInfo{BaseURL: b.baseURL, TTL: options.TTL, Offline: options.Offline}
+	cacheFile, err := catalogCacheFile(b.name, b.baseURL)
+	if err != nil {
+		info.Error = err
+	}
+	info.CacheFile = cacheFile
+	var cached *schema.Catalog
+	var entry catalogCacheEntry
+	if cacheFile != "" && (options.TTL > 0 || options.Offline) {
+		entry, cached, err = b.readCache(cacheFile)
+		if err == nil && (options.Offline || time.Since(entry.FetchedAt) < options.TTL) {
+			return cached, b.cachedInfo(info, entry)
+		}
+	}
+	if options.Offline {
+		info.Source = CatalogEmbedded
+		return b.embedded(), info
+	}
+
+	catalog, info, err := b.refresh(info, options.TTL > 0)
+	if err == nil {
+		return catalog, info
+	}
+	info.Error = err
+	if cached != nil {
+		return cached, b.cachedInfo(info, entry)
+	}
+	info.Source = CatalogEmbedded
+	return b.embedded(), info
+}
+
+// refresh downloads the catalog, and caches it if cache is true.
+func (b catalogBackend) refresh(info CatalogInfo, cache bool) (*schema.Catalog, CatalogInfo, error) {
+	data, err := b.fetch()
+	if err != nil {
+		return nil, info, fmt.Errorf("Cannot get openapi: %s", err)
+	}
+	parser, err := schema.NewOpenAPIParser(data)
+	if err != nil {
+		return nil, info, fmt.Errorf("Cannot parse openapi: %s", err)
+	}
+	catalog, err := b.build(parser)
+	if err != nil {
+		return nil, info, fmt.Errorf("Cannot extract schemaCatalog from openapi: %s", err)
+	}
+	info.Source = CatalogLive
+	info.ServerVersion = parser.Version()
+	info.FetchedAt = time.Now()
+	if cache && info.CacheFile != "" {
+		err = writeCatalogCache(info, catalog)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: cannot cache the catalog of %s: %s\n", b.baseURL, err)
+		}
+	}
+	return catalog, info, nil
+}
+
+func (b catalogBackend) readCache(cacheFile string) (catalogCacheEntry, *schema.Catalog, error) {
+	var entry catalogCacheEntry
+	data, err := os.ReadFile(cacheFile)
+	if err != nil {
+		return entry, nil, err
+	}
+	err = json.Unmarshal(data, &entry)
+	if err != nil {
+		return entry, nil, err
+	}
+	// another version of the CLI can build another catalog from the same API
+	if entry.BaseURL != b.baseURL || entry.CLIVersion != utils.GetConduktorVersion() {
+		return entry, nil, fmt.Errorf("catalog cached for another base URL or CLI version")
+	}
+	catalog, err := b.fromJSON(entry.Catalog)
+	return entry, catalog, err
+}
+
+func (b catalogBackend) cachedInfo(info CatalogInfo, entry catalogCacheEntry) CatalogInfo {
+	info.Source = CatalogCached
+	info.ServerVersion = entry.ServerVersion
+	info.FetchedAt = entry.FetchedAt
+	return info
+}
+
+func writeCatalogCache(info CatalogInfo, catalog *schema.Catalog) error {
+	data, err := json.Marshal(catalog)
+	if err != nil {
+		return err
+	}
+	entry, err := json.Marshal(catalogCacheEntry{
+		BaseURL:       info.BaseURL,
+		ServerVersion: info.ServerVersion,
+		CLIVersion:    utils.GetConduktorVersion(),
+		FetchedAt:     info.FetchedAt,
+		Catalog:       data,
+	})
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(info.CacheFile), 0755)
+	if err != nil {
+		return err
+	}
+	// write then rename so that concurrent invocations never read a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(info.CacheFile), filepath.Base(info.CacheFile)+".*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(entry)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), info.CacheFile)
+}
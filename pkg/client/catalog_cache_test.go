@@ -0,0 +1,164 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+const catalogOpenAPI = `openapi: 3.0.3
+info:
+  title: Conduktor Public API
+  version: 1.30.0
+paths:
+  /public/kafka/v2/cluster/{cluster}/topic:
+    get:
+      tags: [cli_topic_kafka_v2_12]
+      parameters:
+        - {name: cluster, in: path, required: true, schema: {type: string}}
+      responses: {'200': {description: ''}}
+    put:
+      tags: [cli_topic_kafka_v2_12]
+      parameters:
+        - {name: cluster, in: path, required: true, schema: {type: string}}
+      requestBody:
+        content:
+          application/json:
+            schema: {type: object}
+      responses: {'200': {description: ''}}
+`
+
+func testCatalogBackend(fetches *int, fail *bool) catalogBackend {
+	backend := consoleCatalogBackend("http://console/api")
+	backend.fetch = func() ([]byte, error) {
+		*fetches++
+		if *fail {
+			return nil, errors.New("connection refused")
+		}
+		return []byte(catalogOpenAPI), nil
+	}
+	return backend
+}
+
+func TestCatalogCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	fetches, fail := 0, false
+	backend := testCatalogBackend(&fetches, &fail)
+	options := CatalogOptions{TTL: time.Hour}
+
+	catalog, info := backend.load(options)
+	if info.Source != CatalogLive || info.ServerVersion != "1.30.0" || fetches != 1 {
+		t.Fatalf("Expected the live catalog to be downloaded got %+v after %d fetches", info, fetches)
+	}
+	if _, ok := catalog.Kind["Topic"]; !ok || len(catalog.Kind) != 1 {
+		t.Errorf("Expected the catalog of the API got %v", catalog.Kind)
+	}
+
+	catalog, info = backend.load(options)
+	if info.Source != CatalogCached || info.ServerVersion != "1.30.0" || fetches != 1 {
+		t.Fatalf("Expected the cached catalog to be used got %+v after %d fetches", info, fetches)
+	}
+	topic := catalog.Kind["Topic"]
+	if len(topic.GetParentFlag()) != 1 {
+		t.Errorf("Expected the cached catalog to keep the kinds got %v", catalog.Kind)
+	}
+
+	// an expired cache is used when the server cannot be reached
+	fail = true
+	_, info = backend.load(CatalogOptions{TTL: time.Nanosecond})
+	if info.Source != CatalogCached || info.Error == nil || fetches != 2 {
+		t.Errorf("Expected the expired cached catalog to be used got %+v after %d fetches", info, fetches)
+	}
+
+	_, info = backend.load(CatalogOptions{Offline: true})
+	if info.Source != CatalogCached || fetches != 2 {
+		t.Errorf("Expected the cached catalog to be used offline got %+v after %d fetches", info, fetches)
+	}
+
+	err := os.Remove(info.CacheFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	catalog, info = backend.load(CatalogOptions{Offline: true})
+	if info.Source != CatalogEmbedded || fetches != 2 || len(catalog.Kind) < 2 {
+		t.Errorf("Expected the embedded catalog to be used offline without cache got %+v after %d fetches", info, fetches)
+	}
+}
+
+func TestLocalConsoleCatalog(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	fetches, fail := 0, false
+	env := map[string]string{"CDK_BASE_URL": "http://console"}
+	getenv := func(name string) string { return env[name] }
+
+	_, info := LocalConsoleCatalog(getenv)
+	if info.Source != CatalogEmbedded {
+		t.Errorf("Expected the embedded catalog without cache got %+v", info)
+	}
+
+	_, _ = testCatalogBackend(&fetches, &fail).load(CatalogOptions{TTL: time.Nanosecond})
+	catalog, info := LocalConsoleCatalog(getenv)
+	if info.Source != CatalogCached || len(catalog.Kind) != 1 {
+		t.Errorf("Expected the expired cached catalog to be used got %+v", info)
+	}
+
+	env["CDK_CATALOG_TTL"] = "0"
+	_, info = LocalConsoleCatalog(getenv)
+	if info.Source != CatalogEmbedded || fetches != 1 {
+		t.Errorf("Expected the embedded catalog when the cache is disabled got %+v after %d fetches", info, fetches)
+	}
+}
+
+func TestCatalogOptionsFromEnv(t *testing.T) {
+	env := map[string]string{"CDK_OFFLINE": "TRUE", "CDK_CATALOG_TTL": "90m"}
+	options, err := CatalogOptionsFromEnv(func(name string) string { return env[name] })
+	if err != nil || !options.Offline || options.TTL != 90*time.Minute {
+		t.Errorf("Unexpected options %+v %v", options, err)
+	}
+	env = map[string]string{"CDK_CATALOG_TTL": "one day"}
+	_, err = CatalogOptionsFromEnv(func(name string) string { return env[name] })
+	if err == nil {
+		t.Error("Expected an invalid CDK_CATALOG_TTL to fail")
+	}
+}
+
+func TestRefreshCachedCatalog(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	serverVersion := "1.30.0"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.Replace(catalogOpenAPI, "1.30.0", serverVersion, 1)))
+	}))
+	defer server.Close()
+	makeClient := func(options CatalogOptions) *Client {
+		client, err := Make(APIParameter{BaseURL: server.URL, APIKey: "key", Catalog: options})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return client
+	}
+
+	makeClient(CatalogOptions{TTL: time.Hour})
+	serverVersion = "1.31.0"
+	client := makeClient(CatalogOptions{TTL: time.Hour})
+	if info := client.CatalogInfo(); info.Source != CatalogCached || info.ServerVersion != "1.30.0" {
+		t.Fatalf("Expected the catalog of the previous version to be cached got %+v", info)
+	}
+	if !client.RefreshCachedCatalog() {
+		t.Error("Expected the cached catalog to be refreshed")
+	}
+	if info := client.CatalogInfo(); info.Source != CatalogLive || info.ServerVersion != "1.31.0" {
+		t.Errorf("Expected the catalog of the upgraded server got %+v", info)
+	}
+	if client.RefreshCachedCatalog() {
+		t.Error("Expected a live catalog not to be downloaded again")
+	}
+
+	offline := makeClient(CatalogOptions{Offline: true})
+	if offline.CatalogInfo().Source != CatalogCached || offline.RefreshCachedCatalog() {
+		t.Errorf("Expected the cached catalog to be kept offline got %+v", offline.CatalogInfo())
+	}
+}
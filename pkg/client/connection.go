@@ -0,0 +1,33 @@
+package client
+
+// Connection describes how a client reaches its API, e.g. to hand it to a plugin.
+type Connection struct {
+	BaseURL string `json:"baseUrl"`
+	// Authorization is the Authorization header sent to the API, empty if the client has no credentials
+	Authorization string `json:"authorization,omitempty"`
+	Cacert        string `json:"cacert,omitempty"`
+	Cert          string `json:"cert,omitempty"`
+	Key           string `json:"key,omitempty"`
+	Insecure      bool   `json:"insecure,omitempty"`
+}
+
+// Connection returns the base URL, TLS settings and Authorization header of the client, logging in with the
+// cached session if needed, without Authorization if it has expired. A credential command is run if its credentials are not cached.
+func (client *Client) Connection() Connection {
+	connection := client.connection
+	if client.authMethod == nil {
+		_, _ = client.useCachedSession()
+	}
+	if client.authMethod != nil {
+		connection.Authorization = client.authMethod.AuthorizationHeader()
+	}
+	return connection
+}
+
+// Connection returns the base URL, TLS settings and Authorization header of the client.
+// A credential command is run if its credentials are not cached.
+func (client *GatewayClient) Connection() Connection {
+	connection := client.connection
+	connection.Authorization = client.authMethod.AuthorizationHeader()
+	return connection
+}
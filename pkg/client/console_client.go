@@ -4,10 +4,12 @@ import (
 	"crypto/tls"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/conduktor/ctl/internal/utils"
 	"github.com/conduktor/ctl/pkg/resource"
@@ -43,6 +45,17 @@ type Client struct {
 	baseURL       string
 	client        *resty.Client
 	schemaCatalog *schema.Catalog
+	catalogInfo   CatalogInfo
+	// session is the login session the client authenticates with, refreshed before its expiry
+	session      *Session
+	sessionMutex sync.Mutex
+	// credentials are the ones the session was logged in with, used to login again before it expires
+	credentials *BasicAuth
+	connection  Connection
+	// getenv reads the CDK_* variables the client was created from, e.g. an env file, for the credentials resolved on first request
+	getenv func(string) string
+	// sendingSecrets are the secret fields of the resources being applied, masked in the debug logs
+	sendingSecrets sendingSecrets
 }
 
 type APIParameter struct {
@@ -56,6 +69,12 @@ type APIParameter struct {
 	CdkPassword string
 	AuthMode    string
 	Insecure    bool
+	// ExecCommand is the command line of a credential command, see ExecCredential
+	ExecCommand string
+	Catalog     CatalogOptions
+	// UseCachedSession authenticates with the session cached by conduktor login when no credentials are set,
+	// failing if it has expired
+	UseCachedSession bool
 }
 
 func uniformizeBaseURL(baseURL string) string {
@@ -90,6 +109,10 @@ func Make(apiParameter APIParameter) (*Client, error) {
 		return nil, fmt.Errorf("Can't set both CDK_USER and CDK_API_KEY")
 	}
 
+	if apiParameter.ExecCommand != "" && (apiParameter.CdkUser != "" || apiParameter.APIKey != "") {
+		return nil, fmt.Errorf("Can't set CDK_AUTH_EXEC with CDK_USER or CDK_API_KEY")
+	}
+
 	if apiParameter.Cacert != "" {
 		restyClient.SetRootCertificate(apiParameter.Cacert)
 	}
@@ -99,65 +122,99 @@ func Make(apiParameter APIParameter) (*Client, error) {
 		baseURL:       uniformizeBaseURL(apiParameter.BaseURL),
 		client:        restyClient,
 		schemaCatalog: nil,
+		getenv:        os.Getenv,
+		connection: Connection{
+			BaseURL:  uniformizeBaseURL(apiParameter.BaseURL),
+			Cacert:   apiParameter.Cacert,
+			Cert:     apiParameter.Cert,
+			Key:      apiParameter.Key,
+			Insecure: apiParameter.Insecure,
+		},
 	}
 
 	if apiParameter.Insecure {
 		result.IgnoreUntrustedCertificate()
 	}
+	maskDebugLogs(restyClient, func() *schema.Catalog { return result.schemaCatalog }, &result.sendingSecrets)
+	restyClient.OnBeforeRequest(result.refreshSessionIfNeeded)
+	restyClient.OnBeforeRequest(result.authorizeRequest)
 
 	if apiParameter.APIKey != "" {
 		result.authMethod = BearerToken{apiParameter.APIKey}
 	}
 
+	if apiParameter.ExecCommand != "" {
+		execCredential, err := NewExecCredential(apiParameter.ExecCommand, ConsoleAudience, result.baseURL)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid CDK_AUTH_EXEC: %s", err)
+		}
+		result.authMethod = execCredential
+	}
+
 	if apiParameter.CdkUser != "" {
 		if strings.ToLower(apiParameter.AuthMode) == "external" {
 			result.authMethod = BasicAuth{apiParameter.CdkUser, apiParameter.CdkPassword}
 		} else if apiParameter.AuthMode == "" || strings.ToLower(apiParameter.AuthMode) == "conduktor" {
-			jwtToken, err := result.Login(apiParameter.CdkUser, apiParameter.CdkPassword)
+			session, err := result.loginWithCache(apiParameter.CdkUser, apiParameter.CdkPassword)
 			if err != nil {
 				return nil, fmt.Errorf("Could not login: %s", err)
 			}
-			bearer := BearerToken{jwtToken.AccessToken}
-			result.authMethod = &bearer
+			result.useSession(session)
 		} else {
 			return nil, fmt.Errorf("CDK_AUTH_MODE was: \"%s\". Accepted values are \"conduktor\" or \"external\".", apiParameter.AuthMode)
 		}
 	}
 
+	if result.authMethod == nil && apiParameter.UseCachedSession && (apiParameter.AuthMode == "" || strings.ToLower(apiParameter.AuthMode) == "conduktor") {
+		if _, err := result.useCachedSession(); err != nil {
+			return nil, err
+		}
+	}
+
 	if result.authMethod != nil {
 		// set auth method in rest client only if defined to avoid failing on cmd help command print
 		result.setAuthMethodInRestClient()
 	}
 
-	err := result.initKindFromAPI()
-	if err != nil {
-		if apiParameter.Debug {
-			fmt.Fprintf(os.Stderr, "Cannot access the Conduktor API: %s\nUsing offline defaults.\n", err)
-		}
-		result.schemaCatalog = schema.ConsoleDefaultCatalog()
+	result.schemaCatalog, result.catalogInfo = result.catalogBackend().load(apiParameter.Catalog)
+	if result.catalogInfo.Error != nil && apiParameter.Debug {
+		fmt.Fprintf(os.Stderr, "Cannot access the Conduktor API: %s\nUsing %s catalog.\n", result.catalogInfo.Error, result.catalogInfo.Source)
 	}
 
 	return result, nil
 }
 
 func MakeFromEnv() (*Client, error) {
+	return MakeFromEnvLookup(os.Getenv)
+}
+
+// MakeFromEnvLookup creates a client from the CDK_* variables returned by getenv, e.g. read from an env file.
+func MakeFromEnvLookup(getenv func(string) string) (*Client, error) {
 	apiParameter := APIParameter{
-		BaseURL:     os.Getenv("CDK_BASE_URL"),
-		Debug:       utils.CdkDebug(),
-		Key:         os.Getenv("CDK_KEY"),
-		Cert:        os.Getenv("CDK_CERT"),
-		Cacert:      os.Getenv("CDK_CACERT"),
-		APIKey:      os.Getenv("CDK_API_KEY"),
-		CdkUser:     os.Getenv("CDK_USER"),
-		CdkPassword: os.Getenv("CDK_PASSWORD"),
-		AuthMode:    os.Getenv("CDK_AUTH_MODE"),
-		Insecure:    strings.ToLower(os.Getenv("CDK_INSECURE")) == "true",
+		BaseURL:          getenv("CDK_BASE_URL"),
+		Debug:            utils.CdkDebugFromEnvLookup(getenv),
+		Key:              getenv("CDK_KEY"),
+		Cert:             getenv("CDK_CERT"),
+		Cacert:           getenv("CDK_CACERT"),
+		APIKey:           getenv("CDK_API_KEY"),
+		CdkUser:          getenv("CDK_USER"),
+		CdkPassword:      getenv("CDK_PASSWORD"),
+		AuthMode:         getenv("CDK_AUTH_MODE"),
+		Insecure:         strings.ToLower(getenv("CDK_INSECURE")) == "true",
+		ExecCommand:      getenv("CDK_AUTH_EXEC"),
+		UseCachedSession: true,
+	}
+	catalogOptions, err := CatalogOptionsFromEnv(getenv)
+	if err != nil {
+		return nil, err
 	}
+	apiParameter.Catalog = catalogOptions
 
 	client, err := Make(apiParameter)
 	if err != nil {
 		return nil, fmt.Errorf("Cannot create client: %s", err)
 	}
+	client.getenv = getenv
 	return client, nil
 }
 
@@ -172,13 +229,13 @@ func (client *Client) IgnoreUntrustedCertificate() {
 
 func (client *Client) setAuthMethodFromEnvIfNeeded() {
 	if client.authMethod == nil {
-		authMode := strings.ToLower(os.Getenv("CDK_AUTH_MODE"))
-		apiKey := os.Getenv("CDK_API_KEY")
+		authMode := strings.ToLower(client.getenv("CDK_AUTH_MODE"))
+		apiKey := client.getenv("CDK_API_KEY")
 
 		switch authMode {
 		case "external":
-			user := os.Getenv("CDK_USER")
-			password := os.Getenv("CDK_PASSWORD")
+			user := client.getenv("CDK_USER")
+			password := client.getenv("CDK_PASSWORD")
 
 			if apiKey == "" && user == "" {
 				fmt.Fprintln(os.Stderr, "Please set CDK_API_KEY or CDK_USER/CDK_PASSWORD")
@@ -202,7 +259,12 @@ func (client *Client) setAuthMethodFromEnvIfNeeded() {
 			}
 		case "", "conduktor":
 			if apiKey == "" {
-				fmt.Fprintln(os.Stderr, "Please set CDK_API_KEY")
+				if ok, err := client.useCachedSession(); ok {
+					return
+				} else if err != nil {
+					fmt.Fprintf(os.Stderr, "Cannot use the cached login session: %s\n", err)
+				}
+				fmt.Fprintln(os.Stderr, "Please set CDK_API_KEY or run conduktor login")
 				os.Exit(1)
 			}
 
@@ -221,9 +283,24 @@ func (client *Client) setAuthMethodInRestClient() {
 		fmt.Fprintln(os.Stderr, "No authentication method defined. Please set CDK_API_KEY or CDK_USER/CDK_PASSWORD")
 		os.Exit(1)
 	}
+	if _, ok := client.authMethod.(requestAuthMethod); ok {
+		// set on each request by authorizeRequest
+		return
+	}
 	client.client = client.client.SetHeader("Authorization", client.authMethod.AuthorizationHeader())
 }
 
+func (client *Client) authorizeRequest(_ *resty.Client, request *resty.Request) error {
+	// the OpenAPI documentation is public, no need to run a credential command for every invocation
+	if strings.HasPrefix(request.URL, client.baseURL+"/public/docs/") {
+		return nil
+	}
+	if method, ok := client.authMethod.(requestAuthMethod); ok {
+		return method.authorizeRequest(request)
+	}
+	return nil
+}
+
 func (client *Client) SetAPIKey(apiKey string) {
 	client.authMethod = BearerToken{apiKey}
 	client.setAuthMethodInRestClient()
@@ -262,6 +339,7 @@ func (client *Client) Apply(resource *resource.Resource, dryMode bool, diffMode
 		return result, err
 	}
 	url := client.baseURL + applyQueryInfo.Path
+	defer client.sendingSecrets.add(resource)()
 	builder := client.client.R().SetBody(resource.Json)
 	for _, param := range applyQueryInfo.QueryParams {
 		builder = builder.SetQueryParam(param.Name, param.Value)
@@ -272,10 +350,14 @@ func (client *Client) Apply(resource *resource.Resource, dryMode bool, diffMode
 
 	if diffMode {
 		currentRes, err := client.GetFromResource(resource)
-		if err != nil {
+		if err != nil && !errors.Is(err, ErrResourceNotFound) {
 			return result, err
 		}
-		diff, err := utils.DiffResources(&currentRes, resource)
+		diff, err := utils.DiffResourcesWithOptions(&currentRes, resource, utils.DiffOptions{
+			Context:         utils.DefaultDiffContext,
+			ArrayOrdering:   kind.GetLatestKindVersion().GetArrayOrdering(),
+			SensitiveFields: kind.GetLatestKindVersion().GetSensitiveFields(),
+		})
 		if err != nil {
 			return result, err
 		}
@@ -363,7 +445,7 @@ func (client *Client) GetFromResource(res *resource.Resource) (resource.Resource
 			return element, nil
 		}
 	}
-	return resource.Resource{}, fmt.Errorf("could not find any matching resource")
+	return resource.Resource{}, ErrResourceNotFound
 }
 
 func (client *Client) Run(run schema.Run, pathValue []string, queryParams map[string]string, body interface{}) ([]byte, error) {
@@ -390,7 +472,7 @@ func (client *Client) Run(run schema.Run, pathValue []string, queryParams map[st
 }
 
 func (client *Client) Login(username, password string) (LoginResult, error) {
-	url := client.baseURL + "/login"
+	url := client.baseURL + loginPath
 	resp, err := client.client.R().SetBody(map[string]string{"username": username, "password": password}).Post(url)
 	if err != nil {
 		return LoginResult{}, err
@@ -496,21 +578,37 @@ func (client *Client) GetOpenAPI() ([]byte, error) {
 	return resp.Body(), nil
 }
 
-func (client *Client) initKindFromAPI() error {
-	data, err := client.GetOpenAPI()
-	if err != nil {
-		return fmt.Errorf("Cannot get openapi: %s", err)
-	}
-	schema, err := schema.NewOpenAPIParser(data)
+func (client *Client) catalogBackend() catalogBackend {
+	backend := consoleCatalogBackend(client.baseURL)
+	backend.fetch = client.GetOpenAPI
+	return backend
+}
+
+// RefreshCatalog downloads the catalog and replaces the cached one.
+func (client *Client) RefreshCatalog() (CatalogInfo, error) {
+	info := client.catalogInfo
+	info.Error = nil
+	catalog, info, err := client.catalogBackend().refresh(info, true)
 	if err != nil {
-		return fmt.Errorf("Cannot parse openapi: %s", err)
+		return info, err
 	}
-	strict := false
-	client.schemaCatalog, err = schema.GetConsoleCatalog(strict)
-	if err != nil {
-		return fmt.Errorf("Cannot extract schemaCatalog from openapi: %s", err)
+	client.schemaCatalog, client.catalogInfo = catalog, info
+	return info, nil
+}
+
+// RefreshCachedCatalog downloads the catalog again if the one in use was cached, e.g. when it lacks the kind of a resource
+// because the server was upgraded since. It returns true if the catalog was replaced.
+func (client *Client) RefreshCachedCatalog() bool {
+	if !client.catalogInfo.refreshable() {
+		return false
 	}
-	return nil
+	_, err := client.RefreshCatalog()
+	return err == nil
+}
+
+// CatalogInfo tells where the catalog in use comes from.
+func (client *Client) CatalogInfo() CatalogInfo {
+	return client.catalogInfo
 }
 
 func (client *Client) ListAdminToken() ([]Token, error) {
@@ -597,3 +597,24 @@ func TestDeleteShouldFailOnNot2XX(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestMakeFromEnvLookupDoesNotReadTheProcessEnvironment(t *testing.T) {
+	t.Setenv("CDK_DEBUG", "true")
+	t.Setenv("CDK_AUTH_MODE", "external")
+	t.Setenv("CDK_API_KEY", "fromProcess")
+	env := map[string]string{
+		"CDK_BASE_URL": "http://baseUrl",
+		"CDK_API_KEY":  "fromLookup",
+		"CDK_OFFLINE":  "true",
+	}
+	client, err := MakeFromEnvLookup(func(name string) string { return env[name] })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.client.Debug {
+		t.Error("Expected debug to be read from the lookup")
+	}
+	if client.getenv("CDK_AUTH_MODE") != "" || client.getenv("CDK_API_KEY") != "fromLookup" {
+		t.Errorf("Expected the credentials to be read from the lookup, got CDK_AUTH_MODE=%s CDK_API_KEY=%s", client.getenv("CDK_AUTH_MODE"), client.getenv("CDK_API_KEY"))
+	}
+}
@@ -0,0 +1,180 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/conduktor/ctl/internal/utils"
+	"github.com/zalando/go-keyring"
+)
+
+const credentialsFileName = "credentials.json"
+
+// keyringService is the service name the credentials are stored under in the OS keyring.
+const keyringService = "conduktor-cli"
+
+// credentialStore persists JSON credentials grouped in sections, e.g. the login sessions keyed by base URL.
+type credentialStore interface {
+	load(section, key string, value interface{}) (bool, error)
+	save(section, key string, value interface{}) error
+	delete(section, key string) (bool, error)
+}
+
+// credentialStoreFromEnv returns the store selected by CDK_CREDENTIALS_STORE, the credentials file by default.
+func credentialStoreFromEnv() (credentialStore, error) {
+	storeName := os.Getenv("CDK_CREDENTIALS_STORE")
+	switch strings.ToLower(storeName) {
+	case "", "file":
+		path, err := CredentialsFilePath()
+		if err != nil {
+			return nil, err
+		}
+		return fileCredentialStore{path}, nil
+	case "keyring":
+		return keyringCredentialStore{}, nil
+	default:
+		return nil, fmt.Errorf("CDK_CREDENTIALS_STORE was: \"%s\". Accepted values are \"file\" or \"keyring\".", storeName)
+	}
+}
+
+// CredentialsFilePath returns the path of the file caching the credentials, in the configuration directory.
+func CredentialsFilePath() (string, error) {
+	configDir, err := utils.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, credentialsFileName), nil
+}
+
+// fileCredentialStore stores the credentials in a JSON file only readable by the user.
+type fileCredentialStore struct {
+	path string
+}
+
+type credentialsFile map[string]map[string]json.RawMessage
+
+func (store fileCredentialStore) read() (credentialsFile, error) {
+	result := credentialsFile{}
+	data, err := os.ReadFile(store.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return result, nil
+	} else if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(data, &result)
+	if err != nil {
+		return result, fmt.Errorf("invalid credentials file %s: %s", store.path, err)
+	}
+	return result, nil
+}
+
+func (store fileCredentialStore) write(file credentialsFile) error {
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(store.path), 0700)
+	if err != nil {
+		return err
+	}
+	// write then rename so a concurrent invocation never reads a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(store.path), credentialsFileName+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err = tmp.Chmod(0600); err == nil {
+		_, err = tmp.Write(data)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), store.path)
+}
+
+func (store fileCredentialStore) load(section, key string, value interface{}) (bool, error) {
+	file, err := store.read()
+	if err != nil {
+		return false, err
+	}
+	data, ok := file[section][key]
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(data, value)
+}
+
+func (store fileCredentialStore) save(section, key string, value interface{}) error {
+	file, err := store.read()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if file[section] == nil {
+		file[section] = map[string]json.RawMessage{}
+	}
+	file[section][key] = data
+	return store.write(file)
+}
+
+func (store fileCredentialStore) delete(section, key string) (bool, error) {
+	file, err := store.read()
+	if err != nil {
+		return false, err
+	}
+	if _, ok := file[section][key]; !ok {
+		return false, nil
+	}
+	delete(file[section], key)
+	return true, store.write(file)
+}
+
+// keyringCredentialStore stores the credentials in the OS keyring: the macOS Keychain,
+// the Windows Credential Manager or the Secret Service on Linux.
+type keyringCredentialStore struct{}
+
+func keyringUser(section, key string) string {
+	return section + ":" + key
+}
+
+func (keyringCredentialStore) load(section, key string, value interface{}) (bool, error) {
+	data, err := keyring.Get(keyringService, keyringUser(section, key))
+	if errors.Is(err, keyring.ErrNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("could not read the OS keyring: %s", err)
+	}
+	return true, json.Unmarshal([]byte(data), value)
+}
+
+func (keyringCredentialStore) save(section, key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	err = keyring.Set(keyringService, keyringUser(section, key), string(data))
+	if err != nil {
+		return fmt.Errorf("could not write to the OS keyring: %s", err)
+	}
+	return nil
+}
+
+func (keyringCredentialStore) delete(section, key string) (bool, error) {
+	err := keyring.Delete(keyringService, keyringUser(section, key))
+	if errors.Is(err, keyring.ErrNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("could not delete from the OS keyring: %s", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,128 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/conduktor/ctl/pkg/resource"
+	"github.com/conduktor/ctl/pkg/schema"
+	"github.com/go-resty/resty/v2"
+)
+
+// authHeaders are redacted from the debug logs whatever the request.
+var authHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// credentialFields are the fields of the login, refresh and token bodies masked in the debug logs.
+var credentialFields = []string{"password", "token", "access_token", "refresh_token", "id_token", "client_secret"}
+
+// sendingSecrets holds the fields resolved from secret references of the resources a client is sending, by kind,
+// masked in the debug logs of the requests and responses of their kind while they are sent.
+// The debug log hooks of resty only get the headers and the body, not the resource of the request.
+type sendingSecrets struct {
+	mutex  sync.Mutex
+	byKind map[string]map[string]int
+}
+
+// add records the secret fields of a resource being sent, until the returned func is called.
+func (s *sendingSecrets) add(res *resource.Resource) func() {
+	if len(res.SecretFields) == 0 {
+		return func() {}
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.byKind == nil {
+		s.byKind = map[string]map[string]int{}
+	}
+	if s.byKind[res.Kind] == nil {
+		s.byKind[res.Kind] = map[string]int{}
+	}
+	for _, path := range res.SecretFields {
+		s.byKind[res.Kind][path]++
+	}
+	return func() {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		for _, path := range res.SecretFields {
+			s.byKind[res.Kind][path]--
+			if s.byKind[res.Kind][path] == 0 {
+				delete(s.byKind[res.Kind], path)
+			}
+		}
+	}
+}
+
+func (s *sendingSecrets) fieldsOfKind(kind string) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	paths := make([]string, 0, len(s.byKind[kind]))
+	for path := range s.byKind[kind] {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// maskDebugLogs keeps credentials out of the debug logs of a client: the auth headers, the credential fields,
+// the fields resolved from secret references of the resources being sent and the sensitive fields of the resources of the catalog.
+func maskDebugLogs(restyClient *resty.Client, catalog func() *schema.Catalog, secrets *sendingSecrets) {
+	restyClient.OnRequestLog(func(log *resty.RequestLog) error {
+		redactAuthHeaders(log.Header)
+		log.Body = maskLogBody(log.Body, catalog(), secrets)
+		return nil
+	})
+	restyClient.OnResponseLog(func(log *resty.ResponseLog) error {
+		redactAuthHeaders(log.Header)
+		log.Body = maskLogBody(log.Body, catalog(), secrets)
+		return nil
+	})
+}
+
+func redactAuthHeaders(header http.Header) {
+	for _, name := range authHeaders {
+		if header.Get(name) != "" {
+			header.Set(name, resource.SecretMask)
+		}
+	}
+}
+
+// maskLogBody masks a JSON body holding a resource or a list of resources, with the sensitive fields of their kinds
+// and the fields of their kinds resolved from secret references in the resources being sent.
+func maskLogBody(body string, catalog *schema.Catalog, secrets *sendingSecrets) string {
+	var document interface{}
+	if json.Unmarshal([]byte(body), &document) != nil {
+		return body
+	}
+	paths := append([]string{}, credentialFields...)
+	kinds := map[string]bool{}
+	collectKinds(document, kinds)
+	for kind := range kinds {
+		paths = append(paths, catalog.SensitiveFields(kind)...)
+		paths = append(paths, secrets.fieldsOfKind(kind)...)
+	}
+	masked := resource.MaskSensitiveFields([]byte(body), paths)
+	if bytes.Equal(masked, []byte(body)) {
+		return body
+	}
+	// same indentation as resty
+	var indented bytes.Buffer
+	if json.Indent(&indented, masked, "", "   ") != nil {
+		return string(masked)
+	}
+	return indented.String()
+}
+
+func collectKinds(document interface{}, kinds map[string]bool) {
+	switch value := document.(type) {
+	case map[string]interface{}:
+		if kind, ok := value["kind"].(string); ok {
+			kinds[kind] = true
+		}
+	case []interface{}:
+		for _, element := range value {
+			collectKinds(element, kinds)
+		}
+	}
+}
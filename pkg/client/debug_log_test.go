@@ -0,0 +1,104 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/conduktor/ctl/pkg/resource"
+	"github.com/conduktor/ctl/pkg/schema"
+	"github.com/jarcoal/httpmock"
+)
+
+func TestDebugLogsShouldMaskCredentials(t *testing.T) {
+	defer httpmock.Reset()
+	var logs bytes.Buffer
+	client, err := Make(APIParameter{BaseURL: "http://baseUrl", APIKey: "api-key-value", Debug: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.schemaCatalog = schema.ConsoleDefaultCatalog()
+	client.client.SetLogger(&bufferLogger{&logs})
+	httpmock.ActivateNonDefault(client.client.GetClient())
+	httpmock.RegisterResponder("GET", "http://baseUrl/api/public/console/v2/kafka-cluster",
+		httpmock.NewStringResponder(200, `[{"apiVersion":"v2","kind":"KafkaCluster","metadata":{"name":"prod"},"spec":{"properties":{"sasl.jaas.config":"jaas-value"},"schemaRegistry":{"security":{"password":"registry-value"}}}}]`))
+	httpmock.RegisterResponder("POST", "http://baseUrl/api/login",
+		httpmock.NewStringResponder(200, `{"access_token":"access-value","refresh_token":"refresh-value"}`))
+
+	kind := client.GetKinds()["KafkaCluster"]
+	if _, err := client.Get(&kind, nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Login("admin", "password-value"); err != nil {
+		t.Fatal(err)
+	}
+
+	output := logs.String()
+	for _, secret := range []string{"api-key-value", "jaas-value", "registry-value", "password-value", "access-value", "refresh-value"} {
+		if strings.Contains(output, secret) {
+			t.Errorf("Expected %s to be masked in\n%s", secret, output)
+		}
+	}
+	if !strings.Contains(output, `"sasl.jaas.config": "***"`) || !strings.Contains(output, "Authorization: ***") {
+		t.Errorf("Expected masked values in\n%s", output)
+	}
+}
+
+func TestDebugLogsShouldMaskTheSecretFieldsOfTheAppliedResource(t *testing.T) {
+	defer httpmock.Reset()
+	var logs bytes.Buffer
+	client, err := Make(APIParameter{BaseURL: "http://baseUrl", APIKey: "api-key-value", Debug: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.schemaCatalog = schema.ConsoleDefaultCatalog()
+	client.client.SetLogger(&bufferLogger{&logs})
+	httpmock.ActivateNonDefault(client.client.GetClient())
+	httpmock.RegisterResponder("PUT", "http://baseUrl/api/public/kafka/v2/cluster/local/topic",
+		httpmock.NewStringResponder(200, `{"upsertResult": "NotChanged"}`))
+	httpmock.RegisterResponder("GET", "http://baseUrl/api/public/kafka/v2/cluster/local/topic",
+		httpmock.NewStringResponder(200, `[{"apiVersion":"v2","kind":"Topic","metadata":{"name":"toto","cluster":"local"},"spec":{"password":"current-value"}}]`))
+
+	topic := resource.Resource{
+		Json:         []byte(`{"apiVersion":"v2","kind":"Topic","metadata":{"name":"toto","cluster":"local"},"spec":{"password":"secret-value"}}`),
+		Kind:         "Topic",
+		Name:         "toto",
+		Version:      "v2",
+		Metadata:     map[string]interface{}{"cluster": "local"},
+		SecretFields: []string{"spec.password"},
+	}
+	if _, err := client.Apply(&topic, false, true); err != nil {
+		t.Fatal(err)
+	}
+	output := logs.String()
+	if strings.Contains(output, "current-value") || !strings.Contains(output, `"password": "***"`) {
+		t.Errorf("Expected the secret field of the applied resource to be masked in\n%s", output)
+	}
+
+	logs.Reset()
+	kind := client.GetKinds()["Topic"]
+	if _, err := client.Get(&kind, []string{"local"}, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(logs.String(), "current-value") {
+		t.Errorf("Expected the secret fields to be masked only while the resource is applied in\n%s", logs.String())
+	}
+}
+
+func TestRedactAuthHeaders(t *testing.T) {
+	header := http.Header{"Authorization": {"Bearer token"}, "Content-Type": {"application/json"}}
+	redactAuthHeaders(header)
+	if header.Get("Authorization") != "***" || header.Get("Content-Type") != "application/json" || header.Get("Cookie") != "" {
+		t.Errorf("Unexpected headers %v", header)
+	}
+}
+
+type bufferLogger struct {
+	buffer *bytes.Buffer
+}
+
+func (l *bufferLogger) Errorf(format string, v ...interface{}) { fmt.Fprintf(l.buffer, format, v...) }
+func (l *bufferLogger) Warnf(format string, v ...interface{})  { fmt.Fprintf(l.buffer, format, v...) }
+func (l *bufferLogger) Debugf(format string, v ...interface{}) { fmt.Fprintf(l.buffer, format, v...) }
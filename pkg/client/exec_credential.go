@@ -0,0 +1,190 @@
+package client
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/conduktor/ctl/internal/utils"
+	"github.com/go-resty/resty/v2"
+)
+
+const execCredentialsSection = "execCredentials"
+
+const (
+	ExecCredentialAPIVersion = "conduktor.io/v1"
+	ExecCredentialKind       = "ExecCredential"
+)
+
+// Audiences of exec credentials, telling the command which API the credentials are for.
+const (
+	ConsoleAudience = "console"
+	GatewayAudience = "gateway"
+)
+
+// requestAuthMethod is an AuthMethod resolved on each request instead of once on the client,
+// e.g. to renew credentials before they expire.
+type requestAuthMethod interface {
+	AuthMethod
+	authorizeRequest(request *resty.Request) error
+}
+
+// ExecCredential is an AuthMethod running a command to get its credentials, in the style of kubectl exec plugins.
+// The command gets an ExecCredential document without status in the CDK_EXEC_INFO variable
+// and prints it on stdout with a status holding a token, or a username and password, and an optional expiry:
+//
+//	{"apiVersion": "conduktor.io/v1", "kind": "ExecCredential", "status": {"token": "...", "expirationTimestamp": "2024-01-01T00:00:00Z"}}
+//
+// Credentials are cached until they expire, in the credential store when they have an expiry.
+type ExecCredential struct {
+	Command  []string
+	Audience string
+	BaseURL  string
+	mutex    sync.Mutex
+	status   *ExecCredentialStatus
+}
+
+type ExecCredentialSpec struct {
+	Audience string `json:"audience"`
+	BaseURL  string `json:"baseUrl"`
+}
+
+type ExecCredentialStatus struct {
+	Token               string     `json:"token,omitempty"`
+	Username            string     `json:"username,omitempty"`
+	Password            string     `json:"password,omitempty"`
+	ExpirationTimestamp *time.Time `json:"expirationTimestamp,omitempty"`
+}
+
+type ExecCredentialDocument struct {
+	APIVersion string                `json:"apiVersion"`
+	Kind       string                `json:"kind"`
+	Spec       ExecCredentialSpec    `json:"spec"`
+	Status     *ExecCredentialStatus `json:"status,omitempty"`
+}
+
+// NewExecCredential parses the command line of a credential command.
+func NewExecCredential(commandLine, audience, baseURL string) (*ExecCredential, error) {
+	command, err := utils.SplitCommandLine(commandLine)
+	if err != nil {
+		return nil, err
+	}
+	if len(command) == 0 {
+		return nil, fmt.Errorf("empty credential command")
+	}
+	return &ExecCredential{Command: command, Audience: audience, BaseURL: baseURL}, nil
+}
+
+func (s ExecCredentialStatus) expired() bool {
+	return s.ExpirationTimestamp != nil && time.Now().Add(sessionRefreshMargin).After(*s.ExpirationTimestamp)
+}
+
+func (s ExecCredentialStatus) authorizationHeader() string {
+	if s.Token != "" {
+		return BearerToken{s.Token}.AuthorizationHeader()
+	}
+	return BasicAuth{s.Username, s.Password}.AuthorizationHeader()
+}
+
+// cacheKey identifies the cached credentials by audience, base URL and command, so that another command,
+// e.g. for another role, does not reuse them.
+func (e *ExecCredential) cacheKey() string {
+	hash := sha256.Sum256([]byte(strings.Join(e.Command, "\x00")))
+	return e.Audience + ":" + e.BaseURL + ":" + hex.EncodeToString(hash[:8])
+}
+
+// Status returns the cached credentials, running the command if there are none or they expire.
+func (e *ExecCredential) Status() (ExecCredentialStatus, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.status != nil && !e.status.expired() {
+		return *e.status, nil
+	}
+
+	store, storeErr := credentialStoreFromEnv()
+	if storeErr == nil {
+		var cached ExecCredentialStatus
+		ok, err := store.load(execCredentialsSection, e.cacheKey(), &cached)
+		if err == nil && ok && !cached.expired() {
+			e.status = &cached
+			return cached, nil
+		}
+	}
+
+	status, err := e.run()
+	if err != nil {
+		return ExecCredentialStatus{}, err
+	}
+	e.status = &status
+	if status.ExpirationTimestamp != nil && storeErr == nil {
+		if err := store.save(execCredentialsSection, e.cacheKey(), status); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not cache the credentials of %s: %s\n", e.Command[0], err)
+		}
+	}
+	return status, nil
+}
+
+func (e *ExecCredential) run() (ExecCredentialStatus, error) {
+	info, err := json.Marshal(ExecCredentialDocument{
+		APIVersion: ExecCredentialAPIVersion,
+		Kind:       ExecCredentialKind,
+		Spec:       ExecCredentialSpec{Audience: e.Audience, BaseURL: e.BaseURL},
+	})
+	if err != nil {
+		return ExecCredentialStatus{}, err
+	}
+	var stdout bytes.Buffer
+	cmd := exec.Command(e.Command[0], e.Command[1:]...)
+	cmd.Env = append(os.Environ(), "CDK_EXEC_INFO="+string(info))
+	// the command may prompt the user
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+	cmd.Stdout = &stdout
+	err = cmd.Run()
+	if err != nil {
+		return ExecCredentialStatus{}, fmt.Errorf("credential command %s failed: %s", e.Command[0], err)
+	}
+
+	var document ExecCredentialDocument
+	err = json.Unmarshal(stdout.Bytes(), &document)
+	if err != nil {
+		return ExecCredentialStatus{}, fmt.Errorf("invalid output of credential command %s: %s", e.Command[0], err)
+	}
+	if document.Kind != ExecCredentialKind || document.Status == nil {
+		return ExecCredentialStatus{}, fmt.Errorf("credential command %s must print an %s with a status", e.Command[0], ExecCredentialKind)
+	}
+	status := *document.Status
+	hasBasicAuth := status.Username != "" && status.Password != ""
+	if e.Audience == GatewayAudience && !hasBasicAuth {
+		return ExecCredentialStatus{}, fmt.Errorf("credential command %s must return a username and a password for the Gateway", e.Command[0])
+	} else if status.Token == "" && !hasBasicAuth {
+		return ExecCredentialStatus{}, fmt.Errorf("credential command %s must return a token or a username and a password", e.Command[0])
+	}
+	return status, nil
+}
+
+// AuthorizationHeader runs the command if needed, it returns an empty header if it fails:
+// the error is reported by the request made with the client.
+func (e *ExecCredential) AuthorizationHeader() string {
+	status, err := e.Status()
+	if err != nil {
+		return ""
+	}
+	return status.authorizationHeader()
+}
+
+func (e *ExecCredential) authorizeRequest(request *resty.Request) error {
+	status, err := e.Status()
+	if err != nil {
+		return err
+	}
+	request.SetHeader("Authorization", status.authorizationHeader())
+	return nil
+}
@@ -0,0 +1,200 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zalando/go-keyring"
+)
+
+// TestExecCredentialHelperProcess is not a test: it is the credential command run by the tests below.
+func TestExecCredentialHelperProcess(t *testing.T) {
+	if os.Getenv("CDK_TEST_EXEC_HELPER") != "1" {
+		return
+	}
+	counter, _ := os.OpenFile(os.Getenv("CDK_TEST_EXEC_COUNTER"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	_, _ = counter.WriteString("x")
+	counter.Close()
+
+	var info ExecCredentialDocument
+	_ = json.Unmarshal([]byte(os.Getenv("CDK_EXEC_INFO")), &info)
+	expiry := time.Now().Add(time.Hour)
+	if os.Getenv("CDK_TEST_EXEC_EXPIRED") == "1" {
+		expiry = time.Now().Add(-time.Hour)
+	}
+	status := &ExecCredentialStatus{Token: "exec-token", ExpirationTimestamp: &expiry}
+	if info.Spec.Audience == GatewayAudience {
+		status = &ExecCredentialStatus{Username: "exec-user", Password: "exec-password"}
+	}
+	if os.Getenv("CDK_TEST_EXEC_INVALID") == "1" {
+		fmt.Print("not json")
+		os.Exit(0)
+	}
+	info.Status = status
+	_ = json.NewEncoder(os.Stdout).Encode(info)
+	os.Exit(0)
+}
+
+// execHelper returns the command line running the helper process and a function counting its runs.
+func execHelper(t *testing.T) (string, func() int) {
+	counter := filepath.Join(t.TempDir(), "counter")
+	t.Setenv("CDK_TEST_EXEC_HELPER", "1")
+	t.Setenv("CDK_TEST_EXEC_COUNTER", counter)
+	commandLine := fmt.Sprintf("'%s' -test.run=^TestExecCredentialHelperProcess$", os.Args[0])
+	return commandLine, func() int {
+		data, _ := os.ReadFile(counter)
+		return len(data)
+	}
+}
+
+func newAuthorizationRecorder(t *testing.T) (*httptest.Server, *[]string) {
+	var headers []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.URL.Path, "docs") {
+			headers = append(headers, r.Header.Get("Authorization"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &headers
+}
+
+func TestExecCredentialShouldAuthenticateConsoleAndBeCached(t *testing.T) {
+	isolateConfigDir(t)
+	commandLine, runs := execHelper(t)
+	server, headers := newAuthorizationRecorder(t)
+
+	for i := 0; i < 2; i++ {
+		client, err := Make(APIParameter{BaseURL: server.URL, ExecCommand: commandLine})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = client.client.R().Get(server.URL + "/api/public/v1/something")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(*headers) != 2 || (*headers)[0] != "Bearer exec-token" || (*headers)[1] != "Bearer exec-token" {
+		t.Errorf("Unexpected authorization headers %v", *headers)
+	}
+	if runs() != 1 {
+		t.Errorf("Expected the credentials to be cached until expiry, the command ran %d times", runs())
+	}
+}
+
+func TestExecCredentialShouldRunAgainOnceExpired(t *testing.T) {
+	isolateConfigDir(t)
+	commandLine, runs := execHelper(t)
+	t.Setenv("CDK_TEST_EXEC_EXPIRED", "1")
+
+	execCredential, err := NewExecCredential(commandLine, ConsoleAudience, "http://baseUrl/api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := execCredential.Status(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if runs() != 2 {
+		t.Errorf("Expected expired credentials to be renewed, the command ran %d times", runs())
+	}
+}
+
+func TestExecCredentialShouldNotReuseTheCacheOfAnotherCommand(t *testing.T) {
+	isolateConfigDir(t)
+	commandLine, runs := execHelper(t)
+
+	for _, command := range []string{commandLine, commandLine + " -test.v=false"} {
+		execCredential, err := NewExecCredential(command, ConsoleAudience, "http://baseUrl/api")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := execCredential.Status(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if runs() != 2 {
+		t.Errorf("Expected each command to get its own credentials, the commands ran %d times", runs())
+	}
+}
+
+func TestExecCredentialShouldReportInvalidOutput(t *testing.T) {
+	isolateConfigDir(t)
+	commandLine, _ := execHelper(t)
+	t.Setenv("CDK_TEST_EXEC_INVALID", "1")
+	server, _ := newAuthorizationRecorder(t)
+
+	client, err := Make(APIParameter{BaseURL: server.URL, ExecCommand: commandLine})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.client.R().Get(server.URL + "/api/public/v1/something")
+	if err == nil || !strings.Contains(err.Error(), "invalid output of credential command") {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	_, err = Make(APIParameter{BaseURL: server.URL, ExecCommand: commandLine, APIKey: "key"})
+	if err == nil {
+		t.Error("Expected CDK_AUTH_EXEC and CDK_API_KEY to be exclusive")
+	}
+}
+
+func TestExecCredentialShouldAuthenticateGateway(t *testing.T) {
+	isolateConfigDir(t)
+	commandLine, _ := execHelper(t)
+	server, headers := newAuthorizationRecorder(t)
+
+	gatewayClient, err := MakeGateway(GatewayAPIParameter{BaseURL: server.URL, ExecCommand: commandLine})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := listVirtualClusters(gatewayClient); err != nil {
+		t.Fatal(err)
+	}
+	expected := BasicAuth{"exec-user", "exec-password"}.AuthorizationHeader()
+	if len(*headers) != 1 || (*headers)[0] != expected {
+		t.Errorf("Unexpected authorization headers %v", *headers)
+	}
+
+	_, err = MakeGateway(GatewayAPIParameter{BaseURL: server.URL, ExecCommand: commandLine, CdkGatewayUser: "admin"})
+	if err == nil {
+		t.Error("Expected CDK_GATEWAY_AUTH_EXEC and CDK_GATEWAY_USER to be exclusive")
+	}
+}
+
+func TestSessionShouldBeStoredInKeyring(t *testing.T) {
+	isolateConfigDir(t)
+	keyring.MockInit()
+	t.Setenv("CDK_CREDENTIALS_STORE", "keyring")
+
+	session := NewSession("http://baseUrl", "admin", LoginResult{AccessToken: "access"})
+	if err := SaveSession(session); err != nil {
+		t.Fatal(err)
+	}
+	loaded, ok, err := LoadSession("http://baseUrl")
+	if err != nil || !ok || loaded.AccessToken != "access" {
+		t.Errorf("Unexpected session %+v %v %v", loaded, ok, err)
+	}
+	path, _ := CredentialsFilePath()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("The credentials file should not be written when using the keyring")
+	}
+	deleted, err := DeleteSession("http://baseUrl")
+	if err != nil || !deleted {
+		t.Errorf("Expected the session to be deleted got %v %v", deleted, err)
+	}
+
+	t.Setenv("CDK_CREDENTIALS_STORE", "vault")
+	if _, _, err := LoadSession("http://baseUrl"); err == nil {
+		t.Error("Expected an unknown credential store to be rejected")
+	}
+}
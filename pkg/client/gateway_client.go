@@ -3,8 +3,10 @@ package client
 import (
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/conduktor/ctl/internal/utils"
 	"github.com/conduktor/ctl/pkg/resource"
@@ -18,6 +20,11 @@ type GatewayClient struct {
 	baseURL            string
 	client             *resty.Client
 	schemaCatalog      *schema.Catalog
+	catalogInfo        CatalogInfo
+	authMethod         AuthMethod
+	connection         Connection
+	// sendingSecrets are the secret fields of the resources being applied, masked in the debug logs
+	sendingSecrets sendingSecrets
 }
 
 type GatewayAPIParameter struct {
@@ -25,6 +32,13 @@ type GatewayAPIParameter struct {
 	Debug              bool
 	CdkGatewayUser     string
 	CdkGatewayPassword string
+	Key                string
+	Cert               string
+	Cacert             string
+	Insecure           bool
+	// ExecCommand is the command line of a credential command returning the username and password, see ExecCredential
+	ExecCommand string
+	Catalog     CatalogOptions
 }
 
 func MakeGateway(apiParameter GatewayAPIParameter) (*GatewayClient, error) {
@@ -34,40 +48,99 @@ func MakeGateway(apiParameter GatewayAPIParameter) (*GatewayClient, error) {
 		return nil, fmt.Errorf("Please set CDK_GATEWAY_BASE_URL")
 	}
 
-	if apiParameter.CdkGatewayUser == "" || apiParameter.CdkGatewayPassword == "" {
+	var authMethod AuthMethod = BasicAuth{apiParameter.CdkGatewayUser, apiParameter.CdkGatewayPassword}
+
+	if apiParameter.ExecCommand != "" {
+		if apiParameter.CdkGatewayUser != "" || apiParameter.CdkGatewayPassword != "" {
+			return nil, fmt.Errorf("Can't set CDK_GATEWAY_AUTH_EXEC with CDK_GATEWAY_USER or CDK_GATEWAY_PASSWORD")
+		}
+		execCredential, err := NewExecCredential(apiParameter.ExecCommand, GatewayAudience, apiParameter.BaseURL)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid CDK_GATEWAY_AUTH_EXEC: %s", err)
+		}
+		restyClient.OnBeforeRequest(func(_ *resty.Client, request *resty.Request) error {
+			return execCredential.authorizeRequest(request)
+		})
+		authMethod = execCredential
+	} else if apiParameter.CdkGatewayUser == "" || apiParameter.CdkGatewayPassword == "" {
 		return nil, fmt.Errorf("CDK_GATEWAY_USER and CDK_GATEWAY_PASSWORD must be provided")
 	}
 
+	if (apiParameter.Key == "" && apiParameter.Cert != "") || (apiParameter.Key != "" && apiParameter.Cert == "") {
+		return nil, fmt.Errorf("CDK_GATEWAY_KEY and CDK_GATEWAY_CERT must be provided together")
+	} else if apiParameter.Key != "" && apiParameter.Cert != "" {
+		certificate, err := tls.LoadX509KeyPair(apiParameter.Cert, apiParameter.Key)
+		if err != nil {
+			return nil, err
+		}
+		restyClient.SetCertificates(certificate)
+	}
+
+	if apiParameter.Cacert != "" {
+		restyClient.SetRootCertificate(apiParameter.Cacert)
+	}
+
 	result := &GatewayClient{
 		cdkGatewayUser:     apiParameter.CdkGatewayUser,
 		cdkGatewayPassword: apiParameter.CdkGatewayPassword,
 		baseURL:            apiParameter.BaseURL,
 		client:             restyClient,
 		schemaCatalog:      nil,
+		authMethod:         authMethod,
+		connection: Connection{
+			BaseURL:  apiParameter.BaseURL,
+			Cacert:   apiParameter.Cacert,
+			Cert:     apiParameter.Cert,
+			Key:      apiParameter.Key,
+			Insecure: apiParameter.Insecure,
+		},
 	}
 
-	result.client.SetTLSClientConfig(&tls.Config{InsecureSkipVerify: true})
+	if apiParameter.Insecure {
+		result.IgnoreUntrustedCertificate()
+	}
+	maskDebugLogs(restyClient, func() *schema.Catalog { return result.schemaCatalog }, &result.sendingSecrets)
 	result.client.SetDisableWarn(true)
-	result.client.SetBasicAuth(apiParameter.CdkGatewayUser, apiParameter.CdkGatewayPassword)
+	if apiParameter.ExecCommand == "" {
+		result.client.SetBasicAuth(apiParameter.CdkGatewayUser, apiParameter.CdkGatewayPassword)
+	}
 
-	err := result.initCatalogFromAPI()
-	if err != nil {
-		if apiParameter.Debug {
-			fmt.Fprintf(os.Stderr, "Cannot access the Gateway Conduktor API: %s\nUsing offline defaults.\n", err)
-		}
-		result.schemaCatalog = schema.GatewayDefaultCatalog()
+	result.schemaCatalog, result.catalogInfo = result.catalogBackend().load(apiParameter.Catalog)
+	if result.catalogInfo.Error != nil && apiParameter.Debug {
+		fmt.Fprintf(os.Stderr, "Cannot access the Gateway Conduktor API: %s\nUsing %s catalog.\n", result.catalogInfo.Error, result.catalogInfo.Source)
 	}
 
 	return result, nil
 }
 
 func MakeGatewayClientFromEnv() (*GatewayClient, error) {
+	return MakeGatewayClientFromEnvLookup(os.Getenv)
+}
+
+// MakeGatewayClientFromEnvLookup creates a client from the CDK_GATEWAY_* variables returned by getenv, e.g. read from an env file.
+// TLS settings that are not set for the Gateway fall back to the Console ones (CDK_CACERT, CDK_CERT, CDK_KEY and CDK_INSECURE).
+func MakeGatewayClientFromEnvLookup(getenv func(string) string) (*GatewayClient, error) {
 	apiParameter := GatewayAPIParameter{
-		BaseURL:            os.Getenv("CDK_GATEWAY_BASE_URL"),
-		Debug:              utils.CdkDebug(),
-		CdkGatewayUser:     os.Getenv("CDK_GATEWAY_USER"),
-		CdkGatewayPassword: os.Getenv("CDK_GATEWAY_PASSWORD"),
+		BaseURL:            getenv("CDK_GATEWAY_BASE_URL"),
+		Debug:              utils.CdkDebugFromEnvLookup(getenv),
+		CdkGatewayUser:     getenv("CDK_GATEWAY_USER"),
+		CdkGatewayPassword: getenv("CDK_GATEWAY_PASSWORD"),
+		Key:                getenv("CDK_GATEWAY_KEY"),
+		Cert:               getenv("CDK_GATEWAY_CERT"),
+		Cacert:             getenvOrFallback(getenv, "CDK_GATEWAY_CACERT", "CDK_CACERT"),
+		Insecure:           strings.ToLower(getenvOrFallback(getenv, "CDK_GATEWAY_INSECURE", "CDK_INSECURE")) == "true",
+		ExecCommand:        getenv("CDK_GATEWAY_AUTH_EXEC"),
+	}
+	// the client certificate and its key go together, so they only fall back as a pair
+	if apiParameter.Key == "" && apiParameter.Cert == "" {
+		apiParameter.Key = getenv("CDK_KEY")
+		apiParameter.Cert = getenv("CDK_CERT")
+	}
+	catalogOptions, err := CatalogOptionsFromEnv(getenv)
+	if err != nil {
+		return nil, err
 	}
+	apiParameter.Catalog = catalogOptions
 
 	client, err := MakeGateway(apiParameter)
 	if err != nil {
@@ -76,6 +149,17 @@ func MakeGatewayClientFromEnv() (*GatewayClient, error) {
 	return client, nil
 }
 
+func getenvOrFallback(getenv func(string) string, name, fallback string) string {
+	if value := getenv(name); value != "" {
+		return value
+	}
+	return getenv(fallback)
+}
+
+func (client *GatewayClient) IgnoreUntrustedCertificate() {
+	client.client.SetTLSClientConfig(&tls.Config{InsecureSkipVerify: true})
+}
+
 func (client *GatewayClient) Get(kind *schema.Kind, parentPathValue []string, parentQueryValue []string, queryParams map[string]string) ([]resource.Resource, error) {
 	var result []resource.Resource
 	queryInfo := kind.ListPath(parentPathValue, parentQueryValue)
@@ -353,6 +437,7 @@ func (client *GatewayClient) Apply(resource *resource.Resource, dryMode bool, di
 		return result, err
 	}
 	url := client.baseURL + applyQueryInfo.Path
+	defer client.sendingSecrets.add(resource)()
 	builder := client.client.R().SetBody(resource.Json)
 	for _, param := range applyQueryInfo.QueryParams {
 		builder = builder.SetQueryParam(param.Name, param.Value)
@@ -362,10 +447,14 @@ func (client *GatewayClient) Apply(resource *resource.Resource, dryMode bool, di
 	}
 	if diffMode {
 		currentRes, err := client.GetFromResource(resource)
-		if err != nil {
+		if err != nil && !errors.Is(err, ErrResourceNotFound) {
 			return result, err
 		}
-		diff, err := utils.DiffResources(&currentRes, resource)
+		diff, err := utils.DiffResourcesWithOptions(&currentRes, resource, utils.DiffOptions{
+			Context:         utils.DefaultDiffContext,
+			ArrayOrdering:   kind.GetLatestKindVersion().GetArrayOrdering(),
+			SensitiveFields: kind.GetLatestKindVersion().GetSensitiveFields(),
+		})
 		if err != nil {
 			return result, err
 		}
@@ -399,21 +488,37 @@ func (client *GatewayClient) GetOpenAPI() ([]byte, error) {
 	return resp.Body(), nil
 }
 
-func (client *GatewayClient) initCatalogFromAPI() error {
-	data, err := client.GetOpenAPI()
-	if err != nil {
-		return fmt.Errorf("Cannot get openapi: %s", err)
-	}
-	schema, err := schema.NewOpenAPIParser(data)
+func (client *GatewayClient) catalogBackend() catalogBackend {
+	backend := gatewayCatalogBackend(client.baseURL)
+	backend.fetch = client.GetOpenAPI
+	return backend
+}
+
+// RefreshCatalog downloads the catalog and replaces the cached one.
+func (client *GatewayClient) RefreshCatalog() (CatalogInfo, error) {
+	info := client.catalogInfo
+	info.Error = nil
+	catalog, info, err := client.catalogBackend().refresh(info, true)
 	if err != nil {
-		return fmt.Errorf("Cannot parse openapi: %s", err)
+		return info, err
 	}
-	strict := false
-	client.schemaCatalog, err = schema.GetGatewayCatalog(strict)
-	if err != nil {
-		return fmt.Errorf("annot extract schemaCatalog from openapi: %s", err)
+	client.schemaCatalog, client.catalogInfo = catalog, info
+	return info, nil
+}
+
+// RefreshCachedCatalog downloads the catalog again if the one in use was cached, e.g. when it lacks the kind of a resource
+// because the server was upgraded since. It returns true if the catalog was replaced.
+func (client *GatewayClient) RefreshCachedCatalog() bool {
+	if !client.catalogInfo.refreshable() {
+		return false
 	}
-	return nil
+	_, err := client.RefreshCatalog()
+	return err == nil
+}
+
+// CatalogInfo tells where the catalog in use comes from.
+func (client *GatewayClient) CatalogInfo() CatalogInfo {
+	return client.catalogInfo
 }
 
 func (client *GatewayClient) GetKinds() schema.KindCatalog {
@@ -462,5 +567,5 @@ func (client *GatewayClient) GetFromResource(res *resource.Resource) (resource.R
 			return element, nil
 		}
 	}
-	return resource.Resource{}, fmt.Errorf("could not find any matching resource")
+	return resource.Resource{}, ErrResourceNotFound
 }
@@ -1,8 +1,21 @@
 package client
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/conduktor/ctl/pkg/resource"
 	"github.com/conduktor/ctl/pkg/schema"
@@ -322,3 +335,195 @@ func TestGwRunShouldWork(t *testing.T) {
 		t.Errorf("Bad result expected somebody got: %s", body)
 	}
 }
+
+func newGatewayTLSServer(t *testing.T, clientCA *x509.Certificate) (*httptest.Server, string) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	if clientCA != nil {
+		pool := x509.NewCertPool()
+		pool.AddCert(clientCA)
+		server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: pool}
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	caPath := filepath.Join(t.TempDir(), "ca.crt")
+	writePem(t, caPath, "CERTIFICATE", server.Certificate().Raw)
+	return server, caPath
+}
+
+func writePem(t *testing.T, path, blockType string, bytes []byte) {
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: bytes}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newClientCertificate writes a self-signed client certificate and its key and returns their paths.
+func newClientCertificate(t *testing.T) (*x509.Certificate, string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "conduktor-cli"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	certPath, keyPath := filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	writePem(t, certPath, "CERTIFICATE", der)
+	writePem(t, keyPath, "PRIVATE KEY", keyDer)
+	return certificate, certPath, keyPath
+}
+
+func listVirtualClusters(gatewayClient *GatewayClient) error {
+	kind := gatewayClient.GetKinds()["VirtualCluster"]
+	_, err := gatewayClient.Get(&kind, nil, nil, nil)
+	return err
+}
+
+func TestGwShouldVerifyServerCertificate(t *testing.T) {
+	server, caPath := newGatewayTLSServer(t, nil)
+	parameter := GatewayAPIParameter{
+		BaseURL:            server.URL,
+		CdkGatewayUser:     "admin",
+		CdkGatewayPassword: "conduktor",
+	}
+
+	gatewayClient, err := MakeGateway(parameter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := listVirtualClusters(gatewayClient); err == nil {
+		t.Error("Expected an untrusted certificate error")
+	}
+
+	parameter.Cacert = caPath
+	gatewayClient, err = MakeGateway(parameter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := listVirtualClusters(gatewayClient); err != nil {
+		t.Errorf("Expected the CA bundle to be trusted, got: %s", err)
+	}
+
+	parameter.Cacert = ""
+	parameter.Insecure = true
+	gatewayClient, err = MakeGateway(parameter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := listVirtualClusters(gatewayClient); err != nil {
+		t.Errorf("Expected insecure mode to skip verification, got: %s", err)
+	}
+}
+
+func TestGwShouldUseClientCertificate(t *testing.T) {
+	certificate, certPath, keyPath := newClientCertificate(t)
+	server, caPath := newGatewayTLSServer(t, certificate)
+	parameter := GatewayAPIParameter{
+		BaseURL:            server.URL,
+		CdkGatewayUser:     "admin",
+		CdkGatewayPassword: "conduktor",
+		Cacert:             caPath,
+	}
+
+	gatewayClient, err := MakeGateway(parameter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := listVirtualClusters(gatewayClient); err == nil {
+		t.Error("Expected the server to require a client certificate")
+	}
+
+	parameter.Cert = certPath
+	parameter.Key = keyPath
+	gatewayClient, err = MakeGateway(parameter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := listVirtualClusters(gatewayClient); err != nil {
+		t.Errorf("Expected the client certificate to be accepted, got: %s", err)
+	}
+}
+
+func TestGwShouldRejectIncompleteClientCertificate(t *testing.T) {
+	_, certPath, keyPath := newClientCertificate(t)
+	parameter := GatewayAPIParameter{
+		BaseURL:            "https://baseURL",
+		CdkGatewayUser:     "admin",
+		CdkGatewayPassword: "conduktor",
+		Cert:               certPath,
+	}
+
+	_, err := MakeGateway(parameter)
+	if err == nil || err.Error() != "CDK_GATEWAY_KEY and CDK_GATEWAY_CERT must be provided together" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	parameter.Cert = keyPath
+	parameter.Key = keyPath
+	if _, err = MakeGateway(parameter); err == nil {
+		t.Error("Expected an invalid certificate error")
+	}
+}
+
+func TestGwTLSSettingsShouldFallBackToConsole(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	certificate, certPath, keyPath := newClientCertificate(t)
+	server, caPath := newGatewayTLSServer(t, certificate)
+	env := map[string]string{
+		"CDK_GATEWAY_BASE_URL": server.URL,
+		"CDK_GATEWAY_USER":     "admin",
+		"CDK_GATEWAY_PASSWORD": "conduktor",
+		"CDK_CACERT":           caPath,
+		"CDK_CERT":             certPath,
+		"CDK_KEY":              keyPath,
+	}
+	getenv := func(name string) string { return env[name] }
+
+	gatewayClient, err := MakeGatewayClientFromEnvLookup(getenv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := listVirtualClusters(gatewayClient); err != nil {
+		t.Errorf("Expected the Console TLS settings to be used, got: %s", err)
+	}
+
+	// Gateway settings take precedence over the Console ones
+	env["CDK_GATEWAY_CACERT"] = certPath
+	gatewayClient, err = MakeGatewayClientFromEnvLookup(getenv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := listVirtualClusters(gatewayClient); err == nil {
+		t.Error("Expected CDK_GATEWAY_CACERT to override CDK_CACERT")
+	}
+
+	env["CDK_INSECURE"] = "true"
+	env["CDK_GATEWAY_INSECURE"] = "false"
+	gatewayClient, err = MakeGatewayClientFromEnvLookup(getenv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := listVirtualClusters(gatewayClient); err == nil {
+		t.Error("Expected CDK_GATEWAY_INSECURE to override CDK_INSECURE")
+	}
+}
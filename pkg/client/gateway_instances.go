@@ -0,0 +1,63 @@
+package client
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var gatewayInstanceNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`)
+
+// GatewayInstanceNames returns the named Gateway instances of CDK_GATEWAY_INSTANCES, e.g. eu,us,
+// configured next to the default Gateway of CDK_GATEWAY_BASE_URL.
+func GatewayInstanceNames(getenv func(string) string) ([]string, error) {
+	var names []string
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(getenv("CDK_GATEWAY_INSTANCES"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !gatewayInstanceNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid Gateway instance %s in CDK_GATEWAY_INSTANCES, expected letters, digits, - and _", name)
+		}
+		envName := gatewayInstanceEnvName(name)
+		if seen[envName] {
+			return nil, fmt.Errorf("duplicate Gateway instance %s in CDK_GATEWAY_INSTANCES", name)
+		}
+		seen[envName] = true
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// GatewayInstanceEnvLookup returns a getenv reading the variables of a named Gateway instance in place of the
+// CDK_GATEWAY_* ones, e.g. CDK_GATEWAY_EU_BASE_URL for CDK_GATEWAY_BASE_URL, the other variables being unchanged.
+func GatewayInstanceEnvLookup(getenv func(string) string, name string) func(string) string {
+	prefix := gatewayInstanceEnvPrefix(name)
+	return func(variable string) string {
+		if setting, ok := strings.CutPrefix(variable, "CDK_GATEWAY_"); ok {
+			return getenv(prefix + setting)
+		}
+		return getenv(variable)
+	}
+}
+
+// MakeGatewayInstanceClientFromEnvLookup creates the client of a named Gateway instance from its CDK_GATEWAY_<NAME>_* variables,
+// see GatewayInstanceEnvLookup.
+func MakeGatewayInstanceClientFromEnvLookup(getenv func(string) string, name string) (*GatewayClient, error) {
+	client, err := MakeGatewayClientFromEnvLookup(GatewayInstanceEnvLookup(getenv, name))
+	if err != nil {
+		// name the variables of the instance, e.g. Please set CDK_GATEWAY_EU_BASE_URL
+		return nil, fmt.Errorf("Gateway instance %s: %s", name, strings.ReplaceAll(err.Error(), "CDK_GATEWAY_", gatewayInstanceEnvPrefix(name)))
+	}
+	return client, nil
+}
+
+func gatewayInstanceEnvName(name string) string {
+	return strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+func gatewayInstanceEnvPrefix(name string) string {
+	return "CDK_GATEWAY_" + gatewayInstanceEnvName(name) + "_"
+}
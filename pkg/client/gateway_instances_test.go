@@ -0,0 +1,67 @@
+package client
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGatewayInstanceNames(t *testing.T) {
+	testCases := []struct {
+		instances string
+		expected  []string
+		err       string
+	}{
+		{instances: "", expected: nil},
+		{instances: "eu, us-east ,", expected: []string{"eu", "us-east"}},
+		{instances: "eu,eu west", err: "invalid Gateway instance eu west"},
+		{instances: "us-east,us_east", err: "duplicate Gateway instance us_east"},
+	}
+	for _, testCase := range testCases {
+		getenv := func(name string) string {
+			if name == "CDK_GATEWAY_INSTANCES" {
+				return testCase.instances
+			}
+			return ""
+		}
+		names, err := GatewayInstanceNames(getenv)
+		if testCase.err != "" {
+			if err == nil || !strings.Contains(err.Error(), testCase.err) {
+				t.Errorf("%q: expected error %s, got %v", testCase.instances, testCase.err, err)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(names, testCase.expected) {
+			t.Errorf("%q: expected %v, got %v %v", testCase.instances, testCase.expected, names, err)
+		}
+	}
+}
+
+func TestGatewayInstanceEnvLookup(t *testing.T) {
+	env := map[string]string{
+		"CDK_GATEWAY_BASE_URL":         "http://gateway",
+		"CDK_GATEWAY_US_EAST_BASE_URL": "http://us-east",
+		"CDK_GATEWAY_US_EAST_USER":     "admin",
+		"CDK_CACERT":                   "/ca.pem",
+	}
+	getenv := GatewayInstanceEnvLookup(func(name string) string { return env[name] }, "us-east")
+
+	expected := map[string]string{
+		"CDK_GATEWAY_BASE_URL": "http://us-east",
+		"CDK_GATEWAY_USER":     "admin",
+		"CDK_GATEWAY_PASSWORD": "",
+		"CDK_CACERT":           "/ca.pem",
+	}
+	for name, value := range expected {
+		if actual := getenv(name); actual != value {
+			t.Errorf("%s: expected %q, got %q", name, value, actual)
+		}
+	}
+}
+
+func TestMakeGatewayInstanceClientNamesTheInstanceVariables(t *testing.T) {
+	_, err := MakeGatewayInstanceClientFromEnvLookup(func(string) string { return "" }, "eu")
+	if err == nil || !strings.Contains(err.Error(), "Please set CDK_GATEWAY_EU_BASE_URL") {
+		t.Errorf("expected the error to name CDK_GATEWAY_EU_BASE_URL, got %v", err)
+	}
+}
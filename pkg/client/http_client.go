@@ -0,0 +1,59 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// NewHTTPClientFromEnv returns an HTTP client with the TLS settings of the Console client, CDK_CACERT, CDK_CERT, CDK_KEY
+// and CDK_INSECURE, e.g. to fetch resource files. If these settings are invalid, every request fails with their error.
+func NewHTTPClientFromEnv() *http.Client {
+	return newHTTPClient(os.Getenv)
+}
+
+func newHTTPClient(getenv func(string) string) *http.Client {
+	tlsConfig, err := tlsConfigFromEnvLookup(getenv)
+	if err != nil {
+		return &http.Client{Transport: failingTransport{err}}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Transport: transport}
+}
+
+func tlsConfigFromEnvLookup(getenv func(string) string) (*tls.Config, error) {
+	config := &tls.Config{InsecureSkipVerify: strings.ToLower(getenv("CDK_INSECURE")) == "true"}
+	key, cert := getenv("CDK_KEY"), getenv("CDK_CERT")
+	if (key == "") != (cert == "") {
+		return nil, fmt.Errorf("CDK_KEY and CDK_CERT must be provided together")
+	} else if key != "" {
+		certificate, err := tls.LoadX509KeyPair(cert, key)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+	if cacert := getenv("CDK_CACERT"); cacert != "" {
+		pem, err := os.ReadFile(cacert)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read CDK_CACERT: %s", err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in CDK_CACERT %s", cacert)
+		}
+	}
+	return config, nil
+}
+
+type failingTransport struct {
+	err error
+}
+
+func (t failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, t.err
+}
@@ -0,0 +1,30 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPClientShouldUseTheTLSSettingsOfTheConsole(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	env := map[string]string{}
+	getenv := func(key string) string { return env[key] }
+	if _, err := newHTTPClient(getenv).Get(server.URL); err == nil {
+		t.Error("Expected the self-signed certificate to be rejected")
+	}
+
+	env["CDK_INSECURE"] = "true"
+	if _, err := newHTTPClient(getenv).Get(server.URL); err != nil {
+		t.Errorf("Expected CDK_INSECURE to skip the verification got %v", err)
+	}
+
+	env["CDK_CERT"] = "cert.pem"
+	_, err := newHTTPClient(getenv).Get(server.URL)
+	if err == nil || !strings.Contains(err.Error(), "CDK_KEY and CDK_CERT must be provided together") {
+		t.Errorf("Expected the invalid settings to fail the request got %v", err)
+	}
+}
@@ -0,0 +1,246 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// ssoConfigPath is where the Console advertises the identity provider the CLI logs in with.
+const ssoConfigPath = "/login/sso/cli"
+
+var defaultSSOScopes = []string{"openid", "profile", "email", "offline_access"}
+
+// SSOConfig is the OIDC client the Console advertises for the CLI.
+type SSOConfig struct {
+	Issuer   string   `json:"issuer"`
+	ClientID string   `json:"clientId"`
+	Scopes   []string `json:"scopes"`
+}
+
+// OIDCProvider holds the endpoints of the identity provider, read from its discovery document.
+type OIDCProvider struct {
+	AuthorizationEndpoint       string `json:"authorization_endpoint"`
+	TokenEndpoint               string `json:"token_endpoint"`
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+}
+
+// GetSSOConfig returns the identity provider advertised by the Console.
+func (client *Client) GetSSOConfig() (SSOConfig, error) {
+	resp, err := client.client.R().Get(client.baseURL + ssoConfigPath)
+	if err != nil {
+		return SSOConfig{}, err
+	} else if resp.StatusCode() == http.StatusNotFound {
+		return SSOConfig{}, fmt.Errorf("the Console does not advertise an SSO identity provider for the CLI")
+	} else if resp.IsError() {
+		return SSOConfig{}, fmt.Errorf("%s", extractAPIError(resp))
+	}
+	result := SSOConfig{}
+	err = json.Unmarshal(resp.Body(), &result)
+	if err != nil {
+		return SSOConfig{}, err
+	}
+	if result.Issuer == "" || result.ClientID == "" {
+		return SSOConfig{}, fmt.Errorf("the Console SSO configuration lacks an issuer or a client id")
+	}
+	if len(result.Scopes) == 0 {
+		result.Scopes = defaultSSOScopes
+	}
+	return result, nil
+}
+
+// DiscoverOIDC reads the discovery document of an OIDC issuer.
+func DiscoverOIDC(ctx context.Context, issuer string) (OIDCProvider, error) {
+	url := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return OIDCProvider{}, err
+	}
+	resp, err := oidcHTTPClient(ctx).Do(req)
+	if err != nil {
+		return OIDCProvider{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return OIDCProvider{}, fmt.Errorf("could not read the OIDC discovery document %s: %s", url, resp.Status)
+	}
+	result := OIDCProvider{}
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return OIDCProvider{}, fmt.Errorf("invalid OIDC discovery document %s: %s", url, err)
+	}
+	if result.TokenEndpoint == "" {
+		return OIDCProvider{}, fmt.Errorf("the OIDC discovery document %s lacks a token endpoint", url)
+	}
+	return result, nil
+}
+
+func oidcHTTPClient(ctx context.Context) *http.Client {
+	if httpClient, ok := ctx.Value(oauth2.HTTPClient).(*http.Client); ok {
+		return httpClient
+	}
+	return http.DefaultClient
+}
+
+// OAuth2Config builds the configuration of a public OAuth2 client of the identity provider.
+func OAuth2Config(config SSOConfig, provider OIDCProvider) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID: config.ClientID,
+		Scopes:   config.Scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:       provider.AuthorizationEndpoint,
+			TokenURL:      provider.TokenEndpoint,
+			DeviceAuthURL: provider.DeviceAuthorizationEndpoint,
+			AuthStyle:     oauth2.AuthStyleInParams,
+		},
+	}
+}
+
+// DeviceLogin runs the OAuth2 device authorization grant, prompt shows the user where to enter the code.
+func DeviceLogin(ctx context.Context, config *oauth2.Config, prompt func(*oauth2.DeviceAuthResponse)) (*oauth2.Token, error) {
+	if config.Endpoint.DeviceAuthURL == "" {
+		return nil, fmt.Errorf("the identity provider does not support the device authorization grant")
+	}
+	deviceAuth, err := config.DeviceAuth(ctx)
+	if err != nil {
+		return nil, err
+	}
+	prompt(deviceAuth)
+	return config.DeviceAccessToken(ctx, deviceAuth)
+}
+
+type authorizationCallback struct {
+	code string
+	err  error
+}
+
+// BrowserLogin runs the OAuth2 authorization code grant with PKCE, receiving the code on a loopback redirect URL.
+// openURL is called with the URL the user has to visit.
+func BrowserLogin(ctx context.Context, config *oauth2.Config, openURL func(string) error) (*oauth2.Token, error) {
+	if config.Endpoint.AuthURL == "" {
+		return nil, fmt.Errorf("the identity provider does not support the authorization code grant")
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	loopbackConfig := *config
+	loopbackConfig.RedirectURL = fmt.Sprintf("http://%s/callback", listener.Addr())
+
+	verifier := oauth2.GenerateVerifier()
+	state := oauth2.GenerateVerifier()
+	callbacks := make(chan authorizationCallback, 1)
+	server := &http.Server{
+		ReadHeaderTimeout: 10 * time.Second,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/callback" {
+				http.NotFound(w, r)
+				return
+			}
+			query := r.URL.Query()
+			var callback authorizationCallback
+			if query.Get("state") != state {
+				callback.err = fmt.Errorf("invalid state in the authorization callback")
+			} else if query.Get("error") != "" {
+				callback.err = fmt.Errorf("authorization failed: %s %s", query.Get("error"), query.Get("error_description"))
+			} else {
+				callback.code = query.Get("code")
+			}
+			if callback.err != nil {
+				http.Error(w, "Login failed, you can close this window.", http.StatusBadRequest)
+			} else {
+				fmt.Fprintln(w, "Login succeeded, you can close this window.")
+			}
+			select {
+			case callbacks <- callback:
+			default:
+			}
+		}),
+	}
+	go func() { _ = server.Serve(listener) }()
+	defer server.Close()
+
+	err = openURL(loopbackConfig.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier)))
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case callback := <-callbacks:
+		if callback.err != nil {
+			return nil, callback.err
+		}
+		return loopbackConfig.Exchange(ctx, callback.code, oauth2.VerifierOption(verifier))
+	case <-ctx.Done():
+		return nil, fmt.Errorf("no authorization received: %w", ctx.Err())
+	}
+}
+
+// NewSSOSession builds a session from the token of the identity provider.
+// Its access token is sent to the Console as a bearer token and it is refreshed against the identity provider.
+func NewSSOSession(baseURL string, config *oauth2.Config, token *oauth2.Token) Session {
+	return Session{
+		BaseURL:      uniformizeBaseURL(baseURL),
+		Username:     tokenSubject(token),
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		ExpiresAt:    token.Expiry,
+		TokenURL:     config.Endpoint.TokenURL,
+		ClientID:     config.ClientID,
+		Scopes:       config.Scopes,
+	}
+}
+
+// tokenSubject returns the user the id token was issued to, only to display it: the token is not verified.
+func tokenSubject(token *oauth2.Token) string {
+	idToken, _ := token.Extra("id_token").(string)
+	parts := strings.Split(idToken, ".")
+	if len(parts) != 3 {
+		return "sso"
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "sso"
+	}
+	var claims map[string]interface{}
+	if json.Unmarshal(payload, &claims) != nil {
+		return "sso"
+	}
+	for _, claim := range []string{"email", "preferred_username", "sub"} {
+		if value, ok := claims[claim].(string); ok && value != "" {
+			return value
+		}
+	}
+	return "sso"
+}
+
+// refreshSSOSession gets a new access token from the identity provider that issued the session.
+func refreshSSOSession(ctx context.Context, session Session) (Session, error) {
+	config := &oauth2.Config{
+		ClientID: session.ClientID,
+		Scopes:   session.Scopes,
+		Endpoint: oauth2.Endpoint{TokenURL: session.TokenURL, AuthStyle: oauth2.AuthStyleInParams},
+	}
+	token, err := config.TokenSource(ctx, &oauth2.Token{RefreshToken: session.RefreshToken, Expiry: time.Unix(1, 0)}).Token()
+	if err != nil {
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) && retrieveErr.ErrorCode != "" {
+			return Session{}, fmt.Errorf("could not refresh the SSO session: %s", retrieveErr.ErrorCode)
+		}
+		return Session{}, err
+	}
+	refreshed := session
+	refreshed.AccessToken = token.AccessToken
+	refreshed.ExpiresAt = token.Expiry
+	if token.RefreshToken != "" {
+		refreshed.RefreshToken = token.RefreshToken
+	}
+	return refreshed, nil
+}
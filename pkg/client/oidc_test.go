@@ -0,0 +1,236 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// stubIdP is a minimal OIDC identity provider supporting the authorization code grant with PKCE,
+// the device authorization grant and refresh tokens.
+type stubIdP struct {
+	server    *httptest.Server
+	mutex     sync.Mutex
+	challenge string
+	polls     int
+	refreshes int
+}
+
+func newStubIdP(t *testing.T) *stubIdP {
+	idp := &stubIdP{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{
+			"issuer":                        idp.server.URL,
+			"authorization_endpoint":        idp.server.URL + "/authorize",
+			"token_endpoint":                idp.server.URL + "/token",
+			"device_authorization_endpoint": idp.server.URL + "/device",
+		})
+	})
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("client_id") != "cli" || query.Get("code_challenge_method") != "S256" {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+		idp.mutex.Lock()
+		idp.challenge = query.Get("code_challenge")
+		idp.mutex.Unlock()
+		http.Redirect(w, r, query.Get("redirect_uri")+"?code=the-code&state="+url.QueryEscape(query.Get("state")), http.StatusFound)
+	})
+	mux.HandleFunc("/device", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"device_code":      "the-device-code",
+			"user_code":        "ABCD-EFGH",
+			"verification_uri": idp.server.URL + "/activate",
+			"expires_in":       60,
+			"interval":         1,
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		idp.mutex.Lock()
+		defer idp.mutex.Unlock()
+		if r.Form.Get("client_id") != "cli" {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+			return
+		}
+		switch r.Form.Get("grant_type") {
+		case "authorization_code":
+			if r.Form.Get("code") != "the-code" || oauth2.S256ChallengeFromVerifier(r.Form.Get("code_verifier")) != idp.challenge {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+				return
+			}
+			writeJSON(w, http.StatusOK, idp.token("browser-token"))
+		case "urn:ietf:params:oauth:grant-type:device_code":
+			idp.polls++
+			if idp.polls == 1 {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "authorization_pending"})
+				return
+			}
+			writeJSON(w, http.StatusOK, idp.token("device-token"))
+		case "refresh_token":
+			if r.Form.Get("refresh_token") != "the-refresh-token" {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+				return
+			}
+			idp.refreshes++
+			writeJSON(w, http.StatusOK, idp.token(fmt.Sprintf("refreshed-token-%d", idp.refreshes)))
+		default:
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		}
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func (idp *stubIdP) token(accessToken string) map[string]interface{} {
+	claims, _ := json.Marshal(map[string]string{"sub": "42", "email": "jane@example.com"})
+	return map[string]interface{}{
+		"access_token":  accessToken,
+		"refresh_token": "the-refresh-token",
+		"token_type":    "Bearer",
+		"expires_in":    3600,
+		"id_token":      "e30." + base64.RawURLEncoding.EncodeToString(claims) + ".signature",
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// newSSOConsole returns a Console client advertising the stub identity provider.
+func newSSOConsole(t *testing.T, idp *stubIdP) *Client {
+	console := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api"+ssoConfigPath {
+			http.NotFound(w, r)
+			return
+		}
+		writeJSON(w, http.StatusOK, SSOConfig{Issuer: idp.server.URL, ClientID: "cli"})
+	}))
+	t.Cleanup(console.Close)
+	client, err := Make(APIParameter{BaseURL: console.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func ssoOAuth2Config(t *testing.T, client *Client) *oauth2.Config {
+	ssoConfig, err := client.GetSSOConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ssoConfig.Scopes) != len(defaultSSOScopes) {
+		t.Errorf("Expected the default scopes got %v", ssoConfig.Scopes)
+	}
+	provider, err := DiscoverOIDC(context.Background(), ssoConfig.Issuer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return OAuth2Config(ssoConfig, provider)
+}
+
+func TestBrowserLoginWithPKCE(t *testing.T) {
+	idp := newStubIdP(t)
+	client := newSSOConsole(t, idp)
+	config := ssoOAuth2Config(t, client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	token, err := BrowserLogin(ctx, config, func(authURL string) error {
+		// the browser follows the redirection of the identity provider to the loopback callback
+		go func() {
+			resp, err := http.Get(authURL)
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := NewSSOSession(client.baseURL, config, token)
+	if session.AccessToken != "browser-token" || session.Username != "jane@example.com" || session.TokenURL != idp.server.URL+"/token" {
+		t.Errorf("Unexpected session %+v", session)
+	}
+}
+
+func TestBrowserLoginShouldRejectInvalidState(t *testing.T) {
+	idp := newStubIdP(t)
+	config := ssoOAuth2Config(t, newSSOConsole(t, idp))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := BrowserLogin(ctx, config, func(authURL string) error {
+		parsed, _ := url.Parse(authURL)
+		go func() {
+			resp, err := http.Get(parsed.Query().Get("redirect_uri") + "?code=the-code&state=forged")
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+		return nil
+	})
+	if err == nil {
+		t.Error("Expected a forged state to be rejected")
+	}
+}
+
+func TestDeviceLogin(t *testing.T) {
+	idp := newStubIdP(t)
+	config := ssoOAuth2Config(t, newSSOConsole(t, idp))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var userCode string
+	token, err := DeviceLogin(ctx, config, func(deviceAuth *oauth2.DeviceAuthResponse) {
+		userCode = deviceAuth.UserCode
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if userCode != "ABCD-EFGH" || token.AccessToken != "device-token" {
+		t.Errorf("Unexpected device login %s %+v", userCode, token)
+	}
+}
+
+func TestSSOSessionShouldBeRefreshedAgainstIdP(t *testing.T) {
+	isolateConfigDir(t)
+	idp := newStubIdP(t)
+	client := newSSOConsole(t, idp)
+	config := ssoOAuth2Config(t, client)
+
+	session := NewSSOSession(client.baseURL, config, (&oauth2.Token{
+		AccessToken:  "expired-token",
+		RefreshToken: "the-refresh-token",
+		Expiry:       time.Now().Add(-time.Minute),
+	}).WithExtra(map[string]interface{}{}))
+	if err := SaveSession(session); err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, err := client.useCachedSession(); !ok || err != nil {
+		t.Fatalf("Expected the cached session to be used got %v", err)
+	}
+	if client.authMethod.AuthorizationHeader() != "Bearer refreshed-token-1" {
+		t.Errorf("Expected the refreshed token got %s", client.authMethod.AuthorizationHeader())
+	}
+	saved, _, _ := LoadSession(client.baseURL)
+	if saved.AccessToken != "refreshed-token-1" || saved.TokenURL == "" || saved.Username != "sso" {
+		t.Errorf("Expected the refreshed SSO session to be cached got %+v", saved)
+	}
+}
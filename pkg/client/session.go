@@ -0,0 +1,184 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+const sessionsSection = "sessions"
+
+const loginPath = "/login"
+
+// sessionRefreshMargin is how long before its expiry a cached access token is refreshed.
+const sessionRefreshMargin = time.Minute
+
+// Session is a login session cached for a Console base URL by conduktor login.
+type Session struct {
+	BaseURL      string `json:"baseUrl"`
+	Username     string `json:"username"`
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken,omitempty"`
+	// ExpiresAt is zero when the Console did not tell when the access token expires
+	ExpiresAt time.Time `json:"expiresAt"`
+	// TokenURL, ClientID and Scopes are set for SSO sessions, refreshed against the identity provider instead of the Console
+	TokenURL string   `json:"tokenUrl,omitempty"`
+	ClientID string   `json:"clientId,omitempty"`
+	Scopes   []string `json:"scopes,omitempty"`
+}
+
+func NewSession(baseURL, username string, login LoginResult) Session {
+	session := Session{
+		BaseURL:      uniformizeBaseURL(baseURL),
+		Username:     username,
+		AccessToken:  login.AccessToken,
+		RefreshToken: login.RefreshToken,
+	}
+	if login.ExpiresIn > 0 {
+		session.ExpiresAt = time.Now().Add(time.Duration(login.ExpiresIn) * time.Second)
+	}
+	return session
+}
+
+// NeedsRefresh tells whether the access token expires within the refresh margin.
+func (s Session) NeedsRefresh() bool {
+	return !s.ExpiresAt.IsZero() && time.Now().Add(sessionRefreshMargin).After(s.ExpiresAt)
+}
+
+func (s Session) Expired() bool {
+	return !s.ExpiresAt.IsZero() && time.Now().After(s.ExpiresAt)
+}
+
+// LoadSession returns the session cached for a base URL, if any.
+func LoadSession(baseURL string) (Session, bool, error) {
+	store, err := credentialStoreFromEnv()
+	if err != nil {
+		return Session{}, false, err
+	}
+	var session Session
+	ok, err := store.load(sessionsSection, uniformizeBaseURL(baseURL), &session)
+	return session, ok, err
+}
+
+// SaveSession caches a session, replacing the one of the same base URL.
+func SaveSession(session Session) error {
+	store, err := credentialStoreFromEnv()
+	if err != nil {
+		return err
+	}
+	return store.save(sessionsSection, session.BaseURL, session)
+}
+
+// DeleteSession removes the session cached for a base URL and tells whether there was one.
+func DeleteSession(baseURL string) (bool, error) {
+	store, err := credentialStoreFromEnv()
+	if err != nil {
+		return false, err
+	}
+	return store.delete(sessionsSection, uniformizeBaseURL(baseURL))
+}
+
+// refreshSession renews a session before it expires: SSO sessions with their refresh token against the identity provider,
+// Console ones by logging in again with the credentials the client was created with, if any for the user of the session.
+func (client *Client) refreshSession(session Session) (Session, error) {
+	if session.TokenURL != "" {
+		if session.RefreshToken == "" {
+			return Session{}, fmt.Errorf("no refresh token")
+		}
+		refreshed, err := refreshSSOSession(context.Background(), session)
+		if err != nil {
+			return Session{}, err
+		}
+		saveSessionOrWarn(refreshed)
+		return refreshed, nil
+	}
+	if client.credentials == nil || client.credentials.Username != session.Username {
+		return Session{}, fmt.Errorf("no credentials of %s to login again", session.Username)
+	}
+	login, err := client.Login(client.credentials.Username, client.credentials.Password)
+	if err != nil {
+		return Session{}, err
+	}
+	refreshed := NewSession(client.baseURL, session.Username, login)
+	saveSessionOrWarn(refreshed)
+	return refreshed, nil
+}
+
+// loginWithCache reuses the session cached for the user unless it needs a refresh, and logs in otherwise.
+// The credentials are kept to login again before the session expires.
+func (client *Client) loginWithCache(username, password string) (Session, error) {
+	client.credentials = &BasicAuth{username, password}
+	session, ok, err := LoadSession(client.baseURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring cached login session: %s\n", err)
+	} else if ok && session.Username == username && !session.NeedsRefresh() {
+		return session, nil
+	}
+	login, err := client.Login(username, password)
+	if err != nil {
+		return Session{}, err
+	}
+	session = NewSession(client.baseURL, username, login)
+	saveSessionOrWarn(session)
+	return session, nil
+}
+
+// useCachedSession authenticates with the session cached by conduktor login, refreshing it if needed.
+// It returns false if there is none, and an error if it has expired and cannot be refreshed.
+func (client *Client) useCachedSession() (bool, error) {
+	session, ok, err := LoadSession(client.baseURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring cached login session: %s\n", err)
+		return false, nil
+	} else if !ok {
+		return false, nil
+	}
+	if session.NeedsRefresh() {
+		refreshed, err := client.refreshSession(session)
+		if err == nil {
+			session = refreshed
+		} else if session.Expired() {
+			return false, fmt.Errorf("login session of %s expired, please run conduktor login again: %s", session.Username, err)
+		}
+	}
+	client.useSession(session)
+	return true, nil
+}
+
+func (client *Client) useSession(session Session) {
+	client.session = &session
+	client.authMethod = BearerToken{session.AccessToken}
+	client.setAuthMethodInRestClient()
+}
+
+// refreshSessionIfNeeded is a request hook refreshing the session of long-running commands before it expires.
+func (client *Client) refreshSessionIfNeeded(_ *resty.Client, request *resty.Request) error {
+	if request.URL == client.baseURL+loginPath {
+		return nil
+	}
+	client.sessionMutex.Lock()
+	defer client.sessionMutex.Unlock()
+	if client.session == nil || !client.session.NeedsRefresh() {
+		return nil
+	}
+	session, err := client.refreshSession(*client.session)
+	if err != nil {
+		if client.session.Expired() {
+			return fmt.Errorf("login session expired, please run conduktor login again: %s", err)
+		}
+		// the current token is still valid for a little while
+		return nil
+	}
+	client.useSession(session)
+	request.SetHeader("Authorization", client.authMethod.AuthorizationHeader())
+	return nil
+}
+
+func saveSessionOrWarn(session Session) {
+	if err := SaveSession(session); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not cache the login session: %s\n", err)
+	}
+}
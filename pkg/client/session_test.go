@@ -0,0 +1,217 @@
+package client
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func isolateConfigDir(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("APPDATA", dir)
+	t.Setenv("CDK_CREDENTIALS_STORE", "")
+}
+
+func TestSessionShouldBeSavedLoadedAndDeleted(t *testing.T) {
+	isolateConfigDir(t)
+
+	session := NewSession("http://baseUrl", "admin", LoginResult{AccessToken: "access", RefreshToken: "refresh", ExpiresIn: 3600})
+	if err := SaveSession(session); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := CredentialsFilePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected credentials file mode 0600 got %s", info.Mode().Perm())
+	}
+
+	loaded, ok, err := LoadSession("http://baseUrl/api/")
+	if err != nil || !ok {
+		t.Fatalf("Expected a session, got %v %v", ok, err)
+	}
+	if loaded.Username != "admin" || loaded.AccessToken != "access" || loaded.RefreshToken != "refresh" || loaded.NeedsRefresh() {
+		t.Errorf("Unexpected session %+v", loaded)
+	}
+	if _, ok, _ := LoadSession("http://otherUrl"); ok {
+		t.Error("Sessions should be keyed by base URL")
+	}
+
+	deleted, err := DeleteSession("http://baseUrl")
+	if err != nil || !deleted {
+		t.Fatalf("Expected the session to be deleted, got %v %v", deleted, err)
+	}
+	if _, ok, _ := LoadSession("http://baseUrl"); ok {
+		t.Error("Session should be deleted")
+	}
+}
+
+func TestSessionExpiry(t *testing.T) {
+	noExpiry := NewSession("http://baseUrl", "admin", LoginResult{AccessToken: "access"})
+	if noExpiry.NeedsRefresh() || noExpiry.Expired() {
+		t.Error("A session without expiry should be used as is")
+	}
+	soon := Session{ExpiresAt: time.Now().Add(sessionRefreshMargin / 2)}
+	if !soon.NeedsRefresh() || soon.Expired() {
+		t.Error("A session expiring within the margin should be refreshed")
+	}
+}
+
+func TestLoginShouldReuseCachedSession(t *testing.T) {
+	isolateConfigDir(t)
+	defer httpmock.Reset()
+	client, err := Make(APIParameter{BaseURL: "http://baseUrl"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpmock.ActivateNonDefault(client.client.GetClient())
+	httpmock.RegisterResponder("POST", "http://baseUrl/api/login",
+		httpmock.NewStringResponder(200, `{"access_token":"access","refresh_token":"refresh","expires_in":3600}`))
+
+	for i := 0; i < 2; i++ {
+		session, err := client.loginWithCache("admin", "secret")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if session.AccessToken != "access" {
+			t.Errorf("Unexpected session %+v", session)
+		}
+	}
+	if count := httpmock.GetTotalCallCount(); count != 1 {
+		t.Errorf("Expected a single login call got %d", count)
+	}
+
+	// another user logs in again
+	if _, err := client.loginWithCache("other", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if count := httpmock.GetTotalCallCount(); count != 2 {
+		t.Errorf("Expected a second login call got %d", count)
+	}
+}
+
+func TestSessionAboutToExpireShouldLoginAgain(t *testing.T) {
+	isolateConfigDir(t)
+	defer httpmock.Reset()
+	err := SaveSession(Session{
+		BaseURL:     "http://baseUrl/api",
+		Username:    "admin",
+		AccessToken: "old",
+		ExpiresAt:   time.Now().Add(10 * time.Second),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := Make(APIParameter{BaseURL: "http://baseUrl"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpmock.ActivateNonDefault(client.client.GetClient())
+	httpmock.RegisterMatcherResponder("POST", "http://baseUrl/api/login",
+		httpmock.BodyContainsString(`"password":"secret"`),
+		httpmock.NewStringResponder(200, `{"access_token":"new","expires_in":3600}`))
+
+	session, err := client.loginWithCache("admin", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.AccessToken != "new" {
+		t.Errorf("Expected a new login got %+v", session)
+	}
+	saved, _, _ := LoadSession("http://baseUrl")
+	if saved.AccessToken != "new" || saved.NeedsRefresh() {
+		t.Errorf("Expected the new session to be cached got %+v", saved)
+	}
+}
+
+func TestCachedSessionWithoutCredentialsShouldBeUsedUntilExpiry(t *testing.T) {
+	isolateConfigDir(t)
+	defer httpmock.Reset()
+	err := SaveSession(Session{
+		BaseURL:     "http://baseUrl/api",
+		Username:    "admin",
+		AccessToken: "old",
+		ExpiresAt:   time.Now().Add(10 * time.Second),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := Make(APIParameter{BaseURL: "http://baseUrl"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpmock.ActivateNonDefault(client.client.GetClient())
+
+	if ok, err := client.useCachedSession(); !ok || err != nil {
+		t.Fatalf("Expected the cached session to be used got %v", err)
+	}
+	if client.authMethod.AuthorizationHeader() != "Bearer old" {
+		t.Errorf("Expected the cached token got %s", client.authMethod.AuthorizationHeader())
+	}
+	if count := httpmock.GetTotalCallCount(); count != 0 {
+		t.Errorf("Expected no call to the Console got %d", count)
+	}
+}
+
+func TestExpiredCachedSessionShouldFailTheClientCreation(t *testing.T) {
+	isolateConfigDir(t)
+	err := SaveSession(Session{
+		BaseURL:     "http://baseUrl/api",
+		Username:    "admin",
+		AccessToken: "old",
+		ExpiresAt:   time.Now().Add(-time.Minute),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{"CDK_BASE_URL": "http://baseUrl", "CDK_OFFLINE": "true"}
+
+	_, err = MakeFromEnvLookup(func(key string) string { return env[key] })
+
+	if err == nil || !strings.Contains(err.Error(), "login session of admin expired, please run conduktor login again") {
+		t.Errorf("Expected the expired session to fail the client creation got %v", err)
+	}
+	// conduktor login creates its client without the cached session
+	if _, err := Make(APIParameter{BaseURL: "http://baseUrl", Catalog: CatalogOptions{Offline: true}}); err != nil {
+		t.Errorf("Expected a client without the cached session got %v", err)
+	}
+}
+
+func TestRequestShouldLoginAgainWhenSessionIsAboutToExpire(t *testing.T) {
+	isolateConfigDir(t)
+	defer httpmock.Reset()
+	client, err := Make(APIParameter{BaseURL: "http://baseUrl"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpmock.ActivateNonDefault(client.client.GetClient())
+	httpmock.RegisterResponder("POST", "http://baseUrl/api/login",
+		httpmock.NewStringResponder(200, `{"access_token":"new","expires_in":3600}`))
+	httpmock.RegisterMatcherResponder("GET", "http://baseUrl/api/public/v1/something",
+		httpmock.HeaderIs("Authorization", "Bearer new"),
+		httpmock.NewStringResponder(200, `{}`))
+
+	client.credentials = &BasicAuth{"admin", "secret"}
+	client.useSession(Session{BaseURL: "http://baseUrl/api", Username: "admin", AccessToken: "old", ExpiresAt: time.Now().Add(time.Second)})
+	resp, err := client.client.R().Get("http://baseUrl/api/public/v1/something")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode() != 200 {
+		t.Errorf("Expected the request to use the new token, got %d", resp.StatusCode())
+	}
+	if client.session.AccessToken != "new" || client.session.NeedsRefresh() {
+		t.Errorf("Expected the new session got %+v", client.session)
+	}
+}
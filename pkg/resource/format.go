@@ -0,0 +1,172 @@
+package resource
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// FormatIndent is the indentation of formatted files.
+const FormatIndent = 2
+
+var canonicalResourceKeys = []string{"apiVersion", "kind", "metadata", "spec"}
+
+// KeyOrder is the order of the keys of a mapping, with the orders of the mappings under its keys and of the
+// elements of its lists, e.g. the order of the properties of a schema.
+type KeyOrder struct {
+	Keys   []string             `json:",omitempty"`
+	Fields map[string]*KeyOrder `json:",omitempty"`
+	Items  *KeyOrder            `json:",omitempty"`
+}
+
+// KeyOrderOf returns the key order of a YAML document, e.g. an apply example, nil if it is not a mapping.
+// The elements of a list are ordered as its first element.
+func KeyOrderOf(example string) *KeyOrder {
+	var document yaml.Node
+	if yaml.Unmarshal([]byte(example), &document) != nil || len(document.Content) == 0 {
+		return nil
+	}
+	return keyOrderOfNode(document.Content[0])
+}
+
+func keyOrderOfNode(node *yaml.Node) *KeyOrder {
+	switch {
+	case node.Kind == yaml.MappingNode:
+		order := &KeyOrder{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			order.Keys = append(order.Keys, key)
+			if field := keyOrderOfNode(node.Content[i+1]); field != nil {
+				if order.Fields == nil {
+					order.Fields = make(map[string]*KeyOrder)
+				}
+				order.Fields[key] = field
+			}
+		}
+		return order
+	case node.Kind == yaml.SequenceNode && len(node.Content) > 0:
+		if items := keyOrderOfNode(node.Content[0]); items != nil {
+			return &KeyOrder{Items: items}
+		}
+	}
+	return nil
+}
+
+// FormatYaml rewrites the resource documents of a YAML file in the canonical layout, keeping their comments:
+// apiVersion, kind, metadata and spec first, the keys of metadata and spec in the order given by order then the other
+// keys in their order, block style mappings and lists, quotes only where needed and literal multi-line strings.
+// order returns the key order of the resources of a kind, e.g. the one of its schema in the catalog, nil if the kind is unknown.
+// Data is returned as is if it holds no resource.
+func FormatYaml(data []byte, order func(kind, apiVersion string) *KeyOrder) ([]byte, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	var documents []*yaml.Node
+	hasResource := false
+	for {
+		document := &yaml.Node{}
+		err := decoder.Decode(document)
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		documents = append(documents, document)
+		if len(document.Content) == 0 {
+			continue
+		}
+		root := document.Content[0]
+		kind, apiVersion := mappingValue(root, "kind"), mappingValue(root, "apiVersion")
+		if kind == nil {
+			continue
+		}
+		hasResource = true
+		// the comment above the first key is the header of the document, it stays first
+		header := root.Content[0].HeadComment
+		root.Content[0].HeadComment = ""
+		orderKeys(root, canonicalResourceKeys)
+		if first := root.Content[0]; header != "" && first.HeadComment != "" {
+			first.HeadComment = header + "\n" + first.HeadComment
+		} else if header != "" {
+			first.HeadComment = header
+		}
+		if apiVersion != nil && order != nil {
+			if resourceOrder := order(kind.Value, apiVersion.Value); resourceOrder != nil {
+				for _, key := range []string{"metadata", "spec"} {
+					orderLike(mappingValue(root, key), resourceOrder.Fields[key])
+				}
+			}
+		}
+		normalizeStyle(document)
+	}
+	if !hasResource {
+		return data, nil
+	}
+
+	var output bytes.Buffer
+	encoder := yaml.NewEncoder(&output)
+	encoder.SetIndent(FormatIndent)
+	for _, document := range documents {
+		err := encoder.Encode(document)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err := encoder.Close()
+	return output.Bytes(), err
+}
+
+// orderKeys moves the given keys of a mapping first, in order, the other keys keeping their order.
+func orderKeys(mapping *yaml.Node, keys []string) {
+	if mapping == nil || mapping.Kind != yaml.MappingNode {
+		return
+	}
+	content := make([]*yaml.Node, 0, len(mapping.Content))
+	moved := map[int]bool{}
+	for _, key := range keys {
+		for i := 0; i+1 < len(mapping.Content); i += 2 {
+			if !moved[i] && mapping.Content[i].Value == key {
+				content = append(content, mapping.Content[i], mapping.Content[i+1])
+				moved[i] = true
+				break
+			}
+		}
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if !moved[i] {
+			content = append(content, mapping.Content[i], mapping.Content[i+1])
+		}
+	}
+	mapping.Content = content
+}
+
+// orderLike orders the keys of node, recursively, as given by order.
+func orderLike(node *yaml.Node, order *KeyOrder) {
+	if node == nil || order == nil {
+		return
+	}
+	switch node.Kind {
+	case yaml.MappingNode:
+		orderKeys(node, order.Keys)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			orderLike(node.Content[i+1], order.Fields[node.Content[i].Value])
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			orderLike(item, order.Items)
+		}
+	}
+}
+
+// normalizeStyle sets the block style of mappings and lists, and the plain style of scalars but multi-line strings,
+// the encoder quoting the strings that would otherwise read as another type.
+func normalizeStyle(node *yaml.Node) {
+	node.Style = 0
+	if node.Kind == yaml.ScalarNode && strings.Contains(node.Value, "\n") && (node.Tag == "!!str" || node.Tag == "") {
+		node.Style = yaml.LiteralStyle
+	}
+	for _, child := range node.Content {
+		normalizeStyle(child)
+	}
+}
@@ -0,0 +1,120 @@
+package resource
+
+import (
+	"reflect"
+	"testing"
+)
+
+const formatExample = `apiVersion: v2
+kind: Topic
+metadata:
+  name: my-topic
+  cluster: my-cluster
+  labels:
+    team: payments
+spec:
+  partitions: 3
+  replicationFactor: 1
+  configs:
+    retention.ms: "60000"
+`
+
+func TestFormatYaml(t *testing.T) {
+	data := []byte(`# orders topic
+spec:
+  configs: {retention.ms: '60000', cleanup.policy: "delete"}
+  replicationFactor: 3 # three brokers
+  partitions: 12
+metadata:
+  labels: {team: "payments"}
+  name: 'orders'
+  cluster: prod
+kind: Topic
+apiVersion: v2
+---
+kind: Application
+apiVersion: v1
+metadata: {name: shop}
+spec:
+  description: "first line\nsecond line\n"
+  title: "123"
+`)
+	order := func(kind, apiVersion string) *KeyOrder {
+		if kind == "Topic" && apiVersion == "v2" {
+			return KeyOrderOf(formatExample)
+		}
+		return nil
+	}
+	expected := `# orders topic
+apiVersion: v2
+kind: Topic
+metadata:
+  name: orders
+  cluster: prod
+  labels:
+    team: payments
+spec:
+  partitions: 12
+  replicationFactor: 3 # three brokers
+  configs:
+    retention.ms: "60000"
+    cleanup.policy: delete
+---
+apiVersion: v1
+kind: Application
+metadata:
+  name: shop
+spec:
+  description: |
+    first line
+    second line
+  title: "123"
+`
+	formatted, err := FormatYaml(data, order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(formatted) != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, formatted)
+	}
+
+	again, err := FormatYaml(formatted, order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != expected {
+		t.Errorf("Expected formatting to be idempotent got:\n%s", again)
+	}
+}
+
+func TestKeyOrderOf(t *testing.T) {
+	order := KeyOrderOf("spec:\n  rules:\n    - name: a\n      action: deny\n  partitions: 3\n")
+
+	if !reflect.DeepEqual(order, &KeyOrder{
+		Keys: []string{"spec"},
+		Fields: map[string]*KeyOrder{
+			"spec": {
+				Keys: []string{"rules", "partitions"},
+				Fields: map[string]*KeyOrder{
+					"rules": {Items: &KeyOrder{Keys: []string{"name", "action"}}},
+				},
+			},
+		},
+	}) {
+		t.Errorf("Unexpected key order %+v", order)
+	}
+	if order := KeyOrderOf("not a mapping"); order != nil {
+		t.Errorf("Expected no key order for a scalar got %+v", order)
+	}
+}
+
+func TestFormatYamlWithoutResource(t *testing.T) {
+	data := []byte("values: {a: 1}\n")
+	formatted, err := FormatYaml(data, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(formatted) != string(data) {
+		t.Errorf("Expected a file without resource to be left as is got %s", formatted)
+	}
+}
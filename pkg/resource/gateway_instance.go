@@ -0,0 +1,80 @@
+package resource
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+
+	"github.com/conduktor/ctl/internal/orderedjson"
+)
+
+// GatewayInstanceKey is the metadata field naming the Gateway instance a Gateway resource targets, e.g. gateway: eu,
+// the default Gateway being targeted when not set. It routes the resource and is never sent to the Gateway.
+const GatewayInstanceKey = "gateway"
+
+// GatewayInstancesFolder holds a folder per Gateway instance, e.g. gateways/eu/interceptors.yaml, whose Gateway
+// resources target the instance unless they set metadata.gateway.
+const GatewayInstancesFolder = "gateways"
+
+// GatewayInstance returns the Gateway instance the resource targets, empty for the default Gateway.
+func (r Resource) GatewayInstance() string {
+	instance, _ := r.Metadata[GatewayInstanceKey].(string)
+	return instance
+}
+
+// WithGatewayInstance returns a copy of the resource targeting a Gateway instance, the default Gateway if instance is empty.
+func (r Resource) WithGatewayInstance(instance string) Resource {
+	if r.GatewayInstance() == instance {
+		return r
+	}
+	var document orderedjson.OrderedData
+	if json.Unmarshal(r.Json, &document) != nil || document.GetMapOrNil() == nil {
+		return r
+	}
+	metadata, ok := document.GetMapOrNil().Get("metadata")
+	if !ok || metadata.GetMapOrNil() == nil {
+		return r
+	}
+	if instance == "" {
+		metadata.GetMapOrNil().Delete(GatewayInstanceKey)
+	} else {
+		metadata.GetMapOrNil().Set(GatewayInstanceKey, orderedjson.FromValue(instance))
+	}
+	data, err := json.Marshal(document)
+	if err != nil {
+		return r
+	}
+	return r.withJson(data)
+}
+
+// isGatewayResource tells whether a resource is a Gateway one from the group of its apiVersion, e.g. gateway/v2.
+func isGatewayResource(r Resource) bool {
+	return strings.HasPrefix(r.Version, "gateway/")
+}
+
+// gatewayInstanceOfFile returns the Gateway instance of the folder of a file under GatewayInstancesFolder,
+// e.g. eu for gateways/eu/interceptors.yaml, empty if the file is not under such a folder.
+func gatewayInstanceOfFile(path string) string {
+	segments := strings.Split(filepath.ToSlash(filepath.Dir(path)), "/")
+	for i := len(segments) - 2; i >= 0; i-- {
+		if segments[i] == GatewayInstancesFolder && segments[i+1] != "" {
+			return segments[i+1]
+		}
+	}
+	return ""
+}
+
+// withGatewayInstanceOfFile makes the Gateway resources of a file target the instance of its folder,
+// unless they set metadata.gateway, see GatewayInstancesFolder.
+func withGatewayInstanceOfFile(path string, resources []Resource) []Resource {
+	instance := gatewayInstanceOfFile(path)
+	if instance == "" {
+		return resources
+	}
+	for i, res := range resources {
+		if isGatewayResource(res) && res.GatewayInstance() == "" {
+			resources[i] = res.WithGatewayInstance(instance)
+		}
+	}
+	return resources
+}
@@ -0,0 +1,86 @@
+package resource
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWithGatewayInstance(t *testing.T) {
+	resources, err := FromYamlByte([]byte("apiVersion: gateway/v2\nkind: VirtualCluster\nmetadata:\n  name: vc1\n  scope: {}\nspec: {}\n"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := resources[0]
+
+	eu := res.WithGatewayInstance("eu")
+	if eu.GatewayInstance() != "eu" {
+		t.Errorf("expected the eu instance, got %q", eu.GatewayInstance())
+	}
+	expected := `{"apiVersion":"gateway/v2","kind":"VirtualCluster","metadata":{"name":"vc1","scope":{},"gateway":"eu"},"spec":{}}`
+	if string(eu.Json) != expected {
+		t.Errorf("expected %s, got %s", expected, eu.Json)
+	}
+	if res.GatewayInstance() != "" {
+		t.Error("expected the original resource to be unchanged")
+	}
+
+	removed := eu.WithGatewayInstance("")
+	if removed.GatewayInstance() != "" || string(removed.Json) != string(res.Json) {
+		t.Errorf("expected the instance to be removed, got %s", removed.Json)
+	}
+}
+
+func TestGatewayInstanceOfFile(t *testing.T) {
+	testCases := map[string]string{
+		"gateways/eu/vc.yaml":              "eu",
+		"/repo/gateways/us-east/a/vc.yaml": "us-east",
+		"gateways/vc.yaml":                 "",
+		"resources/vc.yaml":                "",
+		"vc.yaml":                          "",
+	}
+	for path, expected := range testCases {
+		if actual := gatewayInstanceOfFile(filepath.FromSlash(path)); actual != expected {
+			t.Errorf("%s: expected %q, got %q", path, expected, actual)
+		}
+	}
+}
+
+func TestLoadFromGatewayInstanceFolder(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "gateways", "eu")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := `apiVersion: gateway/v2
+kind: VirtualCluster
+metadata:
+  name: vc1
+---
+apiVersion: gateway/v2
+kind: VirtualCluster
+metadata:
+  name: vc2
+  gateway: us
+---
+apiVersion: v2
+kind: Topic
+metadata:
+  name: orders
+  cluster: prod
+`
+	if err := os.WriteFile(filepath.Join(dir, "resources.yaml"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	resources, err := Loader{Strict: true}.FromFolder(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"eu", "us", ""}
+	for i, res := range resources {
+		if res.GatewayInstance() != expected[i] {
+			t.Errorf("%s: expected instance %q, got %q", res.Name, expected[i], res.GatewayInstance())
+		}
+	}
+}
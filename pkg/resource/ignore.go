@@ -0,0 +1,145 @@
+package resource
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	gitignore "github.com/sabhiram/go-gitignore"
+)
+
+// IgnoreFileName is the file listing, with the syntax of .gitignore, the files and folders of its folder
+// that are not loaded from folders. Nested ignore files add patterns relative to their own folder and take precedence:
+// the last pattern matching a path in the deepest ignore file decides, so !pattern re-includes a path ignored above.
+const IgnoreFileName = ".conduktorignore"
+
+// FileFilter selects the files loaded from folders with glob patterns, e.g. topics/**/*.yaml, matched against the path
+// relative to the loaded folder, or against the file name for patterns without /. A file is loaded if it matches
+// one of the Include patterns, when there are some, and none of the Exclude patterns.
+// Files given explicitly are always loaded.
+type FileFilter struct {
+	Include []string
+	Exclude []string
+}
+
+// Validate checks the syntax of the patterns.
+func (f FileFilter) Validate() error {
+	for _, pattern := range append(append([]string{}, f.Include...), f.Exclude...) {
+		if !doublestar.ValidatePattern(pattern) {
+			return fmt.Errorf("invalid glob pattern %s", pattern)
+		}
+	}
+	return nil
+}
+
+func matchesGlob(patterns []string, relativePath string) (string, bool) {
+	for _, pattern := range patterns {
+		if matched, _ := doublestar.Match(pattern, relativePath); matched {
+			return pattern, true
+		}
+		if !strings.Contains(pattern, "/") {
+			if matched, _ := doublestar.Match(pattern, path.Base(relativePath)); matched {
+				return pattern, true
+			}
+		}
+	}
+	return "", false
+}
+
+type ignoreFile struct {
+	// dir is the folder of the file relative to the loaded folder, with / separators
+	dir      string
+	path     string
+	patterns []ignorePattern
+}
+
+// ignorePattern is a line of an ignore file, compiled alone so a negated pattern is known to match.
+type ignorePattern struct {
+	line    string
+	lineNo  int
+	negate  bool
+	matcher *gitignore.GitIgnore
+}
+
+// match returns the last pattern of the file matching pathInIgnoreDir, false if none does.
+func (f ignoreFile) match(pathInIgnoreDir string) (ignorePattern, bool) {
+	var last ignorePattern
+	matched := false
+	for _, pattern := range f.patterns {
+		if pattern.matcher.MatchesPath(pathInIgnoreDir) {
+			last, matched = pattern, true
+		}
+	}
+	return last, matched
+}
+
+// withIgnoreFile adds the ignore file of dir, if any, to ignores.
+func withIgnoreFile(ignores []ignoreFile, dir, relativeDir string) ([]ignoreFile, error) {
+	file := filepath.Join(dir, IgnoreFileName)
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return ignores, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	var patterns []ignorePattern
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		pattern := ignorePattern{line: line, lineNo: i + 1}
+		if strings.HasPrefix(line, "!") {
+			pattern.negate = true
+			line = line[1:]
+		}
+		pattern.matcher = gitignore.CompileIgnoreLines(line)
+		patterns = append(patterns, pattern)
+	}
+	return append(ignores[:len(ignores):len(ignores)], ignoreFile{dir: relativeDir, path: file, patterns: patterns}), nil
+}
+
+// skipReason tells why the entry at relativePath of a loaded folder is not loaded, empty if it is.
+func (l Loader) skipReason(relativePath string, isDir bool, ignores []ignoreFile) string {
+	for i := len(ignores) - 1; i >= 0; i-- {
+		ignore := ignores[i]
+		pathInIgnoreDir := relativePath
+		if ignore.dir != "" {
+			pathInIgnoreDir = strings.TrimPrefix(relativePath, ignore.dir+"/")
+		}
+		if isDir {
+			pathInIgnoreDir += "/"
+		}
+		pattern, matched := ignore.match(pathInIgnoreDir)
+		if !matched {
+			continue
+		}
+		if !pattern.negate {
+			return fmt.Sprintf("ignored by %s line %d: %s", ignore.path, pattern.lineNo, pattern.line)
+		}
+		break
+	}
+	if pattern, excluded := matchesGlob(l.Filter.Exclude, relativePath); excluded {
+		return "excluded by --exclude " + pattern
+	}
+	if isDir {
+		return ""
+	}
+	if !isResourceFile(relativePath) {
+		return "not a resource file (" + strings.Join(ResourceFileExtensions, ", ") + ")"
+	}
+	if _, included := matchesGlob(l.Filter.Include, relativePath); len(l.Filter.Include) > 0 && !included {
+		return "not matching any --include pattern"
+	}
+	return ""
+}
+
+func (l Loader) logSkipped(path, reason string) {
+	if l.Debug {
+		fmt.Fprintf(os.Stderr, "Skipping %s: %s\n", path, reason)
+	}
+}
@@ -0,0 +1,130 @@
+package resource
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func loadedNames(t *testing.T, loader Loader, dir string) []string {
+	resources, err := loader.FromFolder(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, res := range resources {
+		names = append(names, res.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func topicNamed(name string) string {
+	return "apiVersion: v2\nkind: Topic\nmetadata:\n  name: " + name + "\n  cluster: prod\nspec:\n  partitions: 3\n"
+}
+
+func TestFromFolderShouldHonorIgnoreFiles(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		IgnoreFileName:                     "# CI and chart files\n.gitlab-ci.yml\nchart/\n*.bak.yaml\n!keep.bak.yaml\n",
+		".gitlab-ci.yml":                   "stages: [deploy]\n",
+		"orders.yaml":                      topicNamed("orders"),
+		"orders.bak.yaml":                  "not: a resource\n",
+		"keep.bak.yaml":                    topicNamed("keep"),
+		"chart/values.yaml":                "replicas: 3\n",
+		"teams/payments.yaml":              topicNamed("payments"),
+		"teams/draft/wip.yaml":             "not: a resource\n",
+		"teams/" + IgnoreFileName:          "draft\n",
+		"overlays/prod/" + OverlayFileName: "resources: [../../teams]\n",
+	})
+
+	names := loadedNames(t, Loader{Strict: true, Recursive: true}, dir)
+	if strings.Join(names, ",") != "keep,orders,payments" {
+		t.Errorf("Unexpected resources %v", names)
+	}
+}
+
+func TestNestedIgnoreFilesShouldReIncludeWithNegation(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		IgnoreFileName:                     "*.bak.yaml\ndrafts/\n",
+		"orders.bak.yaml":                  "not: a resource\n",
+		"teams/" + IgnoreFileName:          "!keep.bak.yaml\n",
+		"teams/keep.bak.yaml":              topicNamed("keep"),
+		"teams/old.bak.yaml":               "not: a resource\n",
+		"teams/payments/" + IgnoreFileName: "*.yaml\n!payments.yaml\n",
+		"teams/payments/payments.yaml":     topicNamed("payments"),
+		"teams/payments/other.yaml":        "not: a resource\n",
+		"teams/payments/keep.bak.yaml":     "not: a resource\n",
+		"teams/drafts/" + IgnoreFileName:   "!wip.yaml\n",
+		"teams/drafts/wip.yaml":            "not: a resource\n",
+	})
+
+	names := loadedNames(t, Loader{Strict: true, Recursive: true}, dir)
+	if strings.Join(names, ",") != "keep,payments" {
+		t.Errorf("Unexpected resources %v", names)
+	}
+}
+
+func TestFromFolderShouldFilterFilesWithGlobs(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"topics/orders.yaml":      topicNamed("orders"),
+		"topics/prod/audit.yaml":  topicNamed("audit"),
+		"topics/values-prod.yaml": "replicas: 3\n",
+		"helm/values.yaml":        "replicas: 3\n",
+		"README.yaml":             "not: a resource\n",
+	})
+
+	loader := Loader{Strict: true, Recursive: true, Filter: FileFilter{Include: []string{"topics/**"}, Exclude: []string{"values*.yaml"}}}
+	names := loadedNames(t, loader, dir)
+	if strings.Join(names, ",") != "audit,orders" {
+		t.Errorf("Unexpected resources %v", names)
+	}
+
+	loader.Filter = FileFilter{Exclude: []string{"helm", "README.yaml", "**/values-*.yaml", "topics/prod/**"}}
+	names = loadedNames(t, loader, dir)
+	if strings.Join(names, ",") != "orders" {
+		t.Errorf("Unexpected resources %v", names)
+	}
+
+	if (FileFilter{Include: []string{"topics/[a-"}}).Validate() == nil {
+		t.Error("Expected an invalid pattern to be rejected")
+	}
+}
+
+func TestSkippedFilesShouldBeReported(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		IgnoreFileName:  "ignored.yaml\n",
+		"ignored.yaml":  "not: a resource\n",
+		"excluded.yaml": "not: a resource\n",
+		"notes.txt":     "not a resource\n",
+		"sub/a.yaml":    topicNamed("a"),
+	})
+
+	stderr := os.Stderr
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stderr = writer
+	_, err = Loader{Strict: true, Debug: true, Filter: FileFilter{Exclude: []string{"excluded.yaml"}}}.FromFolder(dir)
+	os.Stderr = stderr
+	writer.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, _ := io.ReadAll(reader)
+
+	expected := []string{
+		"Skipping " + filepath.Join(dir, "ignored.yaml") + ": ignored by " + filepath.Join(dir, IgnoreFileName) + " line 1: ignored.yaml",
+		"Skipping " + filepath.Join(dir, "excluded.yaml") + ": excluded by --exclude excluded.yaml",
+		"Skipping " + filepath.Join(dir, "notes.txt") + ": not a resource file (.yaml, .yml, .json, .ndjson)",
+		"Skipping " + filepath.Join(dir, "sub") + ": subfolder, loaded with --recursive",
+	}
+	for _, line := range expected {
+		if !strings.Contains(string(output), line) {
+			t.Errorf("Expected %q in %s", line, output)
+		}
+	}
+}
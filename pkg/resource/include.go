@@ -0,0 +1,202 @@
+package resource
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// Includes replace a value of a resource by the content of a file, resolved relative to the including file:
+//
+//	config: !include config.json              # the text of the file
+//	config:
+//	  $file: config.json                      # the text of the file, or its parsed content with format yaml or json
+//	  format: json
+const (
+	IncludeTag       = "!include"
+	IncludeKey       = "$file"
+	IncludeFormatKey = "format"
+)
+
+// Formats of the included content.
+const (
+	IncludeText = "text"
+	IncludeYaml = "yaml"
+	IncludeJson = "json"
+)
+
+// legacyIncludes are the fields of a kind whose value is the path of a file whose text replaces another field,
+// e.g. spec.schemaFile for spec.schema of a Subject.
+var legacyIncludes = map[string][]struct {
+	path        []string
+	fileKey     string
+	includedKey string
+}{
+	"Subject": {{[]string{"spec"}, "schemaFile", "schema"}},
+	"Topic":   {{[]string{"metadata", "labels"}, "conduktor.io/descriptionFile", "conduktor.io/description"}},
+}
+
+type includeResolver struct {
+	strict bool
+	// remote and root confine the included files of a remote source, see confine
+	remote string
+	root   string
+	// stack holds the files being included, to detect cycles
+	stack []string
+	// verbatim holds the nodes replaced by the text of a file
+	verbatim map[*yaml.Node]bool
+}
+
+// resolveIncludes replaces the includes of a YAML document loaded from options.dir by the content of their files.
+// It returns the nodes replaced by the text of a file, whose secret references are not resolved.
+func resolveIncludes(document *yaml.Node, options parseOptions) (map[*yaml.Node]bool, error) {
+	resolver := &includeResolver{strict: options.strict, remote: options.remote, root: options.root, verbatim: map[*yaml.Node]bool{}}
+	err := resolver.resolveLegacyIncludes(document, options.dir)
+	if err != nil {
+		return nil, err
+	}
+	return resolver.verbatim, resolver.resolve(document, options.dir)
+}
+
+func (r *includeResolver) resolve(node *yaml.Node, dir string) error {
+	if node.Kind == yaml.ScalarNode && node.Tag == IncludeTag {
+		return r.include(node, dir, node.Value, IncludeText)
+	}
+	if node.Kind == yaml.MappingNode {
+		if path := mappingValue(node, IncludeKey); path != nil {
+			return r.includeMapping(node, dir, path)
+		}
+	}
+	for _, child := range node.Content {
+		err := r.resolve(child, dir)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *includeResolver) includeMapping(node *yaml.Node, dir string, path *yaml.Node) error {
+	format := IncludeText
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		switch key := node.Content[i].Value; key {
+		case IncludeKey:
+		case IncludeFormatKey:
+			format = node.Content[i+1].Value
+		default:
+			return fmt.Errorf("unexpected key %s next to %s %s, only %s is allowed", key, IncludeKey, path.Value, IncludeFormatKey)
+		}
+	}
+	return r.include(node, dir, path.Value, format)
+}
+
+// include replaces node by the content of the file at path.
+func (r *includeResolver) include(node *yaml.Node, dir, path, format string) error {
+	if path == "" {
+		return fmt.Errorf("empty %s path", IncludeTag)
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	for _, included := range r.stack {
+		if included == path {
+			return fmt.Errorf("circular include of %s", path)
+		}
+	}
+	if r.remote != "" {
+		err := confine(path, r.root, r.remote)
+		if err != nil {
+			return fmt.Errorf("failed to include file %s: %w", path, err)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to include file %s: %w", path, err)
+	}
+
+	switch format {
+	case IncludeText:
+		*node = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: string(data)}
+		r.verbatim[node] = true
+		return nil
+	case IncludeYaml, IncludeJson:
+		data, err = expandEnvVars(data, r.strict)
+		if err != nil {
+			return fmt.Errorf("failed to include file %s: %w", path, err)
+		}
+		var document yaml.Node
+		err = yaml.Unmarshal(data, &document)
+		if err != nil {
+			return fmt.Errorf("failed to parse included file %s: %w", path, err)
+		}
+		if len(document.Content) == 0 {
+			*node = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+			return nil
+		}
+		// includes of the included file are relative to it
+		r.stack = append(r.stack, path)
+		err = r.resolve(document.Content[0], filepath.Dir(path))
+		r.stack = r.stack[:len(r.stack)-1]
+		if err != nil {
+			return err
+		}
+		*node = *document.Content[0]
+		return nil
+	default:
+		return fmt.Errorf("invalid include format %s of %s, expected %s, %s or %s", format, path, IncludeText, IncludeYaml, IncludeJson)
+	}
+}
+
+// resolveLegacyIncludes expands spec.schemaFile of a Subject and the conduktor.io/descriptionFile label of a Topic,
+// relative to dir or, if missing there, to the current directory as before generic includes.
+func (r *includeResolver) resolveLegacyIncludes(document *yaml.Node, dir string) error {
+	root := document
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+	kind := mappingValue(root, "kind")
+	if kind == nil {
+		return nil
+	}
+	for _, legacy := range legacyIncludes[kind.Value] {
+		parent := root
+		for _, key := range legacy.path {
+			if parent = mappingValue(parent, key); parent == nil {
+				break
+			}
+		}
+		if parent == nil {
+			continue
+		}
+		for i := 0; i+1 < len(parent.Content); i += 2 {
+			if parent.Content[i].Value != legacy.fileKey {
+				continue
+			}
+			path, baseDir := parent.Content[i+1].Value, dir
+			if _, err := os.Stat(filepath.Join(dir, path)); err != nil && !filepath.IsAbs(path) {
+				baseDir = "."
+			}
+			parent.Content[i].Value = legacy.includedKey
+			err := r.include(parent.Content[i+1], baseDir, path, IncludeText)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// mappingValue returns the value of key in a mapping node, nil if node is not a mapping or has no such key.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package resource
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestIncludesShouldBeRelativeToTheIncludingFile(t *testing.T) {
+	t.Setenv("TOPIC", "orders")
+	dir := writeFiles(t, map[string]string{
+		"resources/interceptor.yaml": `
+apiVersion: gateway/v2
+kind: Interceptor
+metadata:
+  name: masking
+spec:
+  pluginClass: io.conduktor.gateway.interceptor.FieldLevelDataMaskingPlugin
+  comment: !include comment.txt
+  config:
+    $file: config/masking.json
+    format: json
+`,
+		"resources/comment.txt":         "Masks the PII of ${TOPIC}\n",
+		"resources/config/masking.json": `{"topic": "${TOPIC}", "policies": {"$file": "policies.yaml", "format": "yaml"}}`,
+		"resources/config/policies.yaml": `
+- name: email
+  rule: MASK_ALL
+`,
+	})
+
+	resources, err := FromFile(filepath.Join(dir, "resources", "interceptor.yaml"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"pluginClass": "io.conduktor.gateway.interceptor.FieldLevelDataMaskingPlugin",
+		// text is included as is
+		"comment": "Masks the PII of ${TOPIC}\n",
+		"config": map[string]interface{}{
+			"topic":    "orders",
+			"policies": []interface{}{map[string]interface{}{"name": "email", "rule": "MASK_ALL"}},
+		},
+	}
+	if !reflect.DeepEqual(resources[0].Spec, expected) {
+		t.Errorf("Expected spec %v got %v", expected, resources[0].Spec)
+	}
+}
+
+func TestLegacyIncludesShouldBeRelativeToTheIncludingFile(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"subject.yaml":        "kind: Subject\nmetadata:\n  name: orders-value\n  cluster: prod\nspec:\n  format: AVRO\n  schemaFile: schemas/orders.avsc\n",
+		"schemas/orders.avsc": `{"type": "long"}`,
+	})
+
+	resources, err := FromFile(filepath.Join(dir, "subject.yaml"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resources[0].Spec["schema"] != `{"type": "long"}` || resources[0].Spec["schemaFile"] != nil {
+		t.Errorf("Unexpected spec %v", resources[0].Spec)
+	}
+}
+
+func TestIncludeErrors(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.yaml":    "$file: b.yaml\nformat: yaml\n",
+		"b.yaml":    "$file: a.yaml\nformat: yaml\n",
+		"text.json": "{}",
+	})
+	tests := map[string]string{
+		"spec: !include missing.txt":                "failed to include file",
+		"spec:\n  $file: a.yaml\n  format: yaml":    "circular include",
+		"spec:\n  $file: text.json\n  format: xml":  "invalid include format xml",
+		"spec:\n  $file: text.json\n  other: value": "unexpected key other",
+	}
+	for content, expectedError := range tests {
+		path := filepath.Join(dir, "resource.yaml")
+		if err := os.WriteFile(path, []byte("kind: Topic\nmetadata:\n  name: test\n"+content+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		_, err := FromFile(path, true)
+		if err == nil || !strings.Contains(err.Error(), expectedError) {
+			t.Errorf("Expected error %q for %q got %v", expectedError, content, err)
+		}
+	}
+}
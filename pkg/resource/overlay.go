@@ -0,0 +1,325 @@
+package resource
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	yamlJson "github.com/ghodss/yaml"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// OverlayFileName is the file describing an overlay in its directory.
+const OverlayFileName = "conduktor-overlay.yaml"
+
+// Overlay customizes the resources of bases for an environment, e.g. overlays/prod/conduktor-overlay.yaml:
+//
+//	resources:                # files, folders or other overlays, relative to the overlay
+//	  - ../../base
+//	namePrefix: prod-
+//	commonLabels:
+//	  env: prod
+//	cluster: prod-cluster     # replaces metadata.cluster of the resources having one
+//	patches:
+//	  - target:
+//	      kind: Topic
+//	      name: orders
+//	    patch:                # a JSON merge patch, or a JSON 6902 patch when it is a list
+//	      spec:
+//	        partitions: 12
+//	  - target:
+//	      labelSelector: team=payments
+//	    path: payments.yaml   # the patch in a file relative to the overlay
+//
+// Patches are applied in order to the resources of the bases, before the names, labels and cluster are changed,
+// so targets use the names of the bases.
+type Overlay struct {
+	Resources    []string          `yaml:"resources"`
+	NamePrefix   string            `yaml:"namePrefix"`
+	NameSuffix   string            `yaml:"nameSuffix"`
+	CommonLabels map[string]string `yaml:"commonLabels"`
+	Cluster      string            `yaml:"cluster"`
+	Patches      []OverlayPatch    `yaml:"patches"`
+}
+
+// OverlayPatch is given inline by Patch, as YAML or as a string, or by the file at Path.
+type OverlayPatch struct {
+	Target PatchTarget `yaml:"target"`
+	Patch  yaml.Node   `yaml:"patch"`
+	Path   string      `yaml:"path"`
+}
+
+// PatchTarget selects the resources a patch applies to, an empty field matches all resources.
+type PatchTarget struct {
+	Kind          string `yaml:"kind"`
+	Name          string `yaml:"name"`
+	LabelSelector string `yaml:"labelSelector"`
+}
+
+// IsOverlay returns true if path is an overlay file or a directory holding one.
+func IsOverlay(path string) bool {
+	if filepath.Base(path) == OverlayFileName {
+		return true
+	}
+	info, err := os.Stat(filepath.Join(path, OverlayFileName))
+	return err == nil && !info.IsDir()
+}
+
+// Build loads the resources of the overlay at path, a directory holding an overlay file or the file itself,
+// and applies its patches, name prefix and suffix, common labels and cluster.
+func (l Loader) Build(path string) ([]Resource, error) {
+	return l.withSecretCache().build(path, nil)
+}
+
+func (l Loader) build(path string, stack []string) ([]Resource, error) {
+	file := path
+	if filepath.Base(path) != OverlayFileName {
+		file = filepath.Join(path, OverlayFileName)
+	}
+	dir := filepath.Dir(file)
+	for _, built := range stack {
+		if built == file {
+			return nil, fmt.Errorf("circular overlay %s", file)
+		}
+	}
+	stack = append(stack, file)
+
+	overlay, err := l.readOverlay(file)
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]Resource, 0)
+	for _, base := range overlay.Resources {
+		basePath := base
+		if !filepath.IsAbs(basePath) {
+			basePath = filepath.Join(dir, base)
+		}
+		info, err := os.Stat(basePath)
+		if err != nil {
+			return nil, fmt.Errorf("invalid resource %s of overlay %s: %w", base, file, err)
+		}
+		var loaded []Resource
+		if IsOverlay(basePath) {
+			loaded, err = l.build(basePath, stack)
+		} else if info.IsDir() {
+			loaded, err = l.FromFolder(basePath)
+		} else {
+			loaded, err = l.FromFile(basePath)
+		}
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, loaded...)
+	}
+
+	for i, patch := range overlay.Patches {
+		err = l.applyOverlayPatch(resources, patch, dir)
+		if err != nil {
+			return nil, fmt.Errorf("patch %d of overlay %s: %w", i+1, file, err)
+		}
+	}
+	for i := range resources {
+		resources[i], err = overlay.transform(resources[i])
+		if err != nil {
+			return nil, fmt.Errorf("overlay %s: %w", file, err)
+		}
+	}
+	return resources, nil
+}
+
+func (l Loader) readOverlay(file string) (Overlay, error) {
+	var overlay Overlay
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return overlay, err
+	}
+	data, err = expandEnvVars(data, l.Strict)
+	if err != nil {
+		return overlay, fmt.Errorf("overlay %s: %w", file, err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	err = decoder.Decode(&overlay)
+	if err != nil {
+		return overlay, fmt.Errorf("invalid overlay %s: %w", file, err)
+	}
+	return overlay, nil
+}
+
+func (l Loader) applyOverlayPatch(resources []Resource, patch OverlayPatch, dir string) error {
+	data, secretFields, err := l.patchJson(patch, dir)
+	if err != nil {
+		return err
+	}
+	var selector Selector
+	if patch.Target.LabelSelector != "" {
+		selector, err = ParseLabelSelector(patch.Target.LabelSelector)
+		if err != nil {
+			return err
+		}
+	}
+	matched := false
+	for i, res := range resources {
+		if (patch.Target.Kind != "" && patch.Target.Kind != res.Kind) ||
+			(patch.Target.Name != "" && patch.Target.Name != res.Name) ||
+			!selector.Matches(res) {
+			continue
+		}
+		matched = true
+		resources[i], err = applyPatch(res, data)
+		if err != nil {
+			return fmt.Errorf("failed to patch %s/%s: %w", res.Kind, res.Name, err)
+		}
+		resources[i].SecretFields = appendMissing(resources[i].SecretFields, secretFields)
+	}
+	// a patch matching nothing is most likely a typo in its target
+	if !matched {
+		return fmt.Errorf("no resource matches the target %+v", patch.Target)
+	}
+	return nil
+}
+
+// patchJson returns the JSON of a patch given inline or in a file, with its secret references resolved,
+// and the paths of the fields of the patched resources holding a secret of the patch.
+func (l Loader) patchJson(patch OverlayPatch, dir string) ([]byte, []string, error) {
+	var data []byte
+	document := &yaml.Node{}
+	switch {
+	case patch.Path != "" && !patch.Patch.IsZero():
+		return nil, nil, fmt.Errorf("patch and path are exclusive")
+	case patch.Path != "":
+		path := patch.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		fileData, err := os.ReadFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		data, err = expandEnvVars(fileData, l.Strict)
+		if err != nil {
+			return nil, nil, err
+		}
+		err = yaml.Unmarshal(data, document)
+		if err != nil {
+			return nil, nil, err
+		}
+	case patch.Patch.Kind == yaml.ScalarNode:
+		err := yaml.Unmarshal([]byte(patch.Patch.Value), document)
+		if err != nil {
+			return nil, nil, err
+		}
+	case !patch.Patch.IsZero():
+		document = &patch.Patch
+	default:
+		return nil, nil, fmt.Errorf("missing patch or path")
+	}
+	if document.IsZero() {
+		data, err := yamlJson.YAMLToJSON(data)
+		return data, nil, err
+	}
+	secrets, err := resolveSecretReferences(document, nil, l.secretPolicy(), l.resolved)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err = yaml.Marshal(document)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err = yamlJson.YAMLToJSON(data)
+	return data, patchSecretFieldPaths(document, secrets), err
+}
+
+// patchSecretFieldPaths returns the paths of the secrets of a patch in the patched resources: their paths in a merge patch,
+// below the path of their operation in a JSON 6902 patch.
+func patchSecretFieldPaths(document *yaml.Node, secrets map[*yaml.Node]bool) []string {
+	root := document
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+	if root.Kind != yaml.SequenceNode {
+		return secretFieldPaths(root, secrets)
+	}
+	var paths []string
+	for _, operation := range root.Content {
+		pointer, value := mappingValue(operation, "path"), mappingValue(operation, "value")
+		if pointer == nil || value == nil {
+			continue
+		}
+		var prefix []string
+		for _, segment := range strings.Split(strings.TrimPrefix(pointer.Value, "/"), "/") {
+			// arrays are gone through by the paths
+			if _, err := strconv.Atoi(segment); err == nil || segment == "-" || segment == "" {
+				continue
+			}
+			prefix = append(prefix, strings.NewReplacer("~1", "/", "~0", "~").Replace(segment))
+		}
+		if secrets[value] {
+			paths = appendMissing(paths, []string{strings.Join(prefix, ".")})
+		}
+		for _, path := range secretFieldPaths(value, secrets) {
+			paths = appendMissing(paths, []string{strings.Join(append(prefix[:len(prefix):len(prefix)], path), ".")})
+		}
+	}
+	return paths
+}
+
+func appendMissing(paths, others []string) []string {
+	for _, other := range others {
+		if other != "" && !slices.Contains(paths, other) {
+			paths = append(paths, other)
+		}
+	}
+	return paths
+}
+
+// applyPatch applies a JSON 6902 patch if patch is a list, a JSON merge patch otherwise.
+func applyPatch(res Resource, patch []byte) (Resource, error) {
+	var patched []byte
+	var err error
+	if bytes.HasPrefix(bytes.TrimSpace(patch), []byte("[")) {
+		var operations jsonpatch.Patch
+		operations, err = jsonpatch.DecodePatch(patch)
+		if err != nil {
+			return res, err
+		}
+		patched, err = operations.Apply(res.Json)
+	} else {
+		patched, err = jsonpatch.MergePatch(res.Json, patch)
+	}
+	if err != nil {
+		return res, err
+	}
+	var result Resource
+	err = json.Unmarshal(patched, &result)
+	result.SecretFields = res.SecretFields
+	return result, err
+}
+
+// transform renames the resource and sets its labels and cluster.
+func (o Overlay) transform(res Resource) (Resource, error) {
+	metadata := map[string]interface{}{}
+	if o.NamePrefix != "" || o.NameSuffix != "" {
+		metadata["name"] = o.NamePrefix + res.Name + o.NameSuffix
+	}
+	if len(o.CommonLabels) > 0 {
+		metadata["labels"] = o.CommonLabels
+	}
+	if _, hasCluster := res.Metadata["cluster"]; hasCluster && o.Cluster != "" {
+		metadata["cluster"] = o.Cluster
+	}
+	if len(metadata) == 0 {
+		return res, nil
+	}
+	patch, err := json.Marshal(map[string]interface{}{"metadata": metadata})
+	if err != nil {
+		return res, err
+	}
+	return applyPatch(res, patch)
+}
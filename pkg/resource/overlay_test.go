@@ -0,0 +1,173 @@
+package resource
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const overlayBase = `
+apiVersion: v2
+kind: Topic
+metadata:
+  name: orders
+  cluster: dev-cluster
+  labels:
+    team: payments
+spec:
+  partitions: 3
+  replicationFactor: 1
+---
+apiVersion: v2
+kind: Topic
+metadata:
+  name: audit
+  cluster: dev-cluster
+spec:
+  partitions: 1
+  replicationFactor: 1
+---
+apiVersion: v1
+kind: Application
+metadata:
+  name: shop
+spec:
+  title: Shop
+`
+
+func TestBuildOverlay(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"base/resources.yaml": overlayBase,
+		"overlays/prod/" + OverlayFileName: `
+resources:
+  - ../../base
+namePrefix: prod-
+nameSuffix: -v1
+commonLabels:
+  env: prod
+cluster: prod-cluster
+patches:
+  - target:
+      kind: Topic
+      name: orders
+    patch:
+      spec:
+        partitions: 12
+  - target:
+      labelSelector: team=payments
+    path: replication.yaml
+  - target:
+      kind: Application
+    patch: |
+      - op: replace
+        path: /spec/title
+        value: Shop (prod)
+`,
+		"overlays/prod/replication.yaml": "spec:\n  replicationFactor: 3\n",
+	})
+
+	resources, err := Loader{Strict: true}.Build(filepath.Join(dir, "overlays", "prod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		`{"apiVersion":"v2","kind":"Topic","metadata":{"cluster":"prod-cluster","labels":{"team":"payments","env":"prod"},"name":"prod-orders-v1"},"spec":{"partitions":12,"replicationFactor":3}}`,
+		`{"apiVersion":"v2","kind":"Topic","metadata":{"cluster":"prod-cluster","name":"prod-audit-v1","labels":{"env":"prod"}},"spec":{"partitions":1,"replicationFactor":1}}`,
+		`{"apiVersion":"v1","kind":"Application","metadata":{"name":"prod-shop-v1","labels":{"env":"prod"}},"spec":{"title":"Shop (prod)"}}`,
+	}
+	if len(resources) != len(expected) {
+		t.Fatalf("Expected %d resources got %d", len(expected), len(resources))
+	}
+	for i, res := range resources {
+		if string(res.Json) != expected[i] {
+			t.Errorf("Expected %s got %s", expected[i], res.Json)
+		}
+	}
+	if resources[0].Name != "prod-orders-v1" || resources[0].Metadata["cluster"] != "prod-cluster" {
+		t.Errorf("Expected Name and Metadata to follow the patches got %s %v", resources[0].Name, resources[0].Metadata)
+	}
+}
+
+func TestBuildNestedOverlays(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"base/resources.yaml":            overlayBase,
+		"staging/" + OverlayFileName:     "resources: [../base]\nnamePrefix: staging-\n",
+		"staging-eu/" + OverlayFileName:  "resources: [../staging]\nnameSuffix: -eu\npatches:\n  - target: {name: staging-audit}\n    patch: {spec: {partitions: 2}}\n",
+		"circular-a/" + OverlayFileName:  "resources: [../circular-b]\n",
+		"circular-b/" + OverlayFileName:  "resources: [../circular-a]\n",
+		"no-target/" + OverlayFileName:   "resources: [../base]\npatches:\n  - target: {name: typo}\n    patch: {spec: {partitions: 2}}\n",
+		"unknown-key/" + OverlayFileName: "resources: [../base]\nprefix: typo-\n",
+	})
+
+	resources, err := Loader{Strict: true}.Build(filepath.Join(dir, "staging-eu", OverlayFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resources[1].Name != "staging-audit-eu" || resources[1].Spec["partitions"] != float64(2) {
+		t.Errorf("Expected the patch to target the name of the base overlay got %s", resources[1].Json)
+	}
+
+	errors := map[string]string{
+		"circular-a":  "circular overlay",
+		"no-target":   "no resource matches",
+		"unknown-key": "field prefix not found",
+	}
+	for overlay, message := range errors {
+		_, err := Loader{Strict: true}.Build(filepath.Join(dir, overlay))
+		if err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("Expected building %s to fail with %s got %v", overlay, message, err)
+		}
+	}
+}
+
+func TestIsOverlay(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"base/resources.yaml":     overlayBase,
+		"prod/" + OverlayFileName: "resources: [../base]\n",
+	})
+	if IsOverlay(filepath.Join(dir, "base")) || !IsOverlay(filepath.Join(dir, "prod")) || !IsOverlay(filepath.Join(dir, "prod", OverlayFileName)) {
+		t.Error("Expected only prod to be an overlay")
+	}
+}
+
+func TestOverlayPatchSecretsShouldBeMasked(t *testing.T) {
+	RegisterSecretResolver("overlay", SecretResolverFunc(func(reference string) (string, error) {
+		return "s3cr3t-" + reference, nil
+	}))
+	dir := writeFiles(t, map[string]string{
+		"base/resources.yaml": overlayBase,
+		"overlays/prod/" + OverlayFileName: `
+resources:
+  - ../../base
+patches:
+  - target:
+      kind: Topic
+      name: orders
+    patch:
+      spec:
+        configs:
+          sasl.password: ${overlay:orders}
+  - target:
+      kind: Application
+    patch: |
+      - op: add
+        path: /spec/owner
+        value:
+          token: ${overlay:shop}
+`,
+	})
+
+	resources, err := Loader{Strict: true}.Build(filepath.Join(dir, "overlays", "prod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"orders": "spec.configs.sasl.password", "audit": "", "shop": "spec.owner.token"}
+	for _, res := range resources {
+		if strings.Join(res.SecretFields, ",") != expected[res.Name] {
+			t.Errorf("Expected the secret fields of %s to be %s got %v", res.Name, expected[res.Name], res.SecretFields)
+		}
+		if strings.Contains(res.String(), "s3cr3t") {
+			t.Errorf("Expected the secrets of %s to be masked got %s", res.Name, res.String())
+		}
+	}
+}
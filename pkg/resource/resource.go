@@ -5,12 +5,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
 	"strings"
 
-	gabs "github.com/Jeffail/gabs/v2"
 	"github.com/conduktor/ctl/internal/orderedjson"
 	"github.com/conduktor/ctl/internal/printutils"
 	yamlJson "github.com/ghodss/yaml"
@@ -25,6 +26,8 @@ type Resource struct {
 	Version  string
 	Metadata map[string]interface{}
 	Spec     map[string]interface{}
+	// SecretFields are the dotted paths of the fields resolved from secret references, masked in outputs
+	SecretFields []string
 }
 
 func (r Resource) MarshalJSON() ([]byte, error) {
@@ -49,11 +52,11 @@ func (r *Resource) UnmarshalJSON(data []byte) error {
 	r.Version = forParsingStruct.ApiVersion
 	r.Metadata = forParsingStruct.Metadata
 	r.Spec = forParsingStruct.Spec
-	return expendIncludeFiles(r)
+	return nil
 }
 
 func (r Resource) String() string {
-	return fmt.Sprintf(`version: %s, kind: %s, name: %s, json: '%s'`, r.Version, r.Kind, r.Name, string(r.Json))
+	return fmt.Sprintf(`version: %s, kind: %s, name: %s, json: '%s'`, r.Version, r.Kind, r.Name, string(r.WithSecretFieldsMasked().Json))
 }
 
 func (r Resource) StringFromMetadata(key string) (string, error) {
@@ -68,77 +71,307 @@ type forParsingStruct struct {
 	Spec       map[string]interface{}
 }
 
+// Loader loads the resources of files and folders.
+type Loader struct {
+	Strict bool
+	// Recursive loads the files of the subfolders of a folder
+	Recursive bool
+	// Values enable the rendering of the files as templates when not nil, see RenderTemplate
+	Values map[string]interface{}
+	// HTTPClient fetches URL sources, http.DefaultClient if nil
+	HTTPClient *http.Client
+	// Stdin is read by the "-" source, os.Stdin if nil
+	Stdin io.Reader
+	// Filter selects the files loaded from folders
+	Filter FileFilter
+	// Debug reports the files skipped in folders on stderr
+	Debug bool
+	// StrictYaml rejects duplicate keys, unknown top-level keys and missing or invalid apiVersion, kind and metadata.name
+	// with the file, line and column of the error
+	StrictYaml bool
+	// AllowExecSecrets resolves the ${exec:} and ${file:} secret references of local sources, see SecretPolicy
+	AllowExecSecrets bool
+	// remote names the URL or git source being loaded, see SecretPolicy
+	remote string
+	// root is the fetched tree of a remote source, its files and includes must be inside, see confine
+	root string
+	// resolved caches the secret references resolved by a load, see withSecretCache
+	resolved *secretCache
+}
+
 func FromFile(path string, strict bool) ([]Resource, error) {
+	return Loader{Strict: strict}.FromFile(path)
+}
+
+func FromFolder(path string, strict, recursive bool) ([]Resource, error) {
+	return Loader{Strict: strict, Recursive: recursive}.FromFolder(path)
+}
+
+func (l Loader) FromFile(path string) ([]Resource, error) {
+	l = l.withSecretCache()
+	if l.remote != "" {
+		err := confine(path, l.root, l.remote)
+		if err != nil {
+			return nil, err
+		}
+	}
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
-
-	return FromYamlByte(data, strict)
+	resources, err := l.fromBytes(path, data, filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	return withGatewayInstanceOfFile(path, resources), nil
 }
 
-func FromFolder(path string, strict, recursive bool) ([]Resource, error) {
-	dirEntry, err := os.ReadDir(path)
+// FromFolder loads the resource files of a folder, see Files.
+func (l Loader) FromFolder(path string) ([]Resource, error) {
+	l = l.withSecretCache()
+	files, err := l.Files(path)
 	if err != nil {
 		return nil, err
 	}
 	var result = make([]Resource, 0)
-	for _, entry := range dirEntry {
-		var resources []Resource
-		var err error
-		if entry.IsDir() && recursive {
-			resources, err = FromFolder(filepath.Join(path, entry.Name()), strict, recursive)
-		} else if !entry.IsDir() && (strings.HasSuffix(entry.Name(), ".yml") || strings.HasSuffix(entry.Name(), ".yaml")) {
-			resources, err = FromFile(filepath.Join(path, entry.Name()), strict)
+	for _, file := range files {
+		resources, err := l.FromFile(file)
+		if err != nil {
+			return nil, err
 		}
 		result = append(result, resources...)
+	}
+	return result, nil
+}
+
+// Files returns the resource files of a folder, and of its subfolders if Recursive, except the ones ignored
+// by IgnoreFileName files or filtered out by Filter, see FileFilter. Subfolders holding an overlay are skipped.
+func (l Loader) Files(path string) ([]string, error) {
+	return l.folderFiles(path, "", nil)
+}
+
+func (l Loader) folderFiles(root, relativeDir string, ignores []ignoreFile) ([]string, error) {
+	dir := filepath.Join(root, filepath.FromSlash(relativeDir))
+	dirEntry, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	ignores, err = withIgnoreFile(ignores, dir, relativeDir)
+	if err != nil {
+		return nil, err
+	}
+	var result []string
+	for _, entry := range dirEntry {
+		if entry.Name() == IgnoreFileName {
+			continue
+		}
+		entryPath := filepath.Join(dir, entry.Name())
+		relativePath := path.Join(relativeDir, entry.Name())
+		if reason := l.skipReason(relativePath, entry.IsDir(), ignores); reason != "" {
+			l.logSkipped(entryPath, reason)
+			continue
+		}
+		if !entry.IsDir() {
+			result = append(result, entryPath)
+			continue
+		}
+		if !l.Recursive {
+			l.logSkipped(entryPath, "subfolder, loaded with --recursive")
+			continue
+		}
+		if IsOverlay(entryPath) {
+			l.logSkipped(entryPath, "overlay, loaded with -k")
+			continue
+		}
+		files, err := l.folderFiles(root, relativePath, ignores)
 		if err != nil {
 			return nil, err
 		}
+		result = append(result, files...)
 	}
-
 	return result, nil
 }
 
+// ResourceFileExtensions are the extensions of the files loaded from folders.
+var ResourceFileExtensions = []string{".yaml", ".yml", ".json", ".ndjson"}
+
+func isResourceFile(name string) bool {
+	for _, extension := range ResourceFileExtensions {
+		if strings.HasSuffix(name, extension) {
+			return true
+		}
+	}
+	return false
+}
+
+// FromYamlByte parses resources, their includes are relative to the current directory.
+// Their ${exec:} and ${file:} secret references are refused, see Loader.AllowExecSecrets.
+// data can hold YAML documents, JSON values or JSON lines, each one a resource, an array of resources or a List.
 func FromYamlByte(data []byte, strict bool) ([]Resource, error) {
-	data, err := expandEnvVars(data, strict)
+	return fromYamlByte(data, parseOptions{strict: strict, dir: ".", resolved: newSecretCache()})
+}
+
+// ListKind wraps resources in items, e.g. {"apiVersion": "v1", "kind": "List", "items": [...]}.
+const ListKind = "List"
+
+// parseOptions are the options of fromYamlByte.
+type parseOptions struct {
+	// strict fails on missing environment variables
+	strict bool
+	// dir is the directory includes are relative to
+	dir string
+	// strictYaml validates the documents before resolving their includes, see validateDocument
+	strictYaml bool
+	// source prefixes the locations of strictYaml errors, e.g. the path of the file
+	source string
+	// secrets tells which secret references can be resolved
+	secrets SecretPolicy
+	// resolved caches the values of the secret references of the load
+	resolved *secretCache
+	// remote and root confine the includes of a remote source, see confine
+	remote string
+	root   string
+}
+
+func fromYamlByte(data []byte, options parseOptions) ([]Resource, error) {
+	data, err := expandEnvVars(data, options.strict)
 	if err != nil {
 		return nil, err
 	}
-	reader := bytes.NewReader(data)
-	var yamlData interface{}
-	results := make([]Resource, 0, 2)
-	d := yaml.NewDecoder(reader)
-	for {
-		err := d.Decode(&yamlData)
-		if err == io.EOF {
-			break
-		} else if err != nil {
+	documents, err := decodeDocuments(data)
+	if err != nil {
+		if options.strictYaml {
+			return nil, fmt.Errorf("%s: %w", options.source, err)
+		}
+		return nil, err
+	}
+	if options.strictYaml {
+		err = checkTabIndentation(data, documents, options.source)
+		if err != nil {
 			return nil, err
 		}
-		yamlByte, err := yaml.Marshal(yamlData)
+	}
+	results := make([]Resource, 0, 2)
+	for _, document := range documents {
+		if options.strictYaml {
+			err = validateDocument(document, options.source)
+			if err != nil {
+				return nil, err
+			}
+		}
+		verbatim, err := resolveIncludes(document, options)
 		if err != nil {
 			return nil, err
 		}
-		result, err := yamlByteToResource(yamlByte)
+		secrets, err := resolveSecretReferences(document, verbatim, options.secrets, options.resolved)
 		if err != nil {
 			return nil, err
 		}
-		results = append(results, result)
+		for _, item := range listItems(document) {
+			var yamlData interface{}
+			err = item.Decode(&yamlData)
+			if err != nil {
+				return nil, err
+			}
+			yamlByte, err := yaml.Marshal(yamlData)
+			if err != nil {
+				return nil, err
+			}
+			result, err := yamlByteToResource(yamlByte)
+			if err != nil {
+				return nil, err
+			}
+			result.SecretFields = secretFieldPaths(item, secrets)
+			results = append(results, result)
+		}
 	}
 	return results, nil
 }
 
+// decodeDocuments splits data in YAML documents, or in JSON values if data is a stream of JSON values such as JSON lines.
+func decodeDocuments(data []byte) ([]*yaml.Node, error) {
+	var documents []*yaml.Node
+	if trimmed := bytes.TrimSpace(data); bytes.HasPrefix(trimmed, []byte("{")) || bytes.HasPrefix(trimmed, []byte("[")) {
+		jsonDecoder := json.NewDecoder(bytes.NewReader(trimmed))
+		// lines of the values are counted from the start of data
+		skippedLines := bytes.Count(data[:bytes.Index(data, trimmed)], []byte("\n"))
+		var err error
+		for err == nil {
+			var value json.RawMessage
+			offset := jsonDecoder.InputOffset()
+			err = jsonDecoder.Decode(&value)
+			if err == nil {
+				document := &yaml.Node{}
+				err = yaml.Unmarshal(value, document)
+				valueStart := offset + int64(bytes.Index(trimmed[offset:], value))
+				shiftLines(document, skippedLines+bytes.Count(trimmed[:valueStart], []byte("\n")))
+				documents = append(documents, document)
+			}
+		}
+		if err == io.EOF {
+			return documents, nil
+		}
+		// not JSON, e.g. a YAML flow mapping
+		documents = nil
+	}
+	d := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		document := &yaml.Node{}
+		err := d.Decode(document)
+		if err == io.EOF {
+			return documents, nil
+		} else if err != nil {
+			return nil, err
+		}
+		documents = append(documents, document)
+	}
+}
+
+// listItems returns the resources of a document: the document itself, the elements of an array or the items of a List.
+func listItems(node *yaml.Node) []*yaml.Node {
+	for node.Kind == yaml.DocumentNode && len(node.Content) > 0 || node.Kind == yaml.AliasNode {
+		if node.Kind == yaml.AliasNode {
+			node = node.Alias
+		} else {
+			node = node.Content[0]
+		}
+	}
+	var items []*yaml.Node
+	switch node.Kind {
+	case yaml.SequenceNode:
+		items = node.Content
+	case yaml.MappingNode:
+		if kind := mappingValue(node, "kind"); kind == nil || kind.Value != ListKind {
+			return []*yaml.Node{node}
+		}
+		if list := mappingValue(node, "items"); list != nil && list.Kind == yaml.SequenceNode {
+			items = list.Content
+		}
+	default:
+		return []*yaml.Node{node}
+	}
+	var result []*yaml.Node
+	for _, item := range items {
+		result = append(result, listItems(item)...)
+	}
+	return result
+}
+
 var envVarRegex = regexp.MustCompile(`(\$|\$\$)\{([^}]+)\}`)
 
 // expandEnv replaces ${var} or $var in config according to the values of the current environment variables.
 // The replacement is case-sensitive. References to undefined variables are replaced by the empty string.
 // A default value can be given by using the form ${var:-default value}.
+// Secret references ${file:/path}, ${exec:command}, ${vault:path#key} and ${base64:value} are kept, to be resolved
+// once the YAML is parsed by resolveSecretReferences.
 func expandEnvVars(input []byte, strict bool) ([]byte, error) {
 	missingEnvVars := make([]string, 0)
 	result := envVarRegex.ReplaceAllFunc(input, func(match []byte) []byte {
-		varName := string(match[2 : len(match)-1])
+		varName := string(match[bytes.IndexByte(match, '{')+1 : len(match)-1])
 		defaultValue := ""
+		if _, _, ok := parseSecretReference(varName); ok {
+			return match
+		}
 		// If the match has a double $$, we assume it will be needed by the server.
 		// We're only trimming the first $ in this case.
 		if strings.HasPrefix(string(match), "$$") {
@@ -153,7 +386,7 @@ func expandEnvVars(input []byte, strict bool) ([]byte, error) {
 
 		// use default value
 		if (!isFound || value == "") && defaultValue != "" {
-			return []byte(defaultValue)
+			return []byte(escapeSecretReferences(defaultValue))
 		}
 
 		if strict {
@@ -167,7 +400,7 @@ func expandEnvVars(input []byte, strict bool) ([]byte, error) {
 				return []byte("")
 			}
 		}
-		return []byte(value)
+		return []byte(escapeSecretReferences(value))
 	})
 	if len(missingEnvVars) > 0 {
 		return nil, fmt.Errorf("Missing environment variables: %s", strings.Join(missingEnvVars, ", "))
@@ -197,58 +430,24 @@ func yamlByteToResource(data []byte) (Resource, error) {
 	return result, err
 }
 
-func loadTextFromFile(r *Resource, jsonPathForFilePath string, destJSONPath string) error {
-	jsonData, err := gabs.ParseJSON(r.Json)
-	if err != nil {
-		return err
-	}
-
-	pathExist := jsonData.ExistsP(jsonPathForFilePath)
-	if pathExist {
-		filePath, ok := jsonData.Path(jsonPathForFilePath).Data().(string)
-		if !ok {
-			return fmt.Errorf("%s is not a string", filePath)
-		}
-		fileContent, err := os.ReadFile(filePath)
-		if err != nil {
-			return fmt.Errorf("failed to read file %s: \"%w\"", filePath, err)
-		}
-		_, err = jsonData.SetP(string(fileContent), destJSONPath)
-		if err != nil {
-			return err
-		}
-		err = jsonData.DeleteP(jsonPathForFilePath)
-		if err != nil {
-			return err
-		}
-		finalJSON := []byte(jsonData.String())
-		return r.UnmarshalJSON(finalJSON)
-	}
-	return nil
-}
-
-func expendIncludeFiles(r *Resource) error {
-	// Expend spec.schemaFile into spec.schema and remove spec.schemaFile if kind is Subject
-	switch r.Kind {
-	case "Subject":
-		return loadTextFromFile(r, "spec.schemaFile", "spec.schema")
-	case "Topic":
-		return loadTextFromFile(r, "metadata.labels.conduktor~1io/descriptionFile", "metadata.labels.conduktor~1io/description")
-	}
-	return nil
-}
-
 func (r *Resource) PrintPreservingOriginalFieldOrder() error {
 	var data orderedjson.OrderedData //using this instead of interface{} keep json order
 	var finalData interface{}        // in case it does not work we will failback to deserializing directly to interface{}
-	err := json.Unmarshal(r.Json, &data)
+	masked := r.WithSecretFieldsMasked().Json
+	err := json.Unmarshal(masked, &data)
 	if err != nil {
-		err = json.Unmarshal(r.Json, &finalData)
+		err = json.Unmarshal(masked, &finalData)
 		if err != nil {
 			return err
 		}
 	} else {
 		finalData = data
 	}
-	return printutils.PrintResourceLikeYamlFile(os.Stdout, finalData)
+	var output strings.Builder
+	err = printutils.PrintResourceLikeYamlFile(&output, finalData)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprint(os.Stdout, output.String())
+	return err
 }
@@ -2,9 +2,11 @@ package resource
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/davecgh/go-spew/spew"
@@ -489,3 +491,55 @@ func TestJsonMarshall(t *testing.T) {
 		t.Errorf("Expected %s got %s", string(aResource.Json), string(bytes))
 	}
 }
+
+func TestJsonAndListManifests(t *testing.T) {
+	topic := `{"apiVersion":"v2","kind":"Topic","metadata":{"cluster":"prod","name":"%s"},"spec":{"partitions":3}}`
+	dir := writeFiles(t, map[string]string{
+		"array.json":   "[" + fmt.Sprintf(topic, "a1") + ",\n" + fmt.Sprintf(topic, "a2") + "]",
+		"lines.ndjson": fmt.Sprintf(topic, "l1") + "\n" + fmt.Sprintf(topic, "l2") + "\n",
+		"list.yaml":    "apiVersion: v1\nkind: List\nitems:\n  - " + fmt.Sprintf(topic, "y1") + "\n---\n" + fmt.Sprintf(topic, "y2") + "\n",
+		"list.json":    `{"apiVersion":"v1","kind":"List","items":[` + fmt.Sprintf(topic, "j1") + `]}`,
+		"ignored.txt":  fmt.Sprintf(topic, "ignored"),
+	})
+
+	resources, err := FromFolder(dir, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, res := range resources {
+		names = append(names, res.Name)
+		if string(res.Json) != fmt.Sprintf(topic, res.Name) {
+			t.Errorf("Unexpected json %s", res.Json)
+		}
+	}
+	expected := []string{"a1", "a2", "l1", "l2", "j1", "y1", "y2"}
+	if strings.Join(names, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v got %v", expected, names)
+	}
+
+	resources, err = FromYamlByte([]byte("{kind: Topic, metadata: {name: flow}}"), true)
+	if err != nil || len(resources) != 1 || resources[0].Name != "flow" {
+		t.Errorf("Expected a YAML flow mapping to be parsed got %v %v", resources, err)
+	}
+}
+
+func TestJsonUnmarshalShouldNotReadIncludedFiles(t *testing.T) {
+	schema, err := os.CreateTemp("", "schema.avsc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(schema.Name())
+	if _, err := schema.WriteString(`{"type": "long"}`); err != nil {
+		t.Fatal(err)
+	}
+
+	var decodedResource Resource
+	err = json.Unmarshal([]byte(`{"apiVersion":"v1","kind":"Subject","metadata":{"cluster":"cluster-a","name":"abc"},"spec":{"schemaFile":"`+schema.Name()+`"}}`), &decodedResource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decodedResource.Spec["schemaFile"] != schema.Name() || decodedResource.Spec["schema"] != nil {
+		t.Errorf("Expected schemaFile to be kept as is got %v", decodedResource.Spec)
+	}
+}
@@ -0,0 +1,359 @@
+package resource
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// SecretMask replaces the values resolved from secret references and the sensitive fields in outputs.
+const SecretMask = "***"
+
+// SecretResolver resolves the references of a scheme, e.g. secret/kafka#password for ${vault:secret/kafka#password}.
+type SecretResolver interface {
+	Resolve(reference string) (string, error)
+}
+
+type SecretResolverFunc func(reference string) (string, error)
+
+func (f SecretResolverFunc) Resolve(reference string) (string, error) {
+	return f(reference)
+}
+
+var secretsMutex sync.Mutex
+
+var secretResolvers = map[string]SecretResolver{
+	"file":   SecretResolverFunc(resolveFileSecret),
+	"exec":   SecretResolverFunc(resolveExecSecret),
+	"base64": SecretResolverFunc(resolveBase64Secret),
+	"vault":  &VaultResolver{},
+}
+
+// secretCache holds the value of each reference resolved by a load, so a command or Vault is only queried once per load.
+type secretCache struct {
+	mutex  sync.Mutex
+	values map[string]string
+}
+
+func newSecretCache() *secretCache {
+	return &secretCache{values: map[string]string{}}
+}
+
+// localSecretSchemes are the schemes running commands or reading files of this machine, see SecretPolicy.
+var localSecretSchemes = map[string]bool{"exec": true, "file": true}
+
+// SecretPolicy tells which secret references can be resolved while loading a source.
+type SecretPolicy struct {
+	// AllowLocal resolves the ${exec:} and ${file:} references, running commands and reading files of this machine
+	AllowLocal bool
+	// Remote names the URL or git source being loaded, whose ${exec:} and ${file:} references are refused whatever AllowLocal
+	Remote string
+}
+
+func (p SecretPolicy) check(scheme string) error {
+	if !localSecretSchemes[scheme] {
+		return nil
+	}
+	if p.Remote != "" {
+		return fmt.Errorf("not allowed in the remote source %s", p.Remote)
+	}
+	if !p.AllowLocal {
+		return fmt.Errorf("not allowed without --allow-exec-secrets")
+	}
+	return nil
+}
+
+// unmaskedSchemes are the schemes whose values are not secrets, e.g. a decoded base64 value, not masked in outputs.
+var unmaskedSchemes = map[string]bool{"base64": true}
+
+// RegisterSecretResolver adds or replaces the resolver of a scheme, e.g. to read ${vault:...} with another client.
+func RegisterSecretResolver(scheme string, resolver SecretResolver) {
+	secretsMutex.Lock()
+	defer secretsMutex.Unlock()
+	secretResolvers[scheme] = resolver
+}
+
+// parseSecretReference splits ${scheme:reference} when scheme has a resolver.
+// ${VAR:-default} is not a secret reference.
+func parseSecretReference(expression string) (SecretResolver, string, bool) {
+	scheme, reference, found := strings.Cut(expression, ":")
+	if !found || strings.HasPrefix(reference, "-") {
+		return nil, "", false
+	}
+	secretsMutex.Lock()
+	defer secretsMutex.Unlock()
+	resolver, ok := secretResolvers[scheme]
+	return resolver, reference, ok
+}
+
+func (c *secretCache) resolve(expression string, resolver SecretResolver, reference string) (string, error) {
+	c.mutex.Lock()
+	value, ok := c.values[expression]
+	c.mutex.Unlock()
+	if ok {
+		return value, nil
+	}
+	value, err := resolver.Resolve(reference)
+	if err != nil {
+		return "", err
+	}
+	c.mutex.Lock()
+	c.values[expression] = value
+	c.mutex.Unlock()
+	return value, nil
+}
+
+// resolveSecretReferences replaces the secret references of the scalar values of a YAML node, e.g. ${vault:secret/kafka#password},
+// by their values, the scalars becoming strings whatever the values. $${scheme:reference} is kept as ${scheme:reference}.
+// References are resolved once the YAML is parsed, so a value holding YAML syntax is not interpreted and references in
+// comments are ignored. The nodes of verbatim are kept as is, e.g. the text of an included file.
+// The references refused by policy are errors, the others are resolved once per cache. It returns the scalars holding a secret, to be masked in outputs, see secretFieldPaths.
+func resolveSecretReferences(node *yaml.Node, verbatim map[*yaml.Node]bool, policy SecretPolicy, cache *secretCache) (map[*yaml.Node]bool, error) {
+	secrets := map[*yaml.Node]bool{}
+	var secretErrors []string
+	walkValueScalars(node, verbatim, map[*yaml.Node]bool{}, func(scalar *yaml.Node) {
+		if !strings.Contains(scalar.Value, "${") {
+			return
+		}
+		resolved, secret := false, false
+		value := envVarRegex.ReplaceAllStringFunc(scalar.Value, func(match string) string {
+			groups := envVarRegex.FindStringSubmatch(match)
+			expression := groups[2]
+			resolver, reference, ok := parseSecretReference(expression)
+			if !ok {
+				return match
+			}
+			resolved = true
+			if groups[1] == "$$" {
+				return match[1:]
+			}
+			scheme, _, _ := strings.Cut(expression, ":")
+			secret = secret || !unmaskedSchemes[scheme]
+			if err := policy.check(scheme); err != nil {
+				secretErrors = append(secretErrors, fmt.Sprintf("${%s}: %s", expression, err))
+				return ""
+			}
+			value, err := cache.resolve(expression, resolver, reference)
+			if err != nil {
+				secretErrors = append(secretErrors, fmt.Sprintf("${%s}: %s", expression, err))
+			}
+			return value
+		})
+		if resolved {
+			*scalar = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value, Line: scalar.Line, Column: scalar.Column}
+		}
+		if secret {
+			secrets[scalar] = true
+		}
+	})
+	if len(secretErrors) > 0 {
+		return nil, fmt.Errorf("Could not resolve secret references: %s", strings.Join(secretErrors, ", "))
+	}
+	return secrets, nil
+}
+
+// secretFieldPaths returns the dotted paths of the secrets of a node, e.g. spec.password, going through arrays
+// as the paths of MaskSensitiveFields.
+func secretFieldPaths(node *yaml.Node, secrets map[*yaml.Node]bool) []string {
+	if len(secrets) == 0 {
+		return nil
+	}
+	found := map[string]bool{}
+	collectSecretFieldPaths(node, nil, secrets, map[*yaml.Node]bool{}, found)
+	paths := make([]string, 0, len(found))
+	for path := range found {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func collectSecretFieldPaths(node *yaml.Node, path []string, secrets, visiting map[*yaml.Node]bool, found map[string]bool) {
+	if node == nil || visiting[node] {
+		return
+	}
+	if secrets[node] && len(path) > 0 {
+		found[strings.Join(path, ".")] = true
+	}
+	visiting[node] = true
+	defer delete(visiting, node)
+	switch node.Kind {
+	case yaml.AliasNode:
+		collectSecretFieldPaths(node.Alias, path, secrets, visiting, found)
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			collectSecretFieldPaths(node.Content[i+1], append(path[:len(path):len(path)], node.Content[i].Value), secrets, visiting, found)
+		}
+	default:
+		for _, child := range node.Content {
+			collectSecretFieldPaths(child, path, secrets, visiting, found)
+		}
+	}
+}
+
+// WithSecretFieldsMasked returns a copy of the resource with the values resolved from secret references masked.
+func (r Resource) WithSecretFieldsMasked() Resource {
+	return r.WithSensitiveFieldsMasked(r.SecretFields)
+}
+
+// walkValueScalars calls visit for the scalars of node except the keys of the mappings and the nodes of skip.
+// The nodes visited through aliases are visited once.
+func walkValueScalars(node *yaml.Node, skip, visited map[*yaml.Node]bool, visit func(*yaml.Node)) {
+	if node == nil || skip[node] || visited[node] {
+		return
+	}
+	visited[node] = true
+	switch node.Kind {
+	case yaml.ScalarNode:
+		visit(node)
+	case yaml.AliasNode:
+		walkValueScalars(node.Alias, skip, visited, visit)
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			walkValueScalars(node.Content[i], skip, visited, visit)
+		}
+	default:
+		for _, child := range node.Content {
+			walkValueScalars(child, skip, visited, visit)
+		}
+	}
+}
+
+// escapeSecretReferences escapes the secret references of an environment variable value pasted in a file,
+// so that they are kept as is instead of resolved, see resolveSecretReferences.
+func escapeSecretReferences(value string) string {
+	return envVarRegex.ReplaceAllStringFunc(value, func(match string) string {
+		if _, _, ok := parseSecretReference(envVarRegex.FindStringSubmatch(match)[2]); ok {
+			return "$" + match
+		}
+		return match
+	})
+}
+
+func resolveFileSecret(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(data), "\n"), nil
+}
+
+// resolveExecSecret runs the command with the shell and returns its output.
+func resolveExecSecret(command string) (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("command failed: %s %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSuffix(string(output), "\n"), nil
+}
+
+func resolveBase64Secret(encoded string) (string, error) {
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("invalid base64: %s", err)
+	}
+	return string(decoded), nil
+}
+
+// VaultResolver reads <path>#<key> from the KV secrets engine of HashiCorp Vault, the path starting with the mount,
+// e.g. secret/kafka#password. Address and Token default to the VAULT_ADDR and VAULT_TOKEN environment variables.
+// KV version 2 paths can omit the data/ segment following the mount.
+type VaultResolver struct {
+	Address    string
+	Token      string
+	HTTPClient *http.Client
+}
+
+func (v *VaultResolver) Resolve(reference string) (string, error) {
+	path, key, found := strings.Cut(reference, "#")
+	if !found || path == "" || key == "" {
+		return "", fmt.Errorf("vault reference must be <path>#<key>")
+	}
+	address, token := v.Address, v.Token
+	if address == "" {
+		address = os.Getenv("VAULT_ADDR")
+	}
+	if token == "" {
+		token = os.Getenv("VAULT_TOKEN")
+	}
+	if address == "" || token == "" {
+		return "", fmt.Errorf("please set VAULT_ADDR and VAULT_TOKEN")
+	}
+
+	data, status, err := v.read(address, token, path)
+	if err == nil && status == http.StatusNotFound && !strings.Contains(path, "/data/") {
+		mount, rest, _ := strings.Cut(path, "/")
+		data, status, err = v.read(address, token, mount+"/data/"+rest)
+	}
+	if err != nil {
+		return "", err
+	}
+	if status != http.StatusOK {
+		return "", fmt.Errorf("could not read %s from Vault: status %d", path, status)
+	}
+
+	// KV version 2 nests the secret in data.data, next to data.metadata
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, isV2 := data["metadata"]; isV2 {
+			data = nested
+		}
+	}
+	value, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("key %s not found in Vault secret %s", key, path)
+	}
+	if str, ok := value.(string); ok {
+		return str, nil
+	}
+	encoded, err := json.Marshal(value)
+	return string(encoded), err
+}
+
+func (v *VaultResolver) read(address, token, path string) (map[string]interface{}, int, error) {
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(address, "/")+"/v1/"+strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if namespace := os.Getenv("VAULT_NAMESPACE"); namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+	httpClient := v.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, resp.StatusCode, nil
+	}
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid Vault response: %s", err)
+	}
+	return body.Data, resp.StatusCode, nil
+}
@@ -0,0 +1,293 @@
+package resource
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// execSecretsLoader resolves the ${exec:} and ${file:} references.
+var execSecretsLoader = Loader{Strict: true, AllowExecSecrets: true}
+
+func specOf(t *testing.T, yaml string) map[string]interface{} {
+	resources, err := execSecretsLoader.fromBytes("test", []byte(yaml), ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resources) != 1 {
+		t.Fatalf("Expected one resource got %d", len(resources))
+	}
+	return resources[0].Spec
+}
+
+func TestSecretReferencesShouldBeResolved(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(secretFile, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("USER_NAME", "alice")
+
+	spec := specOf(t, `
+apiVersion: v2
+kind: KafkaCluster
+metadata:
+  name: prod
+spec:
+  user: ${USER_NAME}
+  other: ${UNSET_VAR:-default}
+  file: ${file:`+secretFile+`}
+  base64: ${base64:ZnJvbS1iYXNlNjQ=}
+  escaped: $${file:/not/resolved}
+`)
+	expected := map[string]interface{}{
+		"user":    "alice",
+		"other":   "default",
+		"file":    "from-file",
+		"base64":  "from-base64",
+		"escaped": "${file:/not/resolved}",
+	}
+	for key, value := range expected {
+		if spec[key] != value {
+			t.Errorf("Expected %s to be %v got %v", key, value, spec[key])
+		}
+	}
+}
+
+func TestExecSecretReference(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+	spec := specOf(t, `
+apiVersion: v2
+kind: KafkaCluster
+metadata:
+  name: prod
+spec:
+  password: ${exec:printf 'from-%s\n' exec}
+`)
+	if spec["password"] != "from-exec" {
+		t.Errorf("Expected from-exec got %v", spec["password"])
+	}
+
+	_, err := execSecretsLoader.fromBytes("test", []byte("kind: KafkaCluster\nmetadata:\n  name: prod\nspec:\n  password: ${exec:exit 3}\n"), ".")
+	if err == nil || !strings.Contains(err.Error(), "${exec:exit 3}") {
+		t.Errorf("Expected the failing reference in the error got %v", err)
+	}
+}
+
+func TestSecretReferenceErrors(t *testing.T) {
+	_, err := execSecretsLoader.fromBytes("test", []byte("kind: KafkaCluster\nmetadata:\n  name: prod\nspec:\n  password: ${file:/does/not/exist}\n"), ".")
+	if err == nil || !strings.Contains(err.Error(), "Could not resolve secret references") || !strings.Contains(err.Error(), "/does/not/exist") {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestExecAndFileSecretsShouldRequireOptIn(t *testing.T) {
+	for _, reference := range []string{"${exec:echo secret}", "${file:/etc/hostname}"} {
+		data := []byte("kind: KafkaCluster\nmetadata:\n  name: prod\nspec:\n  password: " + reference + "\n")
+		_, err := FromYamlByte(data, true)
+		if err == nil || !strings.Contains(err.Error(), "not allowed without --allow-exec-secrets") {
+			t.Errorf("Expected %s to be refused without opt-in got %v", reference, err)
+		}
+	}
+}
+
+func TestExecSecretsShouldBeRefusedInURLSources(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("kind: KafkaCluster\nmetadata:\n  name: prod\nspec:\n  password: ${exec:echo secret}\n"))
+	}))
+	defer server.Close()
+
+	_, err := execSecretsLoader.Load(server.URL + "/cluster.yaml")
+	if err == nil || !strings.Contains(err.Error(), "not allowed in the remote source "+server.URL+"/cluster.yaml") {
+		t.Errorf("Expected ${exec:} to be refused in a URL source got %v", err)
+	}
+}
+
+func TestPluggableSecretResolver(t *testing.T) {
+	RegisterSecretResolver("stub", SecretResolverFunc(func(reference string) (string, error) {
+		return "stub-" + reference, nil
+	}))
+	spec := specOf(t, "kind: KafkaCluster\nmetadata:\n  name: prod\nspec:\n  password: ${stub:kafka}\n")
+	if spec["password"] != "stub-kafka" {
+		t.Errorf("Expected stub-kafka got %v", spec["password"])
+	}
+}
+
+func TestSecretValuesShouldBeStringsWhateverTheirSyntax(t *testing.T) {
+	values := map[string]string{
+		"comment": "pass # word",
+		"mapping": "key: value",
+		"quotes":  `it's "quoted"`,
+		"lines":   "first\nsecond: 2",
+		"number":  "1234",
+	}
+	RegisterSecretResolver("syntax", SecretResolverFunc(func(reference string) (string, error) {
+		return values[reference], nil
+	}))
+	spec := specOf(t, `
+apiVersion: v2
+kind: KafkaCluster
+metadata:
+  name: prod
+spec:
+  comment: ${syntax:comment}
+  mapping: ${syntax:mapping}
+  quotes: "${syntax:quotes}"
+  lines: ${syntax:lines}
+  number: ${syntax:number}
+  prefixed: user-${syntax:number}
+  escaped: $${syntax:comment}
+`)
+	for key, value := range values {
+		if spec[key] != value {
+			t.Errorf("Expected %s to be %q got %#v", key, value, spec[key])
+		}
+	}
+	if spec["prefixed"] != "user-1234" || spec["escaped"] != "${syntax:comment}" {
+		t.Errorf("Unexpected prefixed %#v or escaped %#v", spec["prefixed"], spec["escaped"])
+	}
+}
+
+func TestSecretReferencesShouldOnlyBeResolvedInValues(t *testing.T) {
+	var resolved []string
+	RegisterSecretResolver("track", SecretResolverFunc(func(reference string) (string, error) {
+		resolved = append(resolved, reference)
+		return "value", nil
+	}))
+	t.Setenv("FROM_ENV", "${track:env}")
+	spec := specOf(t, `
+# ${track:comment}
+apiVersion: v2
+kind: KafkaCluster
+metadata:
+  name: prod
+spec:
+  password: ${track:value} # ${track:trailing}
+  env: ${FROM_ENV}
+`)
+	if len(resolved) != 1 || resolved[0] != "value" {
+		t.Errorf("Expected only the value to be resolved got %v", resolved)
+	}
+	if spec["env"] != "${track:env}" {
+		t.Errorf("Expected a reference of an environment variable to be kept got %v", spec["env"])
+	}
+}
+
+func TestVaultResolver(t *testing.T) {
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/kafka":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{"data": map[string]interface{}{"password": "from-vault"}, "metadata": map[string]interface{}{}},
+			})
+		case "/v1/kv1/kafka":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"password": "from-kv1"}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer vault.Close()
+	resolver := &VaultResolver{Address: vault.URL, Token: "root"}
+
+	tests := map[string]string{
+		"secret/kafka#password":      "from-vault",
+		"secret/data/kafka#password": "from-vault",
+		"kv1/kafka#password":         "from-kv1",
+	}
+	for reference, expected := range tests {
+		value, err := resolver.Resolve(reference)
+		if err != nil || value != expected {
+			t.Errorf("Resolving %s expected %s got %s %v", reference, expected, value, err)
+		}
+	}
+	for _, reference := range []string{"secret/kafka#missing", "secret/other#password", "secret/kafka"} {
+		if _, err := resolver.Resolve(reference); err == nil {
+			t.Errorf("Expected resolving %s to fail", reference)
+		}
+	}
+	if _, err := (&VaultResolver{Address: vault.URL, Token: "wrong"}).Resolve("secret/kafka#password"); err == nil {
+		t.Error("Expected an invalid token to fail")
+	}
+}
+
+func TestResolvedSecretsShouldBeMaskedByPath(t *testing.T) {
+	RegisterSecretResolver("masked", SecretResolverFunc(func(reference string) (string, error) {
+		return "s3cr3t-" + reference, nil
+	}))
+	resources, err := FromYamlByte([]byte(`
+kind: List
+items:
+  - kind: MaskedCluster
+    metadata:
+      name: prod
+    spec:
+      password: ${masked:prod}
+      encoded: ${base64:ZW5jb2RlZA==}
+      users:
+        - name: admin
+          password: ${masked:admin}
+  - kind: MaskedCluster
+    metadata:
+      name: staging
+    spec:
+      password: s3cr3t-prod
+`), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(resources[0].Json), `"password":"s3cr3t-prod"`) {
+		t.Fatalf("The secret should be sent to the server, got %s", resources[0].Json)
+	}
+	expected := []string{"spec.password", "spec.users.password"}
+	if !reflect.DeepEqual(resources[0].SecretFields, expected) {
+		t.Errorf("Expected secret fields %v got %v", expected, resources[0].SecretFields)
+	}
+	if len(resources[1].SecretFields) != 0 {
+		t.Errorf("Expected no secret field for a resource without reference got %v", resources[1].SecretFields)
+	}
+	masked := resources[0].String()
+	if strings.Contains(masked, "s3cr3t") || !strings.Contains(masked, `"encoded":"encoded"`) {
+		t.Errorf("Expected String to mask the secrets but not the base64 value got %s", masked)
+	}
+	if !strings.Contains(resources[1].String(), "s3cr3t-prod") {
+		t.Errorf("Expected a value equal to a secret to be kept in another resource got %s", resources[1].String())
+	}
+}
+
+func TestSecretReferencesShouldBeResolvedOncePerLoad(t *testing.T) {
+	calls := 0
+	RegisterSecretResolver("counted", SecretResolverFunc(func(reference string) (string, error) {
+		calls++
+		return "value-" + reference, nil
+	}))
+	dir := t.TempDir()
+	for _, name := range []string{"a.yaml", "b.yaml"} {
+		err := os.WriteFile(filepath.Join(dir, name), []byte("kind: Topic\nmetadata:\n  name: "+name+"\nspec:\n  password: ${counted:kafka}\n"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := (Loader{}).Load(dir); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected the reference to be resolved once by the load of the folder, got %d calls", calls)
+	}
+	if _, err := (Loader{}).Load(dir); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected the reference to be resolved again by another load, got %d calls", calls)
+	}
+}
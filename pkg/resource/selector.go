@@ -0,0 +1,231 @@
+package resource
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	gabs "github.com/Jeffail/gabs/v2"
+)
+
+const (
+	OpEqual        = "="
+	OpNotEqual     = "!="
+	OpGreater      = ">"
+	OpGreaterEqual = ">="
+	OpLower        = "<"
+	OpLowerEqual   = "<="
+	OpExists       = "exists"
+	OpNotExists    = "!exists"
+)
+
+// operators ordered so that two characters operators are matched before their one character prefix.
+var selectorOperators = []string{"==", OpNotEqual, OpGreaterEqual, OpLowerEqual, OpEqual, OpGreater, OpLower}
+
+// Requirement is a single condition on a resource field, e.g. spec.partitions>12.
+type Requirement struct {
+	Path     []string
+	Operator string
+	Value    string
+}
+
+// Selector is a list of requirements that must all hold for a resource to match.
+type Selector []Requirement
+
+func (r Requirement) String() string {
+	path := strings.Join(r.Path, ".")
+	switch r.Operator {
+	case OpExists:
+		return path
+	case OpNotExists:
+		return "!" + path
+	default:
+		return path + r.Operator + r.Value
+	}
+}
+
+// ParseFieldSelector parses a comma separated list of field requirements
+// like "spec.partitions>12,metadata.cluster=prod".
+func ParseFieldSelector(input string) (Selector, error) {
+	var result Selector
+	for _, part := range splitSelector(input) {
+		path, operator, value, found := cutOperator(part)
+		if !found {
+			return nil, fmt.Errorf("invalid field selector \"%s\": expected one of %s", part, strings.Join(selectorOperators, " "))
+		}
+		if path == "" {
+			return nil, fmt.Errorf("invalid field selector \"%s\": missing field path", part)
+		}
+		result = append(result, Requirement{
+			Path:     strings.Split(path, "."),
+			Operator: operator,
+			Value:    value,
+		})
+	}
+	return result, nil
+}
+
+// ParseLabelSelector parses a comma separated list of label requirements
+// like "owner=x,env!=prod,team,!deprecated".
+// Each requirement is turned into a requirement on metadata.labels.
+func ParseLabelSelector(input string) (Selector, error) {
+	var result Selector
+	for _, part := range splitSelector(input) {
+		key, operator, value, found := cutOperator(part)
+		if !found {
+			operator = OpExists
+			key = part
+			if strings.HasPrefix(part, "!") {
+				operator = OpNotExists
+				key = strings.TrimPrefix(part, "!")
+			}
+		} else if operator != OpEqual && operator != OpNotEqual {
+			return nil, fmt.Errorf("invalid label selector \"%s\": only =, == and != are supported", part)
+		}
+		if key == "" {
+			return nil, fmt.Errorf("invalid label selector \"%s\": missing label key", part)
+		}
+		result = append(result, Requirement{
+			Path:     []string{"metadata", "labels", key},
+			Operator: operator,
+			Value:    value,
+		})
+	}
+	return result, nil
+}
+
+func splitSelector(input string) []string {
+	var parts []string
+	for _, part := range strings.Split(input, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+func cutOperator(input string) (string, string, string, bool) {
+	bestIndex := -1
+	bestOperator := ""
+	for _, operator := range selectorOperators {
+		index := strings.Index(input, operator)
+		if index == -1 {
+			continue
+		}
+		// leftmost operator wins, on tie the longest one (they are ordered by length)
+		if bestIndex == -1 || index < bestIndex {
+			bestIndex = index
+			bestOperator = operator
+		}
+	}
+	if bestIndex == -1 {
+		return "", "", "", false
+	}
+	operator := bestOperator
+	if operator == "==" {
+		operator = OpEqual
+	}
+	return strings.TrimSpace(input[:bestIndex]), operator, strings.TrimSpace(input[bestIndex+len(bestOperator):]), true
+}
+
+// Matches returns true if all requirements of the selector hold for the given resource.
+// An empty selector matches everything.
+func (s Selector) Matches(res Resource) bool {
+	if len(s) == 0 {
+		return true
+	}
+	jsonData, err := gabs.ParseJSON(res.Json)
+	if err != nil {
+		return false
+	}
+	for _, requirement := range s {
+		if !requirement.matches(jsonData) {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter returns the resources matching the selector, keeping their order.
+func (s Selector) Filter(resources []Resource) []Resource {
+	if len(s) == 0 {
+		return resources
+	}
+	result := make([]Resource, 0, len(resources))
+	for _, res := range resources {
+		if s.Matches(res) {
+			result = append(result, res)
+		}
+	}
+	return result
+}
+
+func (r Requirement) matches(jsonData *gabs.Container) bool {
+	exists := jsonData.Exists(r.Path...)
+	switch r.Operator {
+	case OpExists:
+		return exists
+	case OpNotExists:
+		return !exists
+	case OpNotEqual:
+		if !exists {
+			return true
+		}
+	}
+	if !exists {
+		return false
+	}
+	actual, ok := scalarToString(jsonData.Search(r.Path...).Data())
+	if !ok {
+		return r.Operator == OpNotEqual
+	}
+	comparison := compareValues(actual, r.Value)
+	switch r.Operator {
+	case OpEqual:
+		return comparison == 0
+	case OpNotEqual:
+		return comparison != 0
+	case OpGreater:
+		return comparison > 0
+	case OpGreaterEqual:
+		return comparison >= 0
+	case OpLower:
+		return comparison < 0
+	case OpLowerEqual:
+		return comparison <= 0
+	}
+	return false
+}
+
+func scalarToString(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(v), true
+	case nil:
+		return "null", true
+	default:
+		return "", false
+	}
+}
+
+// compareValues compares numerically when both sides are numbers and as strings otherwise.
+func compareValues(actual, expected string) int {
+	actualNumber, actualErr := strconv.ParseFloat(actual, 64)
+	expectedNumber, expectedErr := strconv.ParseFloat(expected, 64)
+	if actualErr == nil && expectedErr == nil {
+		switch {
+		case actualNumber < expectedNumber:
+			return -1
+		case actualNumber > expectedNumber:
+			return 1
+		default:
+			return 0
+		}
+	}
+	return strings.Compare(actual, expected)
+}
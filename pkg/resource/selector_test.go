@@ -0,0 +1,98 @@
+package resource
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFieldSelector(t *testing.T) {
+	selector, err := ParseFieldSelector("spec.partitions>12, metadata.cluster==prod,spec.a!=b,spec.c>=1,spec.d<=2,spec.e<3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Selector{
+		{Path: []string{"spec", "partitions"}, Operator: OpGreater, Value: "12"},
+		{Path: []string{"metadata", "cluster"}, Operator: OpEqual, Value: "prod"},
+		{Path: []string{"spec", "a"}, Operator: OpNotEqual, Value: "b"},
+		{Path: []string{"spec", "c"}, Operator: OpGreaterEqual, Value: "1"},
+		{Path: []string{"spec", "d"}, Operator: OpLowerEqual, Value: "2"},
+		{Path: []string{"spec", "e"}, Operator: OpLower, Value: "3"},
+	}
+	if !reflect.DeepEqual(selector, expected) {
+		t.Errorf("Expected %v got %v", expected, selector)
+	}
+
+	_, err = ParseFieldSelector("spec.partitions")
+	if err == nil {
+		t.Error("Expected error for field selector without operator")
+	}
+	_, err = ParseFieldSelector("=12")
+	if err == nil {
+		t.Error("Expected error for field selector without path")
+	}
+}
+
+func TestParseLabelSelector(t *testing.T) {
+	selector, err := ParseLabelSelector("owner=x,conduktor.io/env!=prod,team,!deprecated")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Selector{
+		{Path: []string{"metadata", "labels", "owner"}, Operator: OpEqual, Value: "x"},
+		{Path: []string{"metadata", "labels", "conduktor.io/env"}, Operator: OpNotEqual, Value: "prod"},
+		{Path: []string{"metadata", "labels", "team"}, Operator: OpExists},
+		{Path: []string{"metadata", "labels", "deprecated"}, Operator: OpNotExists},
+	}
+	if !reflect.DeepEqual(selector, expected) {
+		t.Errorf("Expected %v got %v", expected, selector)
+	}
+
+	_, err = ParseLabelSelector("owner>x")
+	if err == nil {
+		t.Error("Expected error for unsupported label operator")
+	}
+}
+
+func TestSelectorFilter(t *testing.T) {
+	topicA := Resource{Name: "a", Json: []byte(`{"kind":"Topic","metadata":{"name":"a","cluster":"prod","labels":{"owner":"x"}},"spec":{"partitions":24,"compacted":true}}`)}
+	topicB := Resource{Name: "b", Json: []byte(`{"kind":"Topic","metadata":{"name":"b","cluster":"prod","labels":{"owner":"y"}},"spec":{"partitions":6}}`)}
+	topicC := Resource{Name: "c", Json: []byte(`{"kind":"Topic","metadata":{"name":"c","cluster":"dev"},"spec":{"partitions":100}}`)}
+	resources := []Resource{topicA, topicB, topicC}
+
+	testCases := []struct {
+		label    string
+		field    string
+		expected []string
+	}{
+		{"", "", []string{"a", "b", "c"}},
+		{"owner=x", "", []string{"a"}},
+		{"owner!=x", "", []string{"b", "c"}},
+		{"owner", "", []string{"a", "b"}},
+		{"!owner", "", []string{"c"}},
+		{"", "spec.partitions>12", []string{"a", "c"}},
+		{"", "spec.partitions>12,metadata.cluster=prod", []string{"a"}},
+		{"", "spec.partitions<=6", []string{"b"}},
+		{"", "spec.compacted=true", []string{"a"}},
+		{"", "metadata.name>=b", []string{"b", "c"}},
+		{"owner=y", "spec.partitions>12", []string{}},
+	}
+
+	for _, testCase := range testCases {
+		labels, err := ParseLabelSelector(testCase.label)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fields, err := ParseFieldSelector(testCase.field)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result := append(labels, fields...).Filter(resources)
+		names := make([]string, 0, len(result))
+		for _, r := range result {
+			names = append(names, r.Name)
+		}
+		if !reflect.DeepEqual(names, testCase.expected) {
+			t.Errorf("For -l '%s' --field-selector '%s' expected %v got %v", testCase.label, testCase.field, testCase.expected, names)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package resource
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/conduktor/ctl/internal/orderedjson"
+)
+
+// fieldMasker returns the mask of the value of a sensitive field found at location, e.g. spec.users[1].password.
+type fieldMasker func(location string, value []byte) string
+
+// MaskSensitiveFields replaces the values of the fields at the dotted paths of a JSON document by SecretMask,
+// keeping the order of the other fields. A path can go through keys holding dots, e.g. spec.properties.sasl.jaas.config,
+// and through arrays, e.g. spec.users.password masks the password of every user.
+// The document is returned as is if it is not valid JSON or has no sensitive field.
+func MaskSensitiveFields(data []byte, paths []string) []byte {
+	masked, _ := maskSensitiveFields(data, paths, func(string, []byte) string { return SecretMask })
+	return masked
+}
+
+// MaskSensitiveFieldsPair masks the sensitive fields of two versions of a document, e.g. the sides of a diff.
+// The values that differ are masked as "*** (before)" and "*** (after)" so the change of a credential still shows.
+func MaskSensitiveFieldsPair(before, after []byte, paths []string) ([]byte, []byte) {
+	beforeValues := map[string]string{}
+	maskSensitiveFields(before, paths, func(location string, value []byte) string {
+		beforeValues[location] = string(value)
+		return SecretMask
+	})
+	changed := map[string]bool{}
+	maskedAfter, _ := maskSensitiveFields(after, paths, func(location string, value []byte) string {
+		previous, ok := beforeValues[location]
+		if !ok || previous == string(value) {
+			return SecretMask
+		}
+		changed[location] = true
+		return SecretMask + " (after)"
+	})
+	maskedBefore, _ := maskSensitiveFields(before, paths, func(location string, _ []byte) string {
+		if changed[location] {
+			return SecretMask + " (before)"
+		}
+		return SecretMask
+	})
+	return maskedBefore, maskedAfter
+}
+
+// WithSensitiveFieldsMasked returns a copy of the resource with its sensitive fields masked, see MaskSensitiveFields.
+func (r Resource) WithSensitiveFieldsMasked(paths []string) Resource {
+	return r.withJson(MaskSensitiveFields(r.Json, paths))
+}
+
+func (r Resource) withJson(data []byte) Resource {
+	var parsed forParsingStruct
+	if json.Unmarshal(data, &parsed) == nil {
+		r.Metadata = parsed.Metadata
+		r.Spec = parsed.Spec
+	}
+	r.Json = data
+	return r
+}
+
+func maskSensitiveFields(data []byte, paths []string, mask fieldMasker) ([]byte, bool) {
+	if len(paths) == 0 || len(data) == 0 {
+		return data, false
+	}
+	var document orderedjson.OrderedData
+	if json.Unmarshal(data, &document) != nil {
+		return data, false
+	}
+	found := false
+	for _, path := range paths {
+		if path != "" && maskSensitiveField(document, "", strings.Split(path, "."), mask) {
+			found = true
+		}
+	}
+	if !found {
+		return data, false
+	}
+	masked, err := json.Marshal(document)
+	if err != nil {
+		return data, false
+	}
+	return masked, true
+}
+
+func maskSensitiveField(data orderedjson.OrderedData, location string, segments []string, mask fieldMasker) bool {
+	found := false
+	if array := data.GetArrayOrNil(); array != nil {
+		for i, element := range *array {
+			if maskSensitiveField(element, fmt.Sprintf("%s[%d]", location, i), segments, mask) {
+				found = true
+			}
+		}
+		return found
+	}
+	object := data.GetMapOrNil()
+	if object == nil {
+		return false
+	}
+	// a key can hold dots, e.g. sasl.jaas.config in spec.properties
+	for n := len(segments); n > 0; n-- {
+		key := strings.Join(segments[:n], ".")
+		value, ok := object.Get(key)
+		if !ok {
+			continue
+		}
+		fieldLocation := key
+		if location != "" {
+			fieldLocation = location + "." + key
+		}
+		if n < len(segments) {
+			found = maskSensitiveField(value, fieldLocation, segments[n:], mask) || found
+			continue
+		}
+		raw, err := json.Marshal(value)
+		if err != nil || string(raw) == "null" {
+			continue
+		}
+		object.Set(key, orderedjson.FromValue(mask(fieldLocation, raw)))
+		found = true
+	}
+	return found
+}
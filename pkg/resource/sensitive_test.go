@@ -0,0 +1,48 @@
+package resource
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMaskSensitiveFields(t *testing.T) {
+	document := `{"kind":"KafkaCluster","spec":{"properties":{"sasl.jaas.config":"jaas","bootstrap.servers":"kafka:9092"},"users":[{"name":"a","password":"pa"},{"name":"b","password":"pb"}],"schemaRegistry":{"password":null},"displayName":"prod"}}`
+	paths := []string{"spec.properties.sasl.jaas.config", "spec.users.password", "spec.schemaRegistry.password", "spec.missing"}
+
+	masked := string(MaskSensitiveFields([]byte(document), paths))
+	expected := `{"kind":"KafkaCluster","spec":{"properties":{"sasl.jaas.config":"***","bootstrap.servers":"kafka:9092"},"users":[{"name":"a","password":"***"},{"name":"b","password":"***"}],"schemaRegistry":{"password":null},"displayName":"prod"}}`
+	if masked != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, masked)
+	}
+
+	if string(MaskSensitiveFields([]byte("not json"), paths)) != "not json" {
+		t.Error("Expected invalid JSON to be returned as is")
+	}
+}
+
+func TestMaskSensitiveFieldsPair(t *testing.T) {
+	before := `{"spec":{"password":"old","token":"same"}}`
+	after := `{"spec":{"password":"new","token":"same","key":"added"}}`
+
+	maskedBefore, maskedAfter := MaskSensitiveFieldsPair([]byte(before), []byte(after), []string{"spec.password", "spec.token", "spec.key"})
+	if string(maskedBefore) != `{"spec":{"password":"*** (before)","token":"***"}}` {
+		t.Errorf("Unexpected before %s", maskedBefore)
+	}
+	if string(maskedAfter) != `{"spec":{"password":"*** (after)","token":"***","key":"***"}}` {
+		t.Errorf("Unexpected after %s", maskedAfter)
+	}
+}
+
+func TestResourceWithSensitiveFieldsMasked(t *testing.T) {
+	resources, err := FromYamlByte([]byte("kind: KafkaConnectCluster\nmetadata:\n  name: connect\nspec:\n  security:\n    password: s3cr3t\n"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	masked := resources[0].WithSensitiveFieldsMasked([]string{"spec.security.password"})
+	if strings.Contains(string(masked.Json), "s3cr3t") || masked.Spec["security"].(map[string]interface{})["password"] != SecretMask {
+		t.Errorf("Expected the password to be masked got %s", masked.Json)
+	}
+	if !strings.Contains(string(resources[0].Json), "s3cr3t") {
+		t.Error("The original resource should not be modified")
+	}
+}
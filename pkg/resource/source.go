@@ -0,0 +1,202 @@
+package resource
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Sources of resources besides local files and folders.
+const (
+	StdinSource     = "-"
+	GitSourcePrefix = "git::"
+)
+
+// Load loads the resources of a source:
+//   - "-" reads a stream of YAML or JSON documents from stdin
+//   - http:// and https:// URLs are fetched with HTTPClient
+//   - git::<repository>//<path>?ref=<ref> loads path, a file or folder, from a local or remote git repository,
+//     e.g. git::https://github.com/org/repo.git//topics?ref=v1.2.0, path and ref being optional
+//   - otherwise source is a local file or folder
+//
+// URL and git sources are loaded in a restricted mode: their ${exec:} and ${file:} secret references are refused,
+// see SecretPolicy, and their files and includes must be inside the fetched tree, see confine.
+func (l Loader) Load(source string) ([]Resource, error) {
+	l = l.withSecretCache()
+	switch {
+	case source == StdinSource:
+		stdin := l.Stdin
+		if stdin == nil {
+			stdin = os.Stdin
+		}
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read stdin: %w", err)
+		}
+		return l.fromBytes("stdin", data, ".")
+	case strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://"):
+		data, err := l.fetch(source)
+		if err != nil {
+			return nil, err
+		}
+		l.remote = source
+		return l.fromBytes(source, data, ".")
+	case strings.HasPrefix(source, GitSourcePrefix):
+		return l.fromGit(source)
+	default:
+		info, err := os.Stat(source)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			return l.FromFolder(source)
+		}
+		return l.FromFile(source)
+	}
+}
+
+func (l Loader) fromBytes(name string, data []byte, dir string) ([]Resource, error) {
+	if l.Values != nil {
+		var err error
+		data, err = RenderTemplate(name, data, l.Values, l.Strict)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return fromYamlByte(data, parseOptions{strict: l.Strict, dir: dir, strictYaml: l.StrictYaml, source: name, secrets: l.secretPolicy(),
+		resolved: l.withSecretCache().resolved, remote: l.remote, root: l.root})
+}
+
+// withSecretCache returns the loader with a cache of the secret references shared by the files of a load,
+// kept if the load is part of another one, e.g. the files of a folder.
+func (l Loader) withSecretCache() Loader {
+	if l.resolved == nil {
+		l.resolved = newSecretCache()
+	}
+	return l
+}
+
+func (l Loader) secretPolicy() SecretPolicy {
+	return SecretPolicy{AllowLocal: l.AllowExecSecrets, Remote: l.remote}
+}
+
+func (l Loader) fetch(url string) ([]byte, error) {
+	httpClient := l.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("failed to fetch %s: %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	return data, nil
+}
+
+// parseGitSource splits git::<repository>//<path>?ref=<ref>.
+func parseGitSource(source string) (repository, path, ref string, err error) {
+	repository = strings.TrimPrefix(source, GitSourcePrefix)
+	if index := strings.LastIndex(repository, "?"); index != -1 {
+		query := repository[index+1:]
+		repository = repository[:index]
+		for _, parameter := range strings.Split(query, "&") {
+			key, value, _ := strings.Cut(parameter, "=")
+			if key != "ref" {
+				return "", "", "", fmt.Errorf("invalid git source %s: unknown parameter %s, only ref is supported", source, key)
+			}
+			ref = value
+		}
+	}
+	// the // of a scheme, e.g. https://, does not separate the path
+	start := 0
+	if index := strings.Index(repository, "://"); index != -1 {
+		start = index + len("://")
+	}
+	if index := strings.Index(repository[start:], "//"); index != -1 {
+		path = repository[start+index+2:]
+		repository = repository[:start+index]
+	}
+	if repository == "" {
+		return "", "", "", fmt.Errorf("invalid git source %s: missing repository", source)
+	}
+	if strings.HasPrefix(ref, "-") {
+		return "", "", "", fmt.Errorf("invalid git source %s: invalid ref %s", source, ref)
+	}
+	return repository, path, ref, nil
+}
+
+// fromGit clones the repository of a git source in a temporary directory and loads its path from there.
+func (l Loader) fromGit(source string) ([]Resource, error) {
+	repository, path, ref, err := parseGitSource(source)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp("", "conduktor-git-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	err = runGit("", "clone", "--quiet", "--", repository, dir)
+	if err == nil && ref != "" {
+		err = runGit(dir, "checkout", "--quiet", ref)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", source, err)
+	}
+	fullPath := filepath.Join(dir, filepath.FromSlash(path))
+	if relative, err := filepath.Rel(dir, fullPath); err != nil || strings.HasPrefix(relative, "..") {
+		return nil, fmt.Errorf("invalid git source %s: path %s is outside of the repository", source, path)
+	}
+	l.remote = source
+	l.root, err = filepath.EvalSymlinks(dir)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := l.Load(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", source, err)
+	}
+	return resources, nil
+}
+
+// confine refuses a path outside of root, the fetched tree of the remote source, following symbolic links.
+// Nothing is inside an empty root, e.g. a URL source has no files to include.
+func confine(path, root, remote string) error {
+	if root == "" {
+		return fmt.Errorf("%s is not allowed in the remote source %s", path, remote)
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return err
+	}
+	if relative, err := filepath.Rel(root, resolved); err != nil || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("%s is outside of the remote source %s", path, remote)
+	}
+	return nil
+}
+
+// runGit runs git in dir, the current directory if empty.
+func runGit(dir string, args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		return fmt.Errorf("git %s: %s %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package resource
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sourceTopic = "apiVersion: v2\nkind: Topic\nmetadata:\n  name: orders\n  cluster: prod\nspec:\n  partitions: 3\n"
+
+func TestLoadFromStdin(t *testing.T) {
+	stdin := strings.NewReader(sourceTopic + "---\n" + `{"apiVersion": "v1", "kind": "Application", "metadata": {"name": "shop"}, "spec": {"title": "{{ .Values.title }}"}}`)
+	resources, err := Loader{Strict: true, Stdin: stdin, Values: map[string]interface{}{"title": "Shop"}}.Load(StdinSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resources) != 2 || resources[0].Name != "orders" || resources[1].Spec["title"] != "Shop" {
+		t.Errorf("Unexpected resources %v", resources)
+	}
+}
+
+func TestLoadFromURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/topics.yaml" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(sourceTopic))
+	}))
+	defer server.Close()
+
+	resources, err := Loader{Strict: true, HTTPClient: server.Client()}.Load(server.URL + "/topics.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resources) != 1 || resources[0].Name != "orders" {
+		t.Errorf("Unexpected resources %v", resources)
+	}
+
+	_, err = Loader{Strict: true}.Load(server.URL + "/missing.yaml")
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected a not found error got %v", err)
+	}
+}
+
+func TestParseGitSource(t *testing.T) {
+	tests := map[string][3]string{
+		"git::https://github.com/org/repo.git//topics/prod?ref=v1.2.0": {"https://github.com/org/repo.git", "topics/prod", "v1.2.0"},
+		"git::https://github.com/org/repo.git":                         {"https://github.com/org/repo.git", "", ""},
+		"git::/srv/repo//topics":                                       {"/srv/repo", "topics", ""},
+		"git::git@github.com:org/repo.git//topics.yaml?ref=main":       {"git@github.com:org/repo.git", "topics.yaml", "main"},
+	}
+	for source, expected := range tests {
+		repository, path, ref, err := parseGitSource(source)
+		if err != nil || repository != expected[0] || path != expected[1] || ref != expected[2] {
+			t.Errorf("Parsing %s expected %v got %s %s %s %v", source, expected, repository, path, ref, err)
+		}
+	}
+	for _, source := range []string{"git::", "git::/srv/repo?branch=main", "git::/srv/repo?ref=--upload-pack=x"} {
+		if _, _, _, err := parseGitSource(source); err == nil {
+			t.Errorf("Expected %s to be invalid", source)
+		}
+	}
+}
+
+func TestLoadFromGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	repository := writeFiles(t, map[string]string{"topics/orders.yaml": sourceTopic})
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = repository
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s %s", args, err, output)
+		}
+	}
+	git("init", "--quiet")
+	git("add", ".")
+	git("commit", "--quiet", "-m", "v1")
+	git("tag", "v1")
+	git("rm", "--quiet", "topics/orders.yaml")
+	git("commit", "--quiet", "-m", "v2")
+
+	resources, err := Loader{Strict: true}.Load("git::" + repository + "//topics?ref=v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resources) != 1 || resources[0].Name != "orders" {
+		t.Errorf("Unexpected resources %v", resources)
+	}
+
+	_, err = Loader{Strict: true}.Load("git::" + repository + "//topics")
+	if err == nil {
+		t.Error("Expected the folder removed from the default branch to be missing")
+	}
+	_, err = Loader{Strict: true}.Load("git::" + repository + "//../outside")
+	if err == nil || !strings.Contains(err.Error(), "outside of the repository") {
+		t.Errorf("Expected a path outside of the repository to be rejected got %v", err)
+	}
+	_, err = Loader{Strict: true}.Load("git::" + filepath.Join(repository, "missing"))
+	if err == nil || !strings.Contains(err.Error(), "failed to fetch") {
+		t.Errorf("Expected a missing repository to fail got %v", err)
+	}
+}
+
+// commitAll commits the files of dir in a new git repository.
+func commitAll(t *testing.T, dir string) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	for _, args := range [][]string{{"init", "--quiet"}, {"add", "."}, {"commit", "--quiet", "-m", "init"}} {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s %s", args, err, output)
+		}
+	}
+}
+
+func TestGitSourceShouldOnlyIncludeFilesOfTheRepository(t *testing.T) {
+	outside := writeFiles(t, map[string]string{"secret.txt": "s3cr3t"})
+	repository := writeFiles(t, map[string]string{
+		"schemas/orders.avsc": `{"type": "long"}`,
+		"inside/subject.yaml": "kind: Subject\nmetadata:\n  name: orders-value\nspec:\n  schema: !include ../schemas/orders.avsc\n",
+		"inside/link.yaml":    "kind: Subject\nmetadata:\n  name: link-value\nspec:\n  schema: !include ../schemas/link.avsc\n",
+		"outside/topic.yaml":  "kind: Topic\nmetadata:\n  name: orders\nspec:\n  description: !include " + filepath.Join(outside, "secret.txt") + "\n",
+		"exec/topic.yaml":     "kind: Topic\nmetadata:\n  name: orders\nspec:\n  description: ${exec:echo s3cr3t}\n",
+	})
+	if err := os.Symlink(filepath.Join(outside, "secret.txt"), filepath.Join(repository, "schemas", "link.avsc")); err != nil {
+		t.Fatal(err)
+	}
+	commitAll(t, repository)
+	loader := Loader{Strict: true, AllowExecSecrets: true}
+
+	resources, err := loader.Load("git::" + repository + "//inside/subject.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resources) != 1 || resources[0].Spec["schema"] != `{"type": "long"}` {
+		t.Errorf("Unexpected resources %v", resources)
+	}
+
+	_, err = loader.Load("git::" + repository + "//outside")
+	if err == nil || !strings.Contains(err.Error(), "outside of the remote source") {
+		t.Errorf("Expected an include outside of the repository to be refused got %v", err)
+	}
+	_, err = loader.Load("git::" + repository + "//inside/link.yaml")
+	if err == nil || !strings.Contains(err.Error(), "outside of the remote source") {
+		t.Errorf("Expected a symbolic link outside of the repository to be refused got %v", err)
+	}
+	_, err = loader.Load("git::" + repository + "//exec")
+	if err == nil || !strings.Contains(err.Error(), "not allowed in the remote source") {
+		t.Errorf("Expected ${exec:} to be refused in a git source got %v", err)
+	}
+}
+
+func TestURLSourceShouldNotIncludeFiles(t *testing.T) {
+	included := writeFiles(t, map[string]string{"secret.txt": "s3cr3t"})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("kind: Topic\nmetadata:\n  name: orders\nspec:\n  description: !include " + filepath.Join(included, "secret.txt") + "\n"))
+	}))
+	defer server.Close()
+
+	_, err := Loader{Strict: true}.Load(server.URL + "/topic.yaml")
+	if err == nil || !strings.Contains(err.Error(), "is not allowed in the remote source") {
+		t.Errorf("Expected an include to be refused in a URL source got %v", err)
+	}
+}
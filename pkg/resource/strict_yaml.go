@@ -0,0 +1,208 @@
+package resource
+
+import (
+	"fmt"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+var resourceKeys = []string{"apiVersion", "kind", "metadata", "spec"}
+var listKeys = []string{"apiVersion", "kind", "items"}
+
+// validateDocument checks that a document is a resource, an array of resources or a List, without duplicate keys,
+// with a string apiVersion, kind and metadata.name and no other top-level key than apiVersion, kind, metadata and spec.
+// Errors are located as source:line:column.
+func validateDocument(document *yaml.Node, source string) error {
+	if document.Kind == yaml.DocumentNode {
+		if len(document.Content) == 0 {
+			return locatedError(source, document, "empty document")
+		}
+		document = document.Content[0]
+	}
+	err := checkDuplicateKeys(document, source)
+	if err != nil {
+		return err
+	}
+	return validateResourceNode(document, source)
+}
+
+func validateResourceNode(node *yaml.Node, source string) error {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			err := validateResourceNode(item, source)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case yaml.MappingNode:
+	default:
+		return locatedError(source, node, "expected a resource, an array of resources or a List")
+	}
+
+	kind, err := requiredString(node, "kind", source)
+	if err != nil {
+		return err
+	}
+	allowedKeys := resourceKeys
+	if kind.Value == ListKind {
+		allowedKeys = listKeys
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i]
+		if !contains(allowedKeys, key.Value) {
+			return locatedError(source, key, fmt.Sprintf("unknown top-level key %s of %s, expected %s", key.Value, kind.Value, strings.Join(allowedKeys, ", ")))
+		}
+	}
+	if _, err := requiredString(node, "apiVersion", source); err != nil {
+		return err
+	}
+
+	if kind.Value == ListKind {
+		items := mappingValue(node, "items")
+		if items == nil || items.Kind != yaml.SequenceNode {
+			return locatedError(source, node, "missing items array of List")
+		}
+		return validateResourceNode(items, source)
+	}
+	metadata := mappingValue(node, "metadata")
+	if metadata == nil {
+		return locatedError(source, node, fmt.Sprintf("missing metadata of %s", kind.Value))
+	}
+	if metadata.Kind != yaml.MappingNode {
+		return locatedError(source, metadata, fmt.Sprintf("metadata of %s must be a mapping", kind.Value))
+	}
+	_, err = requiredString(metadata, "name", source)
+	return err
+}
+
+// requiredString returns the value of key in mapping, failing if it is missing, empty or not a string.
+func requiredString(mapping *yaml.Node, key, source string) (*yaml.Node, error) {
+	value := mappingValue(mapping, key)
+	if value == nil {
+		return nil, locatedError(source, mapping, "missing "+key)
+	}
+	if value.Kind != yaml.ScalarNode || (value.Tag != "!!str" && value.Tag != IncludeTag) {
+		return nil, locatedError(source, value, fmt.Sprintf("%s must be a string, got %s", key, describeNode(value)))
+	}
+	if value.Value == "" {
+		return nil, locatedError(source, value, "empty "+key)
+	}
+	return value, nil
+}
+
+func checkDuplicateKeys(node *yaml.Node, source string) error {
+	if node.Kind == yaml.MappingNode {
+		keys := make(map[string]*yaml.Node, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			if first, ok := keys[key.Value]; ok {
+				return locatedError(source, key, fmt.Sprintf("duplicate key %s, first defined at line %d", key.Value, first.Line))
+			}
+			keys[key.Value] = key
+		}
+	}
+	for _, child := range node.Content {
+		err := checkDuplicateKeys(child, source)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func describeNode(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "an array"
+	}
+	return strings.TrimPrefix(node.Tag, "!!") + " " + node.Value
+}
+
+// checkTabIndentation rejects the lines of data indented with tabs that YAML still parses, e.g. the continuation of
+// a plain scalar silently joined to the previous line. The content of block scalars and JSON documents, whose root
+// is a flow collection, may hold tabs.
+func checkTabIndentation(data []byte, documents []*yaml.Node, source string) error {
+	lines := strings.Split(string(data), "\n")
+	allowed := make([]bool, len(lines)+1)
+	for i, document := range documents {
+		root := document
+		if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+			root = root.Content[0]
+		}
+		if root.Style&yaml.FlowStyle == 0 {
+			allowBlockScalarLines(root, lines, allowed)
+			continue
+		}
+		end := len(lines)
+		if i+1 < len(documents) {
+			end = documents[i+1].Line - 1
+		}
+		for line := root.Line; line <= end && line < len(allowed); line++ {
+			allowed[line] = true
+		}
+	}
+	for i, line := range lines {
+		content := strings.TrimLeft(line, " \t")
+		if allowed[i+1] || content == "" || strings.HasPrefix(content, "#") {
+			continue
+		}
+		if column := strings.IndexByte(line[:len(line)-len(content)], '\t'); column != -1 {
+			return locatedErrorAt(source, i+1, column+1, "tab character in indentation, indent with spaces")
+		}
+	}
+	return nil
+}
+
+// allowBlockScalarLines marks the lines of the literal and folded scalars under node, more indented than their key.
+func allowBlockScalarLines(node *yaml.Node, lines []string, allowed []bool) {
+	if node.Kind == yaml.ScalarNode && node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 && node.Line <= len(lines) {
+		indentation := indentationOf(lines[node.Line-1])
+		for line := node.Line + 1; line <= len(lines); line++ {
+			text := lines[line-1]
+			if strings.TrimSpace(text) != "" && indentationOf(text) <= indentation {
+				break
+			}
+			allowed[line] = true
+		}
+	}
+	for _, child := range node.Content {
+		allowBlockScalarLines(child, lines, allowed)
+	}
+}
+
+func indentationOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+func locatedError(source string, node *yaml.Node, message string) error {
+	return locatedErrorAt(source, node.Line, node.Column, message)
+}
+
+func locatedErrorAt(source string, line, column int, message string) error {
+	if source == "" {
+		return fmt.Errorf("line %d, column %d: %s", line, column, message)
+	}
+	return fmt.Errorf("%s:%d:%d: %s", source, line, column, message)
+}
+
+// shiftLines adds lines to the line of node and its children, e.g. for a document decoded from the middle of a file.
+func shiftLines(node *yaml.Node, lines int) {
+	node.Line += lines
+	for _, child := range node.Content {
+		shiftLines(child, lines)
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
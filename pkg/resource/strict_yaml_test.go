@@ -0,0 +1,72 @@
+package resource
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStrictYamlShouldLocateErrors(t *testing.T) {
+	tests := map[string]string{
+		"kind: Topic\napiVersion: v2\nmetadata:\n  name: a\n  cluster: c\n  name: b\n":          "file.yaml:6:3: duplicate key name, first defined at line 4",
+		"apiVersion: v2\nmetadata:\n  name: a\n":                                                "file.yaml:1:1: missing kind",
+		"kind: Topic\nmetadata:\n  name: a\n":                                                   "file.yaml:1:1: missing apiVersion",
+		"kind: Topic\napiVersion: v2\nspec: {}\n":                                               "file.yaml:1:1: missing metadata of Topic",
+		"kind: Topic\napiVersion: v2\nmetadata:\n  cluster: c\n":                                "file.yaml:4:3: missing name",
+		"kind: Topic\napiVersion: v2\nmetadata:\n  name: 42\n":                                  "file.yaml:4:9: name must be a string, got int 42",
+		"kind: Topic\napiVersion: v2\nmetadata:\n  name: a\nspecs: {}\n":                        "file.yaml:5:1: unknown top-level key specs of Topic, expected apiVersion, kind, metadata, spec",
+		"kind: Topic\napiVersion: v2\nmetadata:\n  name: a\n---\nkind: Topic\napiVersion: v2\n": "file.yaml:6:1: missing metadata of Topic",
+		"{\"kind\": \"Topic\", \"apiVersion\": \"v2\", \"metadata\": {\"name\": \"a\"}}\n{\"kind\": \"Topic\", \"metadata\": {\"name\": \"b\"}}\n": "file.yaml:2:1: missing apiVersion",
+		"kind: List\napiVersion: v1\nitems:\n  - kind: Topic\n    apiVersion: v2\n    metadata: {}\n":                                              "file.yaml:6:15: missing name",
+	}
+	for content, expected := range tests {
+		dir := writeFiles(t, map[string]string{"file.yaml": content})
+		_, err := Loader{Strict: true, StrictYaml: true}.FromFile(filepath.Join(dir, "file.yaml"))
+		if err == nil || err.Error() != filepath.Join(dir, expected) {
+			t.Errorf("Expected %s got %v", filepath.Join(dir, expected), err)
+		}
+	}
+}
+
+func TestStrictYamlShouldAcceptValidResources(t *testing.T) {
+	content := "---\nkind: Topic\napiVersion: v2\nmetadata:\n  name: \"42\"\n  cluster: c\nspec:\n  partitions: 3\n" +
+		"---\nkind: List\napiVersion: v1\nitems:\n  - kind: Application\n    apiVersion: v1\n    metadata:\n      name: shop\n"
+	resources, err := Loader{Strict: true, StrictYaml: true}.fromBytes("file.yaml", []byte(content), ".")
+	if err != nil || len(resources) != 2 {
+		t.Errorf("Unexpected result %v %v", resources, err)
+	}
+
+	// without strict YAML, errors come when the resource is used
+	_, err = Loader{Strict: true}.fromBytes("file.yaml", []byte("kind: Topic\napiVersion: v2\nmetadata:\n  name: a\nspecs: {}\n"), ".")
+	if err != nil {
+		t.Errorf("Expected the unknown key to be accepted got %v", err)
+	}
+	_, err = Loader{Strict: true, StrictYaml: true}.fromBytes("file.yaml", []byte("kind: Topic\n  bad indentation: [\n"), ".")
+	if err == nil || !strings.HasPrefix(err.Error(), "file.yaml: yaml: line") {
+		t.Errorf("Expected a syntax error prefixed by the file got %v", err)
+	}
+}
+
+func TestStrictYamlShouldRejectTabIndentation(t *testing.T) {
+	// YAML joins the tab indented line to the description
+	content := "kind: Topic\napiVersion: v2\nmetadata:\n  name: a\nspec:\n  description: orders\n   \tof the shop\n"
+	_, err := Loader{Strict: true}.fromBytes("file.yaml", []byte(content), ".")
+	if err != nil {
+		t.Errorf("Expected the tab to be accepted without strict YAML got %v", err)
+	}
+	_, err = Loader{Strict: true, StrictYaml: true}.fromBytes("file.yaml", []byte(content), ".")
+	if err == nil || err.Error() != "file.yaml:7:4: tab character in indentation, indent with spaces" {
+		t.Errorf("Expected the tab to be rejected got %v", err)
+	}
+
+	valid := map[string]string{
+		"block scalar": "kind: Subject\napiVersion: v2\nmetadata:\n  name: a\nspec:\n  schema: |\n    {\n    \t\"type\": \"long\"\n\n    }\n  format: JSON\n",
+		"json":         "{\n\t\"kind\": \"Topic\",\n\t\"apiVersion\": \"v2\",\n\t\"metadata\": {\n\t\t\"name\": \"a\"\n\t}\n}\n",
+	}
+	for name, content := range valid {
+		_, err = Loader{Strict: true, StrictYaml: true}.fromBytes("file.yaml", []byte(content), ".")
+		if err != nil {
+			t.Errorf("Expected the tabs of the %s to be accepted got %v", name, err)
+		}
+	}
+}
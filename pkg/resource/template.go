@@ -0,0 +1,93 @@
+package resource
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// LoadTemplateValues merges the values of YAML files, the later ones overriding the former,
+// then the key=value pairs of sets where key is a dotted path, e.g. topic.partitions=6.
+// Set values are typed as in YAML: true is a boolean and 6 a number.
+func LoadTemplateValues(valueFiles []string, sets []string) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	for _, path := range valueFiles {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read values file %s: %w", path, err)
+		}
+		var fileValues map[string]interface{}
+		err = yaml.Unmarshal(data, &fileValues)
+		if err != nil {
+			return nil, fmt.Errorf("invalid values file %s: %w", path, err)
+		}
+		mergeValues(values, fileValues)
+	}
+	for _, set := range sets {
+		key, rawValue, found := strings.Cut(set, "=")
+		if !found || key == "" {
+			return nil, fmt.Errorf("invalid --set %s, expected key=value", set)
+		}
+		var value interface{}
+		if yaml.Unmarshal([]byte(rawValue), &value) != nil {
+			value = rawValue
+		}
+		segments := strings.Split(key, ".")
+		for i := len(segments) - 1; i > 0; i-- {
+			value = map[string]interface{}{segments[i]: value}
+		}
+		mergeValues(values, map[string]interface{}{segments[0]: value})
+	}
+	return values, nil
+}
+
+func mergeValues(dest, src map[string]interface{}) {
+	for key, value := range src {
+		srcMap, srcIsMap := value.(map[string]interface{})
+		destMap, destIsMap := dest[key].(map[string]interface{})
+		if srcIsMap && destIsMap {
+			mergeValues(destMap, srcMap)
+		} else {
+			dest[key] = value
+		}
+	}
+}
+
+// RenderTemplate renders a resource file with Go text/template, the sprig functions, toYaml and required.
+// Values are available as .Values. In strict mode, a missing value is an error.
+func RenderTemplate(name string, data []byte, values map[string]interface{}, strict bool) ([]byte, error) {
+	missingKey := "missingkey=zero"
+	if strict {
+		missingKey = "missingkey=error"
+	}
+	tmpl, err := template.New(name).Option(missingKey).Funcs(sprig.TxtFuncMap()).Funcs(template.FuncMap{
+		"toYaml":   toYaml,
+		"required": required,
+	}).Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid template %s: %w", name, err)
+	}
+	var output bytes.Buffer
+	err = tmpl.Execute(&output, map[string]interface{}{"Values": values})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render template %s: %w", name, err)
+	}
+	return output.Bytes(), nil
+}
+
+func toYaml(value interface{}) (string, error) {
+	data, err := yaml.Marshal(value)
+	return strings.TrimSuffix(string(data), "\n"), err
+}
+
+func required(message string, value interface{}) (interface{}, error) {
+	if value == nil || value == "" {
+		return nil, fmt.Errorf("%s", message)
+	}
+	return value, nil
+}
@@ -0,0 +1,93 @@
+package resource
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadTemplateValues(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"values.yaml":      "env: dev\ntopic:\n  partitions: 3\n  replication: 1\n",
+		"values-prod.yaml": "env: prod\ntopic:\n  replication: 3\n",
+	})
+
+	values, err := LoadTemplateValues(
+		[]string{filepath.Join(dir, "values.yaml"), filepath.Join(dir, "values-prod.yaml")},
+		[]string{"topic.partitions=6", "topic.compacted=true", "owner=team-a"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"env":   "prod",
+		"owner": "team-a",
+		"topic": map[string]interface{}{"partitions": 6, "replication": 3, "compacted": true},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("Expected %v got %v", expected, values)
+	}
+
+	if _, err := LoadTemplateValues(nil, []string{"novalue"}); err == nil {
+		t.Error("Expected a --set without = to be rejected")
+	}
+}
+
+func TestLoaderShouldRenderTemplates(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"topics.yaml": `{{- range .Values.topics }}
+---
+apiVersion: v2
+kind: Topic
+metadata:
+  name: {{ . | lower }}-{{ $.Values.env }}
+  cluster: ${CLUSTER}
+spec:
+  partitions: {{ if eq $.Values.env "prod" }}6{{ else }}1{{ end }}
+{{- end }}
+`,
+	})
+	t.Setenv("CLUSTER", "kafka")
+
+	resources, err := Loader{Strict: true, Values: map[string]interface{}{"env": "prod", "topics": []interface{}{"Orders", "Payments"}}}.FromFolder(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resources) != 2 || resources[0].Name != "orders-prod" || resources[1].Name != "payments-prod" {
+		t.Fatalf("Unexpected resources %v", resources)
+	}
+	if resources[0].Spec["partitions"] != 6.0 || resources[0].Metadata["cluster"] != "kafka" {
+		t.Errorf("Unexpected resource %v", resources[0])
+	}
+
+	resources, err = Loader{Strict: false, Values: map[string]interface{}{"env": "prod"}}.FromFile(filepath.Join(dir, "topics.yaml"))
+	if err != nil || len(resources) != 0 {
+		t.Errorf("Expected a missing value to render nothing in permissive mode, got %v %v", resources, err)
+	}
+	_, err = Loader{Strict: true, Values: map[string]interface{}{"env": "prod"}}.FromFile(filepath.Join(dir, "topics.yaml"))
+	if err == nil {
+		t.Error("Expected a missing value to fail in strict mode")
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	values := map[string]interface{}{"configs": map[string]interface{}{"retention.ms": 1000}}
+
+	rendered, err := RenderTemplate("test", []byte("configs:\n  {{- toYaml .Values.configs | nindent 2 }}\nname: {{ .Values.name | default \"fallback\" }}\n"), values, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(rendered) != "configs:\n  retention.ms: 1000\nname: fallback\n" {
+		t.Errorf("Unexpected rendering %q", rendered)
+	}
+
+	_, err = RenderTemplate("test", []byte("name: {{ .Values.missing.name }}"), values, true)
+	if err == nil {
+		t.Error("Expected a missing value to fail in strict mode")
+	}
+	_, err = RenderTemplate("test", []byte(`name: {{ required "name is required" .Values.name }}`), values, false)
+	if err == nil || !strings.Contains(err.Error(), "name is required") {
+		t.Errorf("Expected required to fail got %v", err)
+	}
+}
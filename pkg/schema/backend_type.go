@@ -1,8 +1,33 @@
 package schema
 
+import "strings"
+
 type BackendType int
 
 const (
 	CONSOLE BackendType = iota
 	GATEWAY
 )
+
+// String returns the name qualifying the kinds and runs defined by both backends, e.g. gateway in gateway:ServiceAccount.
+func (b BackendType) String() string {
+	if b == GATEWAY {
+		return "gateway"
+	}
+	return "console"
+}
+
+// QualifiedName returns the name of a kind or run qualified by its backend, e.g. gateway:ServiceAccount.
+func QualifiedName(backend BackendType, name string) string {
+	return backend.String() + ":" + name
+}
+
+// SplitQualifiedName returns the backend and the name of a qualified name, e.g. gateway and ServiceAccount
+// for gateway:ServiceAccount, and an empty backend for a name that is not qualified.
+func SplitQualifiedName(name string) (string, string) {
+	backend, unqualified, found := strings.Cut(name, ":")
+	if !found || (backend != CONSOLE.String() && backend != GATEWAY.String()) {
+		return "", name
+	}
+	return backend, unqualified
+}
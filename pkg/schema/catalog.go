@@ -2,6 +2,9 @@ package schema
 
 import (
 	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/conduktor/ctl/pkg/resource"
 )
@@ -19,8 +22,8 @@ func ConsoleDefaultCatalog() *Catalog {
 }
 
 func (catalog *Catalog) IsConsoleResource(res resource.Resource) bool {
-	kind, exists := catalog.Kind[res.Kind]
-	return exists && kind.IsConsoleKind()
+	kind, err := catalog.LookupKind(res.Kind, res.Version)
+	return err == nil && kind.IsConsoleKind()
 }
 
 func GatewayDefaultCatalog() *Catalog {
@@ -28,37 +31,181 @@ func GatewayDefaultCatalog() *Catalog {
 }
 
 func (catalog *Catalog) IsGatewayResource(res resource.Resource) bool {
-	kind, exists := catalog.Kind[res.Kind]
-	return exists && kind.IsGatewayKind()
+	kind, err := catalog.LookupKind(res.Kind, res.Version)
+	return err == nil && kind.IsGatewayKind()
 }
 
-// TODO: colision don't silently hide others.
+// LookupKind returns the kind of a name, which can be qualified by its backend, e.g. gateway:ServiceAccount.
+// A kind defined by both the Console and the Gateway is resolved with the group of apiVersion: gateway/v2 for
+// the Gateway, and console/v1 or an apiVersion without group like v1 for the Console.
+func (catalog *Catalog) LookupKind(name, apiVersion string) (Kind, error) {
+	return lookupKind(catalog.Kind, name, apiVersion)
+}
+
+func lookupKind(kinds KindCatalog, name, apiVersion string) (Kind, error) {
+	if kind, exists := kinds[name]; exists {
+		return kind, nil
+	}
+	backend, unqualified := SplitQualifiedName(name)
+	if backend != "" {
+		if kind, exists := kinds[unqualified]; exists && kind.backendType().String() == backend {
+			return kind, nil
+		}
+		return Kind{}, fmt.Errorf("kind %s not found", name)
+	}
+	consoleKind, inConsole := kinds[QualifiedName(CONSOLE, name)]
+	gatewayKind, inGateway := kinds[QualifiedName(GATEWAY, name)]
+	if !inConsole || !inGateway {
+		return Kind{}, fmt.Errorf("kind %s not found", name)
+	}
+	group, _, _ := strings.Cut(apiVersion, "/")
+	switch {
+	case group == GATEWAY.String():
+		return gatewayKind, nil
+	case apiVersion != "":
+		return consoleKind, nil
+	}
+	return Kind{}, fmt.Errorf("kind %s is defined by both the Console and the Gateway, use %s or %s", name, QualifiedName(CONSOLE, name), QualifiedName(GATEWAY, name))
+}
+
+// Collisions returns the sorted names of the kinds and of the runs defined by both the Console and the Gateway,
+// qualified by their backend in the catalog, e.g. console:ServiceAccount and gateway:ServiceAccount.
+func (catalog *Catalog) Collisions() ([]string, []string) {
+	var kinds, runs []string
+	for name := range catalog.Kind {
+		if backend, unqualified := SplitQualifiedName(name); backend == GATEWAY.String() {
+			if _, exists := catalog.Kind[QualifiedName(CONSOLE, unqualified)]; exists {
+				kinds = append(kinds, unqualified)
+			}
+		}
+	}
+	for name := range catalog.Run {
+		if backend, unqualified := SplitQualifiedName(name); backend == GATEWAY.String() {
+			if _, exists := catalog.Run[QualifiedName(CONSOLE, unqualified)]; exists {
+				runs = append(runs, unqualified)
+			}
+		}
+	}
+	sort.Strings(kinds)
+	sort.Strings(runs)
+	return kinds, runs
+}
+
+// SensitiveFields returns the dotted paths of the sensitive fields of a kind, nil if the kind is unknown.
+// For a kind defined by both the Console and the Gateway, the sensitive fields of both are returned.
+func (catalog *Catalog) SensitiveFields(kindName string) []string {
+	if catalog == nil {
+		return nil
+	}
+	kind, err := catalog.LookupKind(kindName, "")
+	if err == nil {
+		return kind.GetLatestKindVersion().GetSensitiveFields()
+	}
+	var sensitiveFields []string
+	for _, backend := range []BackendType{CONSOLE, GATEWAY} {
+		if kind, exists := catalog.Kind[QualifiedName(backend, kindName)]; exists {
+			sensitiveFields = append(sensitiveFields, kind.GetLatestKindVersion().GetSensitiveFields()...)
+		}
+	}
+	return sensitiveFields
+}
+
+// KeyOrder returns the key order of the resources of the version of a kind matching apiVersion, or of its latest version
+// if no version matches: the order of the properties of its schema, or of its apply example for a catalog without it,
+// like the offline defaults. nil if the kind is unknown.
+func (catalog *Catalog) KeyOrder(kindName, apiVersion string) *resource.KeyOrder {
+	if catalog == nil {
+		return nil
+	}
+	kind, err := catalog.LookupKind(kindName, apiVersion)
+	if err != nil {
+		return nil
+	}
+	kindVersion := kind.GetLatestKindVersion()
+	if version, err := ParseAPIVersion(apiVersion); err == nil {
+		if matching, exists := kind.Versions[version]; exists {
+			kindVersion = matching
+		}
+	}
+	if order := kindVersion.GetKeyOrder(); order != nil {
+		return order
+	}
+	return resource.KeyOrderOf(kindVersion.GetApplyExample())
+}
+
+// Merge returns the kinds and runs of both catalogs, the ones of other replacing the ones of the same backend.
+// The kinds and runs defined by both the Console and the Gateway are qualified by their backend instead of hiding
+// each other, e.g. console:ServiceAccount and gateway:ServiceAccount, see Collisions.
 func (catalog *Catalog) Merge(other *Catalog) Catalog {
 	result := Catalog{
 		Kind: make(map[string]Kind),
 		Run:  make(map[string]Run),
 	}
-	for kindName, kind := range catalog.Kind {
-		result.Kind[kindName] = kind
+	for _, source := range []*Catalog{catalog, other} {
+		for kindName, kind := range source.Kind {
+			mergeEntry(result.Kind, kindName, kind, Kind.backendType)
+		}
+		for runName, run := range source.Run {
+			mergeEntry(result.Run, runName, run, func(run Run) BackendType { return run.BackendType })
+		}
 	}
-	for kindName, kind := range other.Kind {
-		result.Kind[kindName] = kind
+	return result
+}
+
+// mergeEntry adds a kind or run to entries, qualifying its name and the one of the other backend on collision.
+func mergeEntry[T any](entries map[string]T, name string, entry T, backendOf func(T) BackendType) {
+	_, unqualified := SplitQualifiedName(name)
+	backend := backendOf(entry)
+	if existing, exists := entries[unqualified]; exists && backendOf(existing) != backend {
+		delete(entries, unqualified)
+		entries[QualifiedName(backendOf(existing), unqualified)] = existing
 	}
-	for runName, run := range catalog.Run {
-		result.Run[runName] = run
+	for _, other := range []BackendType{CONSOLE, GATEWAY} {
+		if _, exists := entries[QualifiedName(other, unqualified)]; exists && other != backend {
+			entries[QualifiedName(backend, unqualified)] = entry
+			return
+		}
+	}
+	entries[unqualified] = entry
+}
+
+// Replace replaces the kinds and runs of the catalog with the ones of other. The maps are updated in place
+// so that the copies of the catalog sharing them see the change.
+func (catalog *Catalog) Replace(other Catalog) {
+	clear(catalog.Kind)
+	for kindName, kind := range other.Kind {
+		catalog.Kind[kindName] = kind
 	}
+	clear(catalog.Run)
 	for runName, run := range other.Run {
-		result.Run[runName] = run
+		catalog.Run[runName] = run
 	}
-	return result
 }
 
 func buildCatalogFromByteSchema[T KindVersion](byteSchema []byte, backendType BackendType) *Catalog {
-	var jsonResult CatalogGeneric[T]
-	err := json.Unmarshal(byteSchema, &jsonResult)
+	result, err := catalogFromJSON[T](byteSchema, backendType)
 	if err != nil {
 		panic(err)
 	}
+	return result
+}
+
+// ConsoleCatalogFromJSON reads a Console catalog written as JSON, e.g. by printCatalog.
+func ConsoleCatalogFromJSON(data []byte) (*Catalog, error) {
+	return catalogFromJSON[*ConsoleKindVersion](data, CONSOLE)
+}
+
+// GatewayCatalogFromJSON reads a Gateway catalog written as JSON, e.g. by printCatalog.
+func GatewayCatalogFromJSON(data []byte) (*Catalog, error) {
+	return catalogFromJSON[*GatewayKindVersion](data, GATEWAY)
+}
+
+func catalogFromJSON[T KindVersion](data []byte, backendType BackendType) (*Catalog, error) {
+	var jsonResult CatalogGeneric[T]
+	err := json.Unmarshal(data, &jsonResult)
+	if err != nil {
+		return nil, err
+	}
 	var result = Catalog{
 		Kind: KindCatalog{},
 		Run:  RunCatalog{},
@@ -76,7 +223,7 @@ func buildCatalogFromByteSchema[T KindVersion](byteSchema []byte, backendType Ba
 		run.BackendType = backendType
 		result.Run[runName] = run
 	}
-	return &result
+	return &result, nil
 }
 
 type kindGeneric[T KindVersion] struct {
@@ -3,9 +3,10 @@ package schema
 import (
 	"reflect"
 	"testing"
+
+	"github.com/conduktor/ctl/pkg/resource"
 )
 
-// TODO: test for colision.
 func TestMerge(t *testing.T) {
 	catalog1 := &Catalog{
 		Kind: KindCatalog{
@@ -42,3 +43,134 @@ func TestMerge(t *testing.T) {
 		t.Errorf("expected %v, got %v", expected, mergedCatalog)
 	}
 }
+
+func TestReplaceUpdatesTheCopies(t *testing.T) {
+	catalog := Catalog{
+		Kind: KindCatalog{"kind1": Kind{Versions: map[int]KindVersion{1: &ConsoleKindVersion{}}}},
+		Run:  RunCatalog{"run1": Run{BackendType: CONSOLE}},
+	}
+	copied := catalog
+
+	catalog.Replace(Catalog{
+		Kind: KindCatalog{"kind2": Kind{Versions: map[int]KindVersion{1: &GatewayKindVersion{}}}},
+		Run:  RunCatalog{},
+	})
+
+	expected := Catalog{
+		Kind: KindCatalog{"kind2": Kind{Versions: map[int]KindVersion{1: &GatewayKindVersion{}}}},
+		Run:  RunCatalog{},
+	}
+	if !reflect.DeepEqual(copied, expected) {
+		t.Errorf("expected %v, got %v", expected, copied)
+	}
+}
+
+func TestMergeQualifiesCollisions(t *testing.T) {
+	console := &Catalog{
+		Kind: KindCatalog{
+			"ServiceAccount": NewKind(1, &ConsoleKindVersion{Name: "ServiceAccount"}),
+			"Topic":          NewKind(2, &ConsoleKindVersion{Name: "Topic"}),
+		},
+		Run: RunCatalog{"token": Run{Name: "token", BackendType: CONSOLE}},
+	}
+	gateway := &Catalog{
+		Kind: KindCatalog{
+			"ServiceAccount": NewKind(2, &GatewayKindVersion{Name: "ServiceAccount"}),
+		},
+		Run: RunCatalog{"token": Run{Name: "token", BackendType: GATEWAY}},
+	}
+
+	merged := console.Merge(gateway)
+
+	expectedKinds := []string{"ServiceAccount"}
+	expectedRuns := []string{"token"}
+	kinds, runs := merged.Collisions()
+	if !reflect.DeepEqual(kinds, expectedKinds) || !reflect.DeepEqual(runs, expectedRuns) {
+		t.Errorf("expected collisions %v %v, got %v %v", expectedKinds, expectedRuns, kinds, runs)
+	}
+	if _, exists := merged.Kind["ServiceAccount"]; exists {
+		t.Error("expected the colliding kind to be qualified")
+	}
+	consoleKind := merged.Kind["console:ServiceAccount"]
+	gatewayKind := merged.Kind["gateway:ServiceAccount"]
+	if !consoleKind.IsConsoleKind() || !gatewayKind.IsGatewayKind() {
+		t.Errorf("expected both kinds to be kept, got %v", merged.Kind)
+	}
+	if merged.Run["gateway:token"].BackendType != GATEWAY || merged.Run["console:token"].BackendType != CONSOLE {
+		t.Errorf("expected both runs to be kept, got %v", merged.Run)
+	}
+
+	// merging again keeps the qualified names
+	remerged := merged.Merge(&Catalog{Kind: KindCatalog{"ServiceAccount": NewKind(3, &GatewayKindVersion{Name: "ServiceAccount"})}})
+	remergedKind := remerged.Kind["gateway:ServiceAccount"]
+	if len(remerged.Kind) != 3 || remergedKind.MaxVersion() != 3 {
+		t.Errorf("expected the gateway kind to be replaced, got %v", remerged.Kind)
+	}
+}
+
+func TestLookupKind(t *testing.T) {
+	console := &Catalog{Kind: KindCatalog{
+		"ServiceAccount": NewKind(1, &ConsoleKindVersion{Name: "ServiceAccount"}),
+		"Topic":          NewKind(2, &ConsoleKindVersion{Name: "Topic"}),
+	}}
+	gateway := &Catalog{Kind: KindCatalog{"ServiceAccount": NewKind(2, &GatewayKindVersion{Name: "ServiceAccount"})}}
+	catalog := console.Merge(gateway)
+
+	testCases := []struct {
+		name       string
+		apiVersion string
+		gateway    bool
+		err        string
+	}{
+		{name: "Topic", apiVersion: "v2"},
+		{name: "console:Topic"},
+		{name: "gateway:Topic", err: "kind gateway:Topic not found"},
+		{name: "ServiceAccount", apiVersion: "gateway/v2", gateway: true},
+		{name: "ServiceAccount", apiVersion: "console/v1"},
+		{name: "ServiceAccount", apiVersion: "v1"},
+		{name: "gateway:ServiceAccount", gateway: true},
+		{name: "ServiceAccount", err: "kind ServiceAccount is defined by both the Console and the Gateway, use console:ServiceAccount or gateway:ServiceAccount"},
+		{name: "Unknown", apiVersion: "v1", err: "kind Unknown not found"},
+	}
+	for _, testCase := range testCases {
+		kind, err := catalog.LookupKind(testCase.name, testCase.apiVersion)
+		if testCase.err != "" {
+			if err == nil || err.Error() != testCase.err {
+				t.Errorf("LookupKind(%s, %s): expected error %s, got %v", testCase.name, testCase.apiVersion, testCase.err, err)
+			}
+			continue
+		}
+		if err != nil || kind.IsGatewayKind() != testCase.gateway {
+			t.Errorf("LookupKind(%s, %s): expected gateway %v, got %v %v", testCase.name, testCase.apiVersion, testCase.gateway, kind, err)
+		}
+	}
+
+	routed := resource.Resource{Kind: "ServiceAccount", Version: "gateway/v2"}
+	if !catalog.IsGatewayResource(routed) || catalog.IsConsoleResource(routed) {
+		t.Error("expected a gateway/v2 ServiceAccount to be routed to the Gateway")
+	}
+}
+
+func TestKeyOrderShouldPreferTheSchemaToTheApplyExample(t *testing.T) {
+	schemaOrder := &resource.KeyOrder{Keys: []string{"spec"}, Fields: map[string]*resource.KeyOrder{"spec": {Keys: []string{"replicationFactor", "partitions"}}}}
+	catalog := &Catalog{Kind: KindCatalog{
+		"Topic": Kind{Versions: map[int]KindVersion{
+			1: &ConsoleKindVersion{Name: "Topic", ApplyExample: "spec:\n  partitions: 1\n  replicationFactor: 1\n"},
+			2: &ConsoleKindVersion{Name: "Topic", ApplyExample: "spec:\n  partitions: 1\n  replicationFactor: 1\n", KeyOrder: schemaOrder},
+		}},
+	}}
+
+	if order := catalog.KeyOrder("Topic", "v2"); order != schemaOrder {
+		t.Errorf("Expected the schema order got %+v", order)
+	}
+	expected := &resource.KeyOrder{Keys: []string{"spec"}, Fields: map[string]*resource.KeyOrder{"spec": {Keys: []string{"partitions", "replicationFactor"}}}}
+	if order := catalog.KeyOrder("Topic", "v1"); !reflect.DeepEqual(order, expected) {
+		t.Errorf("Expected the order of the apply example without schema order got %+v", order)
+	}
+	if order := catalog.KeyOrder("Topic", "v3"); order != schemaOrder {
+		t.Errorf("Expected the order of the latest version for an unknown version got %+v", order)
+	}
+	if order := catalog.KeyOrder("Unknown", "v1"); order != nil {
+		t.Errorf("Expected no order for an unknown kind got %+v", order)
+	}
+}
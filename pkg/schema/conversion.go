@@ -0,0 +1,271 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/conduktor/ctl/internal/orderedjson"
+	"github.com/conduktor/ctl/pkg/resource"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+// Conversion converts the resources of the version From of a kind to the version declaring it, e.g. with
+// the x-cdk-conversions extension:
+//
+//	x-cdk-conversions:
+//	  - from: 2
+//	    rename:
+//	      metadata.cluster: spec.cluster
+//	    remove: [spec.legacyField]
+//	    defaults:
+//	      spec.destination.type: Slack
+//
+// Fields are dotted paths whose keys can hold dots, like sensitive fields. Fields are renamed first,
+// then removed, then the missing defaults are set.
+type Conversion struct {
+	From     int
+	Rename   map[string]string      `json:",omitempty"`
+	Remove   []string               `json:",omitempty"`
+	Defaults map[string]interface{} `json:",omitempty"`
+}
+
+// Deprecation returns why a version of the kind is deprecated, empty if it is not.
+func (kind *Kind) Deprecation(version int) string {
+	kindVersion, ok := kind.Versions[version]
+	if !ok {
+		return ""
+	}
+	if deprecated := kindVersion.GetDeprecated(); deprecated != "" {
+		return deprecated
+	}
+	return defaultDeprecations[kindVersion.GetName()][version]
+}
+
+// Conversions returns the conversions declared by a version of the kind.
+func (kind *Kind) Conversions(version int) []Conversion {
+	kindVersion, ok := kind.Versions[version]
+	if !ok {
+		return nil
+	}
+	return kindVersion.GetConversions()
+}
+
+// CanConvert returns true if the catalog declares a chain of conversions from a version of the kind to another.
+func (kind *Kind) CanConvert(from, to int) bool {
+	return kind.conversionPath(from, to) != nil
+}
+
+// AtVersion returns the kind with only the version of apiVersion, e.g. v1, so that it is listed with the path
+// and parameters of that version instead of the ones of the latest version.
+func (kind *Kind) AtVersion(apiVersion string) (Kind, error) {
+	version, err := ParseAPIVersion(apiVersion)
+	if err != nil {
+		return Kind{}, err
+	}
+	kindVersion, ok := kind.Versions[version]
+	if !ok {
+		return Kind{}, fmt.Errorf("kind %s has no version %s, available versions: %s", kind.GetName(), apiVersion, kind.versionList())
+	}
+	return NewKind(version, kindVersion), nil
+}
+
+func (kind *Kind) versionList() string {
+	versions := make([]int, 0, len(kind.Versions))
+	for version := range kind.Versions {
+		versions = append(versions, version)
+	}
+	sort.Ints(versions)
+	names := make([]string, len(versions))
+	for i, version := range versions {
+		names[i] = fmt.Sprintf("v%d", version)
+	}
+	return strings.Join(names, ", ")
+}
+
+// Convert converts a resource of the kind to a version, chaining the conversions declared by the versions
+// in between, e.g. v1 to v2 then v2 to v3. The order of the fields of the resource is kept.
+func (kind *Kind) Convert(res resource.Resource, version int) (resource.Resource, error) {
+	from, err := ParseAPIVersion(res.Version)
+	if err != nil {
+		return res, err
+	}
+	if _, ok := kind.Versions[version]; !ok {
+		return res, fmt.Errorf("kind %s has no version v%d, available versions: %s", res.Kind, version, kind.versionList())
+	}
+	if from == version {
+		return res, nil
+	}
+	steps := kind.conversionPath(from, version)
+	if steps == nil {
+		return res, fmt.Errorf("no conversion of kind %s from %s to v%d in the catalog", res.Kind, res.Version, version)
+	}
+
+	var document orderedjson.OrderedData
+	err = json.Unmarshal(res.Json, &document)
+	if err != nil {
+		return res, err
+	}
+	object := document.GetMapOrNil()
+	if object == nil {
+		return res, fmt.Errorf("invalid resource %s/%s", res.Kind, res.Name)
+	}
+	for _, step := range steps {
+		err = step.apply(object)
+		if err != nil {
+			return res, fmt.Errorf("failed to convert %s/%s from v%d: %w", res.Kind, res.Name, step.From, err)
+		}
+	}
+	object.Set("apiVersion", orderedjson.FromValue(withVersion(res.Version, version)))
+	data, err := json.Marshal(document)
+	if err != nil {
+		return res, err
+	}
+	var converted resource.Resource
+	err = json.Unmarshal(data, &converted)
+	converted.SecretFields = res.SecretFields
+	return converted, err
+}
+
+// conversionPath returns the shortest chain of conversions from a version to another, nil if there is none.
+func (kind *Kind) conversionPath(from, to int) []Conversion {
+	type step struct {
+		version int
+		path    []Conversion
+	}
+	visited := map[int]bool{from: true}
+	queue := []step{{version: from}}
+	versions := make([]int, 0, len(kind.Versions))
+	for version := range kind.Versions {
+		versions = append(versions, version)
+	}
+	sort.Ints(versions)
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, version := range versions {
+			if visited[version] {
+				continue
+			}
+			for _, conversion := range kind.Conversions(version) {
+				if conversion.From != current.version {
+					continue
+				}
+				path := append(append([]Conversion{}, current.path...), conversion)
+				if version == to {
+					return path
+				}
+				visited[version] = true
+				queue = append(queue, step{version: version, path: path})
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// withVersion replaces the version of an apiVersion, keeping its group, e.g. gateway/v1 to gateway/v2.
+func withVersion(apiVersion string, version int) string {
+	location := apiVersionNumber.FindStringIndex(apiVersion)
+	if location == nil {
+		return fmt.Sprintf("v%d", version)
+	}
+	return fmt.Sprintf("%sv%d%s", apiVersion[:location[0]], version, apiVersion[location[1]:])
+}
+
+type orderedObject = *orderedmap.OrderedMap[string, orderedjson.OrderedData]
+
+func (c Conversion) apply(object orderedObject) error {
+	renames := make([]string, 0, len(c.Rename))
+	for from := range c.Rename {
+		renames = append(renames, from)
+	}
+	sort.Strings(renames)
+	for _, from := range renames {
+		parent, key, found := lookupField(object, from)
+		if !found {
+			continue
+		}
+		value, _ := parent.Delete(key)
+		err := setField(object, c.Rename[from], value)
+		if err != nil {
+			return err
+		}
+	}
+	for _, field := range c.Remove {
+		if parent, key, found := lookupField(object, field); found {
+			parent.Delete(key)
+		}
+	}
+	defaults := make([]string, 0, len(c.Defaults))
+	for field := range c.Defaults {
+		defaults = append(defaults, field)
+	}
+	sort.Strings(defaults)
+	for _, field := range defaults {
+		if _, _, found := lookupField(object, field); found {
+			continue
+		}
+		err := setField(object, field, orderedjson.FromValue(c.Defaults[field]))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lookupField returns the object holding the field at a dotted path and its key.
+func lookupField(object orderedObject, field string) (orderedObject, string, bool) {
+	segments := strings.Split(field, ".")
+	for {
+		n := len(segments)
+		for ; n > 0; n-- {
+			if _, ok := object.Get(strings.Join(segments[:n], ".")); ok {
+				break
+			}
+		}
+		if n == 0 {
+			return nil, "", false
+		}
+		key := strings.Join(segments[:n], ".")
+		if n == len(segments) {
+			return object, key, true
+		}
+		value, _ := object.Get(key)
+		object = value.GetMapOrNil()
+		if object == nil {
+			return nil, "", false
+		}
+		segments = segments[n:]
+	}
+}
+
+// setField sets the field at a dotted path, creating the missing objects, one per segment.
+func setField(object orderedObject, field string, value orderedjson.OrderedData) error {
+	segments := strings.Split(field, ".")
+	for len(segments) > 1 {
+		existing, ok := object.Get(segments[0])
+		if !ok {
+			break
+		}
+		object = existing.GetMapOrNil()
+		if object == nil {
+			return fmt.Errorf("cannot set %s, %s is not an object", field, segments[0])
+		}
+		segments = segments[1:]
+	}
+	for i := len(segments) - 1; i > 0; i-- {
+		var leaf interface{}
+		raw, err := json.Marshal(value)
+		if err == nil {
+			err = json.Unmarshal(raw, &leaf)
+		}
+		if err != nil {
+			return err
+		}
+		value = orderedjson.FromValue(map[string]interface{}{segments[i]: leaf})
+	}
+	object.Set(segments[0], value)
+	return nil
+}
@@ -0,0 +1,90 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/conduktor/ctl/pkg/resource"
+)
+
+func mustResource(t *testing.T, data string) resource.Resource {
+	t.Helper()
+	resources, err := resource.FromYamlByte([]byte(data), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resources[0]
+}
+
+func TestDefaultAlertDeprecation(t *testing.T) {
+	alert := ConsoleDefaultCatalog().Kind["Alert"]
+	res := mustResource(t, `
+apiVersion: v2
+kind: Alert
+metadata:
+  name: failed-tasks
+  cluster: prod
+spec:
+  threshold: 1
+`)
+	if !strings.Contains(alert.Deprecation(2), "spec.destination") || alert.Deprecation(3) != "" {
+		t.Errorf("Expected only v2 of Alert to be deprecated, telling what v3 requires, got %s", alert.Deprecation(2))
+	}
+	// an owner and a destination cannot be derived from a v2 Alert
+	if alert.CanConvert(2, 3) {
+		t.Error("Expected no default conversion of Alert")
+	}
+	_, err := alert.Convert(res, 3)
+	if err == nil || !strings.Contains(err.Error(), "no conversion of kind Alert from v2 to v3") {
+		t.Errorf("Expected missing conversion error got %v", err)
+	}
+}
+
+func TestConvertChained(t *testing.T) {
+	kind := Kind{Versions: map[int]KindVersion{
+		1: &GatewayKindVersion{Name: "Plugin"},
+		2: &GatewayKindVersion{Name: "Plugin", Conversions: []Conversion{{
+			From:   1,
+			Rename: map[string]string{"spec.config.retention.ms": "spec.retentionMs"},
+		}}},
+		3: &GatewayKindVersion{Name: "Plugin", Conversions: []Conversion{{
+			From:     2,
+			Remove:   []string{"spec.legacy"},
+			Defaults: map[string]interface{}{"spec.priority": 100, "spec.retentionMs": 0, "spec.target.type": "topic"},
+		}}},
+	}}
+	res := mustResource(t, `
+apiVersion: gateway/v1
+kind: Plugin
+metadata:
+  name: retention
+spec:
+  legacy: true
+  config:
+    retention.ms: 60000
+`)
+	converted, err := kind.Convert(res, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"apiVersion":"gateway/v3","kind":"Plugin","metadata":{"name":"retention"},"spec":{"config":{},"retentionMs":60000,"priority":100,"target":{"type":"topic"}}}`
+	if string(converted.Json) != expected {
+		t.Errorf("Expected %s got %s", expected, converted.Json)
+	}
+}
+
+func TestAtVersion(t *testing.T) {
+	alert := ConsoleDefaultCatalog().Kind["Alert"]
+	v2, err := alert.AtVersion("v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := v2.ListPath([]string{"prod"}, nil).Path
+	if path != "/public/monitoring/v2/cluster/prod/alert" {
+		t.Errorf("Expected the list path of v2 got %s", path)
+	}
+	_, err = alert.AtVersion("v9")
+	if err == nil || !strings.Contains(err.Error(), "available versions: v2, v3") {
+		t.Errorf("Expected unknown version error got %v", err)
+	}
+}
@@ -74,6 +74,13 @@ func (kind *Kind) IsGatewayKind() bool {
 	return isGatewayKind
 }
 
+func (kind Kind) backendType() BackendType {
+	if kind.IsGatewayKind() {
+		return GATEWAY
+	}
+	return CONSOLE
+}
+
 // Determines if the kind is a Gateway Kind that need to be identified by name and vCluster.
 func (kind *Kind) IsKindIdentifiedByNameAndVCluster() bool {
 	return strings.Contains(strings.ToLower(kind.GetName()), "aliastopic") ||
@@ -130,7 +137,10 @@ func (kind *Kind) GetName() string {
 }
 
 func (kind *Kind) ListPath(parentValues []string, parentQueryValues []string) QueryInfo {
-	kindVersion := kind.GetLatestKindVersion()
+	return listPath(kind.GetLatestKindVersion(), parentValues, parentQueryValues)
+}
+
+func listPath(kindVersion KindVersion, parentValues []string, parentQueryValues []string) QueryInfo {
 	if len(parentValues) != len(kindVersion.GetParentPathParam()) {
 		panic(fmt.Sprintf("For kind %s expected %d parent apiVersion values, got %d", kindVersion.GetName(), len(kindVersion.GetParentPathParam()), len(parentValues)))
 	}
@@ -189,7 +199,7 @@ func (kind *Kind) ApplyPath(resource *resource.Resource) (QueryInfo, error) {
 			parentQueryValues = append(parentQueryValues, "")
 		}
 	}
-	return kind.ListPath(parentPathValues, parentQueryValues), nil
+	return listPath(kindVersion, parentPathValues, parentQueryValues), nil
 }
 
 func (kind *Kind) DeletePath(resource *resource.Resource) (string, map[string]string, error) {
@@ -227,25 +237,29 @@ func (kind *Kind) DeletePath(resource *resource.Resource) (string, map[string]st
 	return applyPath.Path + "/" + resource.Name, queryParam, nil
 }
 
-func extractVersionFromAPIVersion(apiVersion string) int {
-	// we extract the number after v in a apiVersion
-	// e.g. v1 1
-	// e.g. v42-> 42
-
-	re := regexp.MustCompile(`v(\d+)`)
-	matches := re.FindStringSubmatch(apiVersion)
+// apiVersionNumber matches the version of an apiVersion, e.g. v2 in gateway/v2.
+var apiVersionNumber = regexp.MustCompile(`v(\d+)`)
 
-	if len(matches) < 2 {
-		fmt.Fprintf(os.Stderr, "Invalid api version format \"%s\", could not extract version\n", apiVersion)
+func extractVersionFromAPIVersion(apiVersion string) int {
+	version, err := ParseAPIVersion(apiVersion)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
 		os.Exit(1)
 	}
+	return version
+}
 
+// ParseAPIVersion returns the number after v in an apiVersion, e.g. 1 for v1 and 2 for gateway/v2.
+func ParseAPIVersion(apiVersion string) (int, error) {
+	matches := apiVersionNumber.FindStringSubmatch(apiVersion)
+	if len(matches) < 2 {
+		return 0, fmt.Errorf("Invalid api version format \"%s\", could not extract version", apiVersion)
+	}
 	version, err := strconv.Atoi(matches[1])
 	if err != nil {
-		panic(fmt.Sprintf("Invalid version number in apiVersion: %s", matches[1]))
+		return 0, fmt.Errorf("Invalid version number in apiVersion: %s", matches[1])
 	}
-
-	return version
+	return version, nil
 }
 
 //go:embed default_schema/console.json
@@ -1,5 +1,7 @@
 package schema
 
+import "github.com/conduktor/ctl/pkg/resource"
+
 type KindVersion interface {
 	GetListPath() string
 	GetName() string
@@ -8,6 +10,35 @@ type KindVersion interface {
 	GetOrder() int
 	GetListQueryParameter() map[string]FlagParameterOption
 	GetApplyExample() string
+	GetReadyCondition() string
+	GetArrayOrdering() map[string]string
+	GetSensitiveFields() []string
+	GetDeprecated() string
+	GetConversions() []Conversion
+	GetKeyOrder() *resource.KeyOrder
+}
+
+// defaultSensitiveFields are the sensitive fields of the kinds whose catalog does not declare them
+// with the x-cdk-sensitive-fields extension, e.g. when using an older Console or the offline defaults.
+var defaultSensitiveFields = map[string][]string{
+	"KafkaCluster":        {"spec.properties.sasl.jaas.config", "spec.schemaRegistry.password", "spec.schemaRegistry.security.password", "spec.credentials"},
+	"KafkaConnectCluster": {"spec.security.password", "spec.credentials"},
+	"KsqlDBCluster":       {"spec.security.password", "spec.credentials"},
+	"VirtualCluster":      {"spec.clientProperties.sasl.jaas.config"},
+}
+
+func sensitiveFieldsOrDefault(name string, sensitiveFields []string) []string {
+	if sensitiveFields != nil {
+		return sensitiveFields
+	}
+	return defaultSensitiveFields[name]
+}
+
+// defaultDeprecations are the deprecations of the versions of the kinds whose catalog does not declare them
+// with the x-cdk-deprecated extension. There is no default conversion: an Alert v3 needs an owner and a destination
+// that cannot be derived from a v2 one.
+var defaultDeprecations = map[string]map[int]string{
+	"Alert": {2: "use v3, moving metadata.cluster to spec.cluster and adding an owner (metadata.appInstance, metadata.group or metadata.user) and a spec.destination"},
 }
 
 type ConsoleKindVersion struct {
@@ -18,6 +49,12 @@ type ConsoleKindVersion struct {
 	ListQueryParameter map[string]FlagParameterOption
 	ApplyExample       string
 	Order              int
+	ReadyCondition     string             `json:",omitempty"`
+	ArrayOrdering      map[string]string  `json:",omitempty"`
+	SensitiveFields    []string           `json:",omitempty"`
+	Deprecated         string             `json:",omitempty"`
+	Conversions        []Conversion       `json:",omitempty"`
+	KeyOrder           *resource.KeyOrder `json:",omitempty"`
 }
 
 func (c *ConsoleKindVersion) GetListPath() string {
@@ -48,6 +85,19 @@ func (c *ConsoleKindVersion) GetListQueryParameter() map[string]FlagParameterOpt
 	return c.ListQueryParameter
 }
 
+func (c *ConsoleKindVersion) GetReadyCondition() string {
+	return c.ReadyCondition
+}
+
+func (c *ConsoleKindVersion) GetArrayOrdering() map[string]string {
+	return c.ArrayOrdering
+}
+
+// GetSensitiveFields returns the dotted paths of the fields holding credentials, masked in outputs and logs.
+func (c *ConsoleKindVersion) GetSensitiveFields() []string {
+	return sensitiveFieldsOrDefault(c.Name, c.SensitiveFields)
+}
+
 type GatewayKindVersion struct {
 	ListPath           string
 	Name               string
@@ -57,6 +107,12 @@ type GatewayKindVersion struct {
 	GetAvailable       bool
 	ApplyExample       string
 	Order              int
+	ReadyCondition     string             `json:",omitempty"`
+	ArrayOrdering      map[string]string  `json:",omitempty"`
+	SensitiveFields    []string           `json:",omitempty"`
+	Deprecated         string             `json:",omitempty"`
+	Conversions        []Conversion       `json:",omitempty"`
+	KeyOrder           *resource.KeyOrder `json:",omitempty"`
 }
 
 func (g *GatewayKindVersion) GetListPath() string {
@@ -86,3 +142,50 @@ func (g *GatewayKindVersion) GetOrder() int {
 func (g *GatewayKindVersion) GetListQueryParameter() map[string]FlagParameterOption {
 	return g.ListQueryParameter
 }
+
+func (g *GatewayKindVersion) GetReadyCondition() string {
+	return g.ReadyCondition
+}
+
+func (g *GatewayKindVersion) GetArrayOrdering() map[string]string {
+	return g.ArrayOrdering
+}
+
+// GetSensitiveFields returns the dotted paths of the fields holding credentials, masked in outputs and logs.
+func (g *GatewayKindVersion) GetSensitiveFields() []string {
+	return sensitiveFieldsOrDefault(g.Name, g.SensitiveFields)
+}
+
+// GetDeprecated returns why the version is deprecated and what replaces it, empty if it is not deprecated.
+// See Kind.Deprecation for the default deprecations.
+func (c *ConsoleKindVersion) GetDeprecated() string {
+	return c.Deprecated
+}
+
+// GetConversions returns how to convert the resources of other versions to this one.
+// See Kind.Conversions for the default conversions.
+func (c *ConsoleKindVersion) GetConversions() []Conversion {
+	return c.Conversions
+}
+
+// GetDeprecated returns why the version is deprecated and what replaces it, empty if it is not deprecated.
+// See Kind.Deprecation for the default deprecations.
+func (g *GatewayKindVersion) GetDeprecated() string {
+	return g.Deprecated
+}
+
+// GetConversions returns how to convert the resources of other versions to this one.
+// See Kind.Conversions for the default conversions.
+func (g *GatewayKindVersion) GetConversions() []Conversion {
+	return g.Conversions
+}
+
+// GetKeyOrder returns the order of the properties of the schema of the resources, nil if the catalog has not it.
+func (c *ConsoleKindVersion) GetKeyOrder() *resource.KeyOrder {
+	return c.KeyOrder
+}
+
+// GetKeyOrder returns the order of the properties of the schema of the resources, nil if the catalog has not it.
+func (g *GatewayKindVersion) GetKeyOrder() *resource.KeyOrder {
+	return g.KeyOrder
+}
@@ -10,7 +10,9 @@ import (
 	"github.com/go-resty/resty/v2"
 
 	"github.com/conduktor/ctl/internal/utils"
+	"github.com/conduktor/ctl/pkg/resource"
 	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
 	v3high "github.com/pb33f/libopenapi/datamodel/high/v3"
 	"gopkg.in/yaml.v3"
 )
@@ -34,6 +36,14 @@ func NewOpenAPIParser(schema []byte) (*OpenAPIParser, error) {
 	}, nil
 }
 
+// Version returns the version of the API, i.e. of the server publishing it, empty if not set.
+func (s *OpenAPIParser) Version() string {
+	if s.doc.Model.Info == nil {
+		return ""
+	}
+	return s.doc.Model.Info.Version
+}
+
 func getKinds[T KindVersion](s *OpenAPIParser, strict bool, buildKindVersion func(s *OpenAPIParser, path, kind string, order int, put *v3high.Operation, get *v3high.Operation, strict bool) (T, error)) (map[string]Kind, error) {
 	result := make(map[string]Kind, 0)
 	for path := s.doc.Model.Paths.PathItems.First(); path != nil; path = path.Next() {
@@ -231,7 +241,41 @@ func buildConsoleKindVersion(s *OpenAPIParser, path, kind string, order int, put
 			}
 		}
 	}
+	readyCondition, present := put.Extensions.Get("x-cdk-ready-condition")
+	if present {
+		newKind.ReadyCondition = readyCondition.Value
+	}
+	arrayOrdering, present := put.Extensions.Get("x-cdk-array-ordering")
+	if present {
+		err := arrayOrdering.Decode(&newKind.ArrayOrdering)
+		if err != nil && strict {
+			return nil, fmt.Errorf("invalid x-cdk-array-ordering for kind %s: %s", kind, err)
+		}
+	}
+	sensitiveFields, present := put.Extensions.Get("x-cdk-sensitive-fields")
+	if present {
+		err := sensitiveFields.Decode(&newKind.SensitiveFields)
+		if err != nil && strict {
+			return nil, fmt.Errorf("invalid x-cdk-sensitive-fields for kind %s: %s", kind, err)
+		}
+	}
+	deprecated, present := put.Extensions.Get("x-cdk-deprecated")
+	if present {
+		newKind.Deprecated = deprecated.Value
+	} else if put.Deprecated != nil && *put.Deprecated {
+		newKind.Deprecated = "deprecated"
+	}
+	conversions, present := put.Extensions.Get("x-cdk-conversions")
+	if present {
+		err := conversions.Decode(&newKind.Conversions)
+		if err != nil && strict {
+			return nil, fmt.Errorf("invalid x-cdk-conversions for kind %s: %s", kind, err)
+		}
+	}
 	schemaJSON, ok := put.RequestBody.Content.Get("application/json")
+	if ok {
+		newKind.KeyOrder = keyOrderOf(schemaJSON.Schema, nil)
+	}
 	if ok && schemaJSON.Example != nil {
 		// Example is a *yaml.Node, we need to decode it first then marshal
 		var example interface{}
@@ -256,6 +300,74 @@ func buildConsoleKindVersion(s *OpenAPIParser, path, kind string, order int, put
 	return newKind, nil
 }
 
+// keyOrderOf returns the order of the properties of an object schema, with the ones of its nested objects and of the
+// items of its arrays, nil if it declares none. The properties of allOf, oneOf and anyOf follow the ones of the schema.
+// references are the schemas being visited, a schema referencing itself is not ordered again.
+func keyOrderOf(proxy *base.SchemaProxy, references []string) *resource.KeyOrder {
+	if proxy == nil {
+		return nil
+	}
+	if proxy.IsReference() {
+		if slices.Contains(references, proxy.GetReference()) {
+			return nil
+		}
+		references = append(references, proxy.GetReference())
+	}
+	schema := proxy.Schema()
+	if schema == nil {
+		return nil
+	}
+	order := &resource.KeyOrder{}
+	if schema.Properties != nil {
+		for property := schema.Properties.First(); property != nil; property = property.Next() {
+			addKey(order, property.Key(), keyOrderOf(property.Value(), references))
+		}
+	}
+	for _, composed := range slices.Concat(schema.AllOf, schema.OneOf, schema.AnyOf) {
+		mergeKeyOrder(order, keyOrderOf(composed, references))
+	}
+	if schema.Items != nil && schema.Items.IsA() && order.Items == nil {
+		order.Items = keyOrderOf(schema.Items.A, references)
+	}
+	if len(order.Keys) == 0 && order.Items == nil {
+		return nil
+	}
+	return order
+}
+
+// mergeKeyOrder adds the keys of other missing from order after its keys.
+func mergeKeyOrder(order, other *resource.KeyOrder) {
+	if other == nil {
+		return
+	}
+	for _, key := range other.Keys {
+		addKey(order, key, other.Fields[key])
+	}
+	if order.Items == nil {
+		order.Items = other.Items
+	} else {
+		mergeKeyOrder(order.Items, other.Items)
+	}
+}
+
+// addKey adds key to order if missing, merging the order of its field with the one already known.
+func addKey(order *resource.KeyOrder, key string, field *resource.KeyOrder) {
+	if !slices.Contains(order.Keys, key) {
+		order.Keys = append(order.Keys, key)
+	}
+	if field == nil {
+		return
+	}
+	if existing, exists := order.Fields[key]; exists {
+		mergeKeyOrder(existing, field)
+		return
+	}
+	if order.Fields == nil {
+		order.Fields = make(map[string]*resource.KeyOrder)
+	}
+	order.Fields[key] = field
+}
+
 // two logics: uniformize flag name and kebab case.
 func computeFlagName(name string) string {
 	kebab := utils.CamelToKebab(name)
@@ -284,6 +396,12 @@ func buildGatewayKindVersion(s *OpenAPIParser, path, kind string, order int, put
 		ApplyExample:       consoleKind.ApplyExample,
 		GetAvailable:       getAvailable,
 		Order:              consoleKind.Order,
+		ReadyCondition:     consoleKind.ReadyCondition,
+		ArrayOrdering:      consoleKind.ArrayOrdering,
+		SensitiveFields:    consoleKind.SensitiveFields,
+		Deprecated:         consoleKind.Deprecated,
+		Conversions:        consoleKind.Conversions,
+		KeyOrder:           consoleKind.KeyOrder,
 	}, nil
 }
 
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/conduktor/ctl/pkg/resource"
 	"github.com/davecgh/go-spew/spew"
 )
 
@@ -35,6 +36,13 @@ func TestGetKindWithYamlFromOldConsolePlusWithoutOrder(t *testing.T) {
 						ParentPathParam:    make([]string, 0),
 						ListQueryParameter: map[string]FlagParameterOption{},
 						Order:              DefaultPriority,
+						KeyOrder: &resource.KeyOrder{
+							Keys: []string{"metadata", "spec", "apiVersion", "kind"},
+							Fields: map[string]*resource.KeyOrder{
+								"metadata": {Keys: []string{"name"}},
+								"spec":     {Keys: []string{"title", "description", "owner"}},
+							},
+						},
 					},
 				},
 			},
@@ -52,6 +60,18 @@ func TestGetKindWithYamlFromOldConsolePlusWithoutOrder(t *testing.T) {
 							},
 						},
 						Order: DefaultPriority,
+						KeyOrder: &resource.KeyOrder{
+							Keys: []string{"metadata", "spec", "apiVersion", "kind"},
+							Fields: map[string]*resource.KeyOrder{
+								"metadata": {Keys: []string{"name", "application"}},
+								"spec": {
+									Keys: []string{"cluster", "topicPolicyRef", "resources", "serviceAccount"},
+									Fields: map[string]*resource.KeyOrder{
+										"resources": {Items: &resource.KeyOrder{Keys: []string{"type", "name", "patternType"}}},
+									},
+								},
+							},
+						},
 					},
 				},
 			},
@@ -79,6 +99,18 @@ func TestGetKindWithYamlFromOldConsolePlusWithoutOrder(t *testing.T) {
 							},
 						},
 						Order: DefaultPriority,
+						KeyOrder: &resource.KeyOrder{
+							Keys: []string{"metadata", "spec", "apiVersion", "kind"},
+							Fields: map[string]*resource.KeyOrder{
+								"metadata": {Keys: []string{"application", "appInstance", "name"}},
+								"spec": {
+									Keys: []string{"resource", "permission", "grantedTo"},
+									Fields: map[string]*resource.KeyOrder{
+										"resource": {Keys: []string{"type", "name", "patternType"}},
+									},
+								},
+							},
+						},
 					},
 				},
 			},
@@ -96,6 +128,13 @@ func TestGetKindWithYamlFromOldConsolePlusWithoutOrder(t *testing.T) {
 							},
 						},
 						Order: DefaultPriority,
+						KeyOrder: &resource.KeyOrder{
+							Keys: []string{"metadata", "spec", "apiVersion", "kind"},
+							Fields: map[string]*resource.KeyOrder{
+								"metadata": {Keys: []string{"name"}},
+								"spec":     {Keys: []string{"policies"}},
+							},
+						},
 					},
 				},
 			},
@@ -107,6 +146,13 @@ func TestGetKindWithYamlFromOldConsolePlusWithoutOrder(t *testing.T) {
 						ParentPathParam:    []string{"cluster"},
 						ListQueryParameter: map[string]FlagParameterOption{},
 						Order:              DefaultPriority,
+						KeyOrder: &resource.KeyOrder{
+							Keys: []string{"metadata", "spec", "apiVersion", "kind"},
+							Fields: map[string]*resource.KeyOrder{
+								"metadata": {Keys: []string{"name", "cluster"}},
+								"spec":     {Keys: []string{"partitions", "replicationFactor", "configs"}},
+							},
+						},
 					},
 				},
 			},
@@ -143,6 +189,13 @@ func TestGetKindWithYamlFromConsolePlus(t *testing.T) {
 						ParentPathParam:    []string{},
 						ListQueryParameter: map[string]FlagParameterOption{},
 						Order:              6,
+						KeyOrder: &resource.KeyOrder{
+							Keys: []string{"apiVersion", "kind", "metadata", "spec"},
+							Fields: map[string]*resource.KeyOrder{
+								"metadata": {Keys: []string{"name"}},
+								"spec":     {Keys: []string{"title", "description", "owner"}},
+							},
+						},
 					},
 				},
 			},
@@ -160,6 +213,18 @@ func TestGetKindWithYamlFromConsolePlus(t *testing.T) {
 							},
 						},
 						Order: 7,
+						KeyOrder: &resource.KeyOrder{
+							Keys: []string{"apiVersion", "kind", "metadata", "spec"},
+							Fields: map[string]*resource.KeyOrder{
+								"metadata": {Keys: []string{"name", "application"}},
+								"spec": {
+									Keys: []string{"cluster", "topicPolicyRef", "resources", "serviceAccount"},
+									Fields: map[string]*resource.KeyOrder{
+										"resources": {Items: &resource.KeyOrder{Keys: []string{"type", "name", "patternType"}}},
+									},
+								},
+							},
+						},
 					},
 				},
 			},
@@ -187,6 +252,18 @@ func TestGetKindWithYamlFromConsolePlus(t *testing.T) {
 							},
 						},
 						Order: 8,
+						KeyOrder: &resource.KeyOrder{
+							Keys: []string{"apiVersion", "kind", "metadata", "spec"},
+							Fields: map[string]*resource.KeyOrder{
+								"metadata": {Keys: []string{"application", "appInstance", "name"}},
+								"spec": {
+									Keys: []string{"resource", "permission", "grantedTo"},
+									Fields: map[string]*resource.KeyOrder{
+										"resource": {Keys: []string{"type", "name", "patternType"}},
+									},
+								},
+							},
+						},
 					},
 				},
 			},
@@ -198,6 +275,18 @@ func TestGetKindWithYamlFromConsolePlus(t *testing.T) {
 						ParentPathParam:    []string{},
 						ListQueryParameter: map[string]FlagParameterOption{},
 						Order:              9,
+						KeyOrder: &resource.KeyOrder{
+							Keys: []string{"apiVersion", "kind", "metadata", "spec"},
+							Fields: map[string]*resource.KeyOrder{
+								"metadata": {Keys: []string{"application", "name"}},
+								"spec": {
+									Keys: []string{"displayName", "description", "permissions", "members"},
+									Fields: map[string]*resource.KeyOrder{
+										"permissions": {Items: &resource.KeyOrder{Keys: []string{"appInstance", "patternType", "name", "permissions", "resourceType"}}},
+									},
+								},
+							},
+						},
 					},
 				},
 			},
@@ -215,6 +304,13 @@ func TestGetKindWithYamlFromConsolePlus(t *testing.T) {
 							},
 						},
 						Order: 5,
+						KeyOrder: &resource.KeyOrder{
+							Keys: []string{"apiVersion", "kind", "metadata", "spec"},
+							Fields: map[string]*resource.KeyOrder{
+								"metadata": {Keys: []string{"name"}},
+								"spec":     {Keys: []string{"policies"}},
+							},
+						},
 					},
 				},
 			},
@@ -226,6 +322,13 @@ func TestGetKindWithYamlFromConsolePlus(t *testing.T) {
 						ParentPathParam:    []string{"cluster"},
 						ListQueryParameter: map[string]FlagParameterOption{},
 						Order:              3,
+						KeyOrder: &resource.KeyOrder{
+							Keys: []string{"apiVersion", "kind", "metadata", "spec"},
+							Fields: map[string]*resource.KeyOrder{
+								"metadata": {Keys: []string{"name", "cluster", "labels"}},
+								"spec":     {Keys: []string{"partitions", "replicationFactor", "configs"}},
+							},
+						},
 					},
 				},
 			},
@@ -237,6 +340,18 @@ func TestGetKindWithYamlFromConsolePlus(t *testing.T) {
 						ParentPathParam:    []string{"cluster"},
 						ListQueryParameter: map[string]FlagParameterOption{},
 						Order:              4,
+						KeyOrder: &resource.KeyOrder{
+							Keys: []string{"apiVersion", "kind", "metadata", "spec"},
+							Fields: map[string]*resource.KeyOrder{
+								"metadata": {Keys: []string{"name", "cluster", "labels"}},
+								"spec": {
+									Keys: []string{"format", "compatibility", "schema", "id", "version", "references"},
+									Fields: map[string]*resource.KeyOrder{
+										"references": {Items: &resource.KeyOrder{Keys: []string{"name", "subject", "version"}}},
+									},
+								},
+							},
+						},
 					},
 				},
 			},
@@ -248,6 +363,18 @@ func TestGetKindWithYamlFromConsolePlus(t *testing.T) {
 						ParentPathParam:    []string{},
 						ListQueryParameter: map[string]FlagParameterOption{},
 						Order:              0,
+						KeyOrder: &resource.KeyOrder{
+							Keys: []string{"apiVersion", "kind", "metadata", "spec"},
+							Fields: map[string]*resource.KeyOrder{
+								"metadata": {Keys: []string{"name"}},
+								"spec": {
+									Keys: []string{"firstName", "lastName", "permissions"},
+									Fields: map[string]*resource.KeyOrder{
+										"permissions": {Items: &resource.KeyOrder{Keys: []string{"name", "permissions", "resourceType", "cluster", "patternType", "kafkaConnect", "ksqlDB"}}},
+									},
+								},
+							},
+						},
 					},
 				},
 			},
@@ -259,6 +386,18 @@ func TestGetKindWithYamlFromConsolePlus(t *testing.T) {
 						ParentPathParam:    []string{},
 						ListQueryParameter: map[string]FlagParameterOption{},
 						Order:              1,
+						KeyOrder: &resource.KeyOrder{
+							Keys: []string{"apiVersion", "kind", "metadata", "spec"},
+							Fields: map[string]*resource.KeyOrder{
+								"metadata": {Keys: []string{"name"}},
+								"spec": {
+									Keys: []string{"displayName", "description", "externalGroups", "members", "membersFromExternalGroups", "permissions"},
+									Fields: map[string]*resource.KeyOrder{
+										"permissions": {Items: &resource.KeyOrder{Keys: []string{"name", "permissions", "resourceType", "cluster", "patternType", "kafkaConnect", "ksqlDB"}}},
+									},
+								},
+							},
+						},
 					},
 				},
 			},
@@ -270,6 +409,25 @@ func TestGetKindWithYamlFromConsolePlus(t *testing.T) {
 						ParentPathParam:    []string{},
 						ListQueryParameter: map[string]FlagParameterOption{},
 						Order:              2,
+						KeyOrder: &resource.KeyOrder{
+							Keys: []string{"apiVersion", "kind", "metadata", "spec"},
+							Fields: map[string]*resource.KeyOrder{
+								"metadata": {Keys: []string{"name", "labels"}},
+								"spec": {
+									Keys: []string{"displayName", "bootstrapServers", "properties", "color", "icon", "schemaRegistry", "amazonSecurity", "ignoreUntrustedCertificate", "accessCert", "accessKey", "kafkaFlavor"},
+									Fields: map[string]*resource.KeyOrder{
+										"schemaRegistry": {
+											Keys: []string{"url", "security", "properties", "ignoreUntrustedCertificate", "registryName", "region"},
+											Fields: map[string]*resource.KeyOrder{
+												"security": {Keys: []string{"username", "password", "type", "token", "key", "certificateChain", "accessKeyId", "secretKey", "profile", "role", "trustAnchorArn", "profileArn", "roleArn", "certificate", "privateKey"}},
+											},
+										},
+										"amazonSecurity": {Keys: []string{"accessKeyId", "secretKey", "type", "profile", "role", "trustAnchorArn", "profileArn", "roleArn", "certificate", "privateKey"}},
+										"kafkaFlavor":    {Keys: []string{"apiToken", "project", "serviceName", "type", "key", "secret", "confluentEnvironmentId", "confluentClusterId", "url", "user", "password", "virtualCluster"}},
+									},
+								},
+							},
+						},
 					},
 				},
 			},
@@ -306,6 +464,13 @@ func TestGetKindWithMultipleVersion(t *testing.T) {
 						ParentPathParam:    []string{"cluster"},
 						ListQueryParameter: map[string]FlagParameterOption{},
 						Order:              DefaultPriority,
+						KeyOrder: &resource.KeyOrder{
+							Keys: []string{"metadata", "spec", "apiVersion", "kind"},
+							Fields: map[string]*resource.KeyOrder{
+								"metadata": {Keys: []string{"name", "cluster"}},
+								"spec":     {Keys: []string{"partitions", "replicationFactor", "configs"}},
+							},
+						},
 					},
 					2: &ConsoleKindVersion{
 						Name:               "Topic",
@@ -313,6 +478,13 @@ func TestGetKindWithMultipleVersion(t *testing.T) {
 						ParentPathParam:    []string{"cluster", "sa"},
 						ListQueryParameter: map[string]FlagParameterOption{},
 						Order:              42,
+						KeyOrder: &resource.KeyOrder{
+							Keys: []string{"metadata", "spec", "apiVersion", "kind"},
+							Fields: map[string]*resource.KeyOrder{
+								"metadata": {Keys: []string{"name", "cluster", "sa"}},
+								"spec":     {Keys: []string{"partitions", "replicationFactor", "configs"}},
+							},
+						},
 					},
 				},
 			},
@@ -460,3 +632,97 @@ func TestGetExecutes(t *testing.T) {
 		}
 	})
 }
+
+func TestGetKindWithCatalogExtensions(t *testing.T) {
+	schemaContent, err := os.ReadFile("testdata/catalog_extensions.yaml")
+	if err != nil {
+		t.Fatalf("failed reading file: %s", err)
+	}
+
+	schema, err := NewOpenAPIParser(schemaContent)
+	if err != nil {
+		t.Fatalf("failed creating new schema: %s", err)
+	}
+
+	kinds, err := schema.GetConsoleKinds(false)
+	if err != nil {
+		t.Fatalf("failed getting kinds: %s", err)
+	}
+
+	connector := kinds["Connector"]
+	kindVersion := connector.GetLatestKindVersion()
+	if kindVersion.GetReadyCondition() != "jsonpath=.metadata.status=Ready" {
+		t.Errorf("unexpected ready condition: %s", kindVersion.GetReadyCondition())
+	}
+	expectedOrdering := map[string]string{
+		"spec.transforms": "ordered",
+		"spec.topics":     "set",
+		"*":               "key:name",
+	}
+	if !reflect.DeepEqual(kindVersion.GetArrayOrdering(), expectedOrdering) {
+		t.Errorf("unexpected array ordering: %v", kindVersion.GetArrayOrdering())
+	}
+	if !reflect.DeepEqual(kindVersion.GetSensitiveFields(), []string{"spec.config.password"}) {
+		t.Errorf("unexpected sensitive fields: %v", kindVersion.GetSensitiveFields())
+	}
+	if connector.Deprecation(2) != "use v3" {
+		t.Errorf("unexpected deprecation: %s", connector.Deprecation(2))
+	}
+	expectedConversions := []Conversion{{
+		From:     1,
+		Rename:   map[string]string{"spec.class": "spec.connectorClass"},
+		Remove:   []string{"spec.legacy"},
+		Defaults: map[string]interface{}{"spec.tasks.max": 1},
+	}}
+	if !reflect.DeepEqual(connector.Conversions(2), expectedConversions) {
+		t.Errorf("unexpected conversions: %v", connector.Conversions(2))
+	}
+}
+
+func TestDefaultSensitiveFields(t *testing.T) {
+	kafkaCluster := ConsoleDefaultCatalog().Kind["KafkaCluster"]
+	fields := kafkaCluster.GetLatestKindVersion().GetSensitiveFields()
+	if len(fields) == 0 || fields[0] != "spec.properties.sasl.jaas.config" {
+		t.Errorf("unexpected default sensitive fields: %v", fields)
+	}
+}
+
+func TestKeyOrderOfComposedAndRecursiveSchemas(t *testing.T) {
+	parser, err := NewOpenAPIParser([]byte(`openapi: 3.1.0
+info: {title: test, version: "1"}
+paths: {}
+components:
+  schemas:
+    Named:
+      type: object
+      properties:
+        name: {type: string}
+        labels: {type: object, additionalProperties: {type: string}}
+    Node:
+      allOf:
+        - $ref: '#/components/schemas/Named'
+        - type: object
+          properties:
+            children:
+              type: array
+              items: {$ref: '#/components/schemas/Node'}
+            name: {type: string}
+`))
+	if err != nil {
+		t.Fatalf("failed creating new schema: %s", err)
+	}
+	node, _ := parser.doc.Model.Components.Schemas.Get("Node")
+
+	order := keyOrderOf(node, nil)
+
+	// the children of the children are not ordered again
+	expected := &resource.KeyOrder{
+		Keys: []string{"name", "labels", "children"},
+		Fields: map[string]*resource.KeyOrder{
+			"children": {Items: &resource.KeyOrder{Keys: []string{"name", "labels", "children"}}},
+		},
+	}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("Expected the allOf properties in order, stopping at the recursive reference got %s", spew.Sdump(order))
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/conduktor/ctl/pkg/resource"
 	"github.com/davecgh/go-spew/spew"
 )
 
@@ -46,6 +47,13 @@ spec:
     type: Standard
 `,
 						Order: 7,
+						KeyOrder: &resource.KeyOrder{
+							Keys: []string{"kind", "apiVersion", "metadata", "spec"},
+							Fields: map[string]*resource.KeyOrder{
+								"metadata": {Keys: []string{"name"}},
+								"spec":     {Keys: []string{"aclEnabled", "superUsers", "type"}},
+							},
+						},
 					},
 				},
 			},
@@ -82,6 +90,13 @@ metadata:
 spec:
     physicalName: physicalName1
 `,
+						KeyOrder: &resource.KeyOrder{
+							Keys: []string{"kind", "apiVersion", "metadata", "spec"},
+							Fields: map[string]*resource.KeyOrder{
+								"metadata": {Keys: []string{"name", "vCluster"}},
+								"spec":     {Keys: []string{"physicalName", "physicalCluster"}},
+							},
+						},
 					},
 				},
 			},
@@ -124,6 +139,18 @@ spec:
         delete: topic
         deleteCompact: compact_delete_topic
 `,
+						KeyOrder: &resource.KeyOrder{
+							Keys: []string{"kind", "apiVersion", "metadata", "spec"},
+							Fields: map[string]*resource.KeyOrder{
+								"metadata": {Keys: []string{"name", "vCluster"}},
+								"spec": {
+									Keys: []string{"pattern", "physicalTopics", "autoManaged", "offsetCorrectness"},
+									Fields: map[string]*resource.KeyOrder{
+										"physicalTopics": {Keys: []string{"delete", "compact", "deleteCompact"}},
+									},
+								},
+							},
+						},
 					},
 				},
 			},
@@ -158,6 +185,18 @@ spec:
 						},
 						GetAvailable: true,
 						Order:        11,
+						KeyOrder: &resource.KeyOrder{
+							Keys: []string{"kind", "apiVersion", "metadata", "spec"},
+							Fields: map[string]*resource.KeyOrder{
+								"metadata": {Keys: []string{"name"}},
+								"spec": {
+									Keys: []string{"members", "externalGroups"},
+									Fields: map[string]*resource.KeyOrder{
+										"members": {Items: &resource.KeyOrder{Keys: []string{"vCluster", "name"}}},
+									},
+								},
+							},
+						},
 					},
 				},
 			},
@@ -201,6 +240,13 @@ spec:
         - externalName
     type: EXTERNAL
 `,
+						KeyOrder: &resource.KeyOrder{
+							Keys: []string{"kind", "apiVersion", "metadata", "spec"},
+							Fields: map[string]*resource.KeyOrder{
+								"metadata": {Keys: []string{"name", "vCluster"}},
+								"spec":     {Keys: []string{"externalNames", "type"}},
+							},
+						},
 					},
 				},
 			},
@@ -253,6 +299,18 @@ spec:
 `,
 						GetAvailable: false,
 						Order:        12,
+						KeyOrder: &resource.KeyOrder{
+							Keys: []string{"kind", "apiVersion", "metadata", "spec"},
+							Fields: map[string]*resource.KeyOrder{
+								"metadata": {
+									Keys: []string{"name", "scope"},
+									Fields: map[string]*resource.KeyOrder{
+										"scope": {Keys: []string{"vCluster", "group", "username"}},
+									},
+								},
+								"spec": {Keys: []string{"comment", "pluginClass", "priority", "config"}},
+							},
+						},
 					},
 				},
 			},
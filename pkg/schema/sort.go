@@ -5,15 +5,23 @@ import (
 	"fmt"
 	"os"
 	"sort"
+	"sync"
 
 	"github.com/conduktor/ctl/pkg/resource"
 )
 
 const DefaultPriority = 1000 //update  json annotation for Order when changing this value
 
+// defaultKinds are the kinds of the embedded Console and Gateway catalogs, giving the order of the kinds
+// whose catalog, e.g. built from an OpenAPI, does not declare it.
+var defaultKinds = sync.OnceValue(func() KindCatalog {
+	catalog := ConsoleDefaultCatalog().Merge(GatewayDefaultCatalog())
+	return catalog.Kind
+})
+
 func resourcePriority(catalog KindCatalog, resource resource.Resource, debug, fallbackToDefaultCatalog bool) int {
-	kind, ok := catalog[resource.Kind]
-	if !ok {
+	kind, err := lookupKind(catalog, resource.Kind, resource.Version)
+	if err != nil {
 		if debug {
 			fmt.Fprintf(os.Stderr, "Could not find kind: %s in catalog, default to DefaultPriority for resource ordering\n", resource.Kind)
 		}
@@ -29,8 +37,7 @@ func resourcePriority(catalog KindCatalog, resource resource.Resource, debug, fa
 	} else {
 		order := kindVersion.GetOrder()
 		if order == DefaultPriority && fallbackToDefaultCatalog {
-			defaultCatalog := ConsoleDefaultCatalog().Kind //TODO: handle gateway
-			orderFromDefaultCatalog := resourcePriority(defaultCatalog, resource, false, false)
+			orderFromDefaultCatalog := resourcePriority(defaultKinds(), resource, false, false)
 			if orderFromDefaultCatalog != DefaultPriority && debug {
 				fmt.Fprintf(os.Stderr, "Could not find version: %d of kind %s in catalog, but find it in default catalog with priority %d\n", version, resource.Kind, orderFromDefaultCatalog)
 			}
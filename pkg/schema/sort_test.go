@@ -50,3 +50,28 @@ func TestSortResources(t *testing.T) {
 		t.Errorf("Resources are not sorted in the expected order. Got: %v, want: %v", resources, expected)
 	}
 }
+
+func TestSortResourcesFallsBackToTheGatewayDefaultOrder(t *testing.T) {
+	// catalog built from an OpenAPI without orders
+	catalog := KindCatalog{
+		"VirtualCluster": NewKind(2, &GatewayKindVersion{Name: "VirtualCluster", Order: DefaultPriority}),
+		"Interceptor":    NewKind(2, &GatewayKindVersion{Name: "Interceptor", Order: DefaultPriority}),
+		"Widget":         NewKind(1, &ConsoleKindVersion{Name: "Widget", Order: DefaultPriority}),
+	}
+	resources := []resource.Resource{
+		{Kind: "Widget", Version: "v1"},
+		{Kind: "Interceptor", Version: "gateway/v2"},
+		{Kind: "VirtualCluster", Version: "gateway/v2"},
+	}
+
+	SortResourcesForApply(catalog, resources, false)
+
+	expected := []resource.Resource{
+		{Kind: "VirtualCluster", Version: "gateway/v2"},
+		{Kind: "Interceptor", Version: "gateway/v2"},
+		{Kind: "Widget", Version: "v1"},
+	}
+	if !reflect.DeepEqual(resources, expected) {
+		t.Errorf("Resources are not sorted in the expected order. Got: %v, want: %v", resources, expected)
+	}
+}